@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) printHistory(history []*dto.BookingHistoryResponse) error {
+	if c.json {
+		// one entry per line so the output of --follow can be piped
+		for _, entry := range history {
+			if err := c.printJSON(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ENTRY\tBOOKING\tACTION\tACTOR\tCHANGES\tREASON\tAT")
+	for _, entry := range history {
+		actor := "-"
+		if entry.ActorRole != "" {
+			actor = fmt.Sprintf("%s:%d", entry.ActorRole, entry.ActorID)
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\n", entry.ID, entry.BookingID, entry.Action, actor,
+			formatChanges(entry.Changes), entry.Reason, formatTime(entry.CreatedAt))
+	}
+	return w.Flush()
+}
+
+// formatChanges writes the changes as field=old->new, sorted by field
+func formatChanges(changes map[string]dto.FieldChange) string {
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for i, field := range fields {
+		fields[i] = fmt.Sprintf("%s=%s->%s", field, changes[field].From, changes[field].To)
+	}
+	return strings.Join(fields, " ")
+}
+
+func (c *cli) auditCommand() *cobra.Command {
+	lines := 0
+	follow := false
+	interval := time.Second
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Print the booking history of every booking, the latest entries last",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			history := c.bookings.GetAuditLog(0)
+			if lines > 0 && len(history) > lines {
+				history = history[len(history)-lines:]
+			}
+			if err := c.printHistory(history); err != nil {
+				return err
+			}
+			if !follow {
+				return nil
+			}
+
+			last := 0
+			if len(history) > 0 {
+				last = history[len(history)-1].ID
+			}
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-cmd.Context().Done():
+					return nil
+				case <-ticker.C:
+				}
+				entries := c.bookings.GetAuditLog(last)
+				if len(entries) == 0 {
+					continue
+				}
+				if err := c.printHistory(entries); err != nil {
+					return err
+				}
+				last = entries[len(entries)-1].ID
+			}
+		},
+	}
+	cmd.Flags().IntVarP(&lines, "lines", "n", 20, "number of entries to print, 0 prints every entry")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "keep printing new entries")
+	cmd.Flags().DurationVar(&interval, "interval", time.Second, "how often new entries are looked up with --follow")
+	return cmd
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/spf13/cobra"
+)
+
+// bookingFilter narrows the bookings listed, zero values match every booking
+type bookingFilter struct {
+	status    string
+	userID    int
+	serviceID int
+	sort      string
+	highValue bool
+}
+
+func (f bookingFilter) matches(booking *dto.BookingResponse) bool {
+	return (f.status == "" || booking.Status == f.status) &&
+		(f.userID == 0 || booking.UserID == f.userID) &&
+		(f.serviceID == 0 || booking.ServiceID == f.serviceID)
+}
+
+// find lists the bookings of the tenant that match the filter and pass
+// keep, in the order of the sort flag
+func (c *cli) find(filter bookingFilter, keep func(*dto.BookingResponse) bool) ([]*dto.BookingResponse, error) {
+	highValue := ""
+	if filter.highValue {
+		highValue = "true"
+	}
+	bookings, err := c.bookings.GetAllBookings(utils.SystemClaims, filter.sort, highValue)
+	if err != nil {
+		return nil, err
+	}
+	// the repository keeps no order, bookings are listed by id unless
+	// sorted by price or date
+	if filter.sort != "price" && filter.sort != "date" {
+		sort.Slice(bookings, func(i, j int) bool { return bookings[i].ID < bookings[j].ID })
+	}
+	res := []*dto.BookingResponse{}
+	for _, booking := range bookings {
+		if filter.matches(booking) && keep(booking) {
+			res = append(res, booking.In(c.bookings.DisplayLocation(utils.SystemClaims, booking.ServiceID)))
+		}
+	}
+	return res, nil
+}
+
+func (c *cli) printBookings(bookings []*dto.BookingResponse) error {
+	if c.json {
+		return c.printJSON(bookings)
+	}
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSER\tSERVICE\tSTATUS\tPRICE\tSTART\tCREATED")
+	for _, booking := range bookings {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%s\t%s\t%s\t%s\n", booking.ID, booking.UserID, booking.ServiceID, booking.Status,
+			booking.Price, formatTime(booking.StartAt), formatTime(booking.CreatedAt))
+	}
+	return w.Flush()
+}
+
+// formatTime writes t with its offset, "-" for bookings without a slot
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func (c *cli) listCommand() *cobra.Command {
+	filter := bookingFilter{}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List bookings",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bookings, err := c.find(filter, func(*dto.BookingResponse) bool { return true })
+			if err != nil {
+				return err
+			}
+			return c.printBookings(bookings)
+		},
+	}
+	cmd.Flags().StringVar(&filter.status, "status", "", "only bookings with the status")
+	cmd.Flags().IntVar(&filter.userID, "user", 0, "only bookings of the user")
+	cmd.Flags().IntVar(&filter.serviceID, "service", 0, "only bookings of the service")
+	cmd.Flags().StringVar(&filter.sort, "sort", "id", "id, price or date")
+	cmd.Flags().BoolVar(&filter.highValue, "high-value", false, "only high value bookings")
+	return cmd
+}
+
+func (c *cli) searchCommand() *cobra.Command {
+	filter := bookingFilter{}
+	cmd := &cobra.Command{
+		Use:   "search <text>",
+		Short: "Find bookings whose notes, coupon code or resources contain the text",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			text := strings.ToLower(args[0])
+			bookings, err := c.find(filter, func(booking *dto.BookingResponse) bool {
+				fields := []string{booking.Notes, booking.CouponCode}
+				for _, resource := range booking.Resources {
+					fields = append(fields, resource.Name)
+				}
+				for _, field := range fields {
+					if strings.Contains(strings.ToLower(field), text) {
+						return true
+					}
+				}
+				return false
+			})
+			if err != nil {
+				return err
+			}
+			return c.printBookings(bookings)
+		},
+	}
+	cmd.Flags().StringVar(&filter.status, "status", "", "only bookings with the status")
+	cmd.Flags().IntVar(&filter.userID, "user", 0, "only bookings of the user")
+	cmd.Flags().IntVar(&filter.serviceID, "service", 0, "only bookings of the service")
+	return cmd
+}
+
+func parseID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf("invalid booking id %q", arg)
+	}
+	return id, nil
+}
+
+// bookingDetails is everything show prints about a booking
+type bookingDetails struct {
+	Booking  *dto.BookingResponse          `json:"booking"`
+	Payments []*dto.PaymentResponse        `json:"payments"`
+	History  []*dto.BookingHistoryResponse `json:"history"`
+}
+
+func (c *cli) showCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show a booking with its payments and history",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			booking, err := c.bookings.GetBookingByID(utils.SystemClaims, id)
+			if err != nil {
+				return err
+			}
+			payments, err := c.bookings.GetPayments(utils.SystemClaims, id)
+			if err != nil {
+				return err
+			}
+			history, err := c.bookings.GetBookingHistory(utils.SystemClaims, id)
+			if err != nil {
+				return err
+			}
+			details := bookingDetails{
+				Booking:  booking.In(c.bookings.DisplayLocation(utils.SystemClaims, booking.ServiceID)),
+				Payments: payments,
+				History:  history,
+			}
+			if c.json {
+				return c.printJSON(details)
+			}
+
+			if err := c.printBookings([]*dto.BookingResponse{details.Booking}); err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "\nPAYMENT\tKIND\tSTATUS\tAMOUNT\tREFERENCE\tMESSAGE")
+			for _, payment := range payments {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", payment.ID, payment.Kind, payment.Status, payment.Amount, payment.Reference, payment.Message)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			fmt.Fprintln(c.out)
+			return c.printHistory(history)
+		},
+	}
+}
+
+func (c *cli) setStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-status <id> <status>",
+		Short: "Move a booking to another status, only the moves of the state machine are allowed",
+		Long: "Move a booking to another status as an admin. The state machine is " +
+			"pending -> deposit_paid | confirmed | rejected | canceled, deposit_paid -> confirmed | canceled " +
+			"and confirmed -> canceled; cancellations are refunded by the cancellation policy.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			if err := c.bookings.UpdateBooking(utils.SystemClaims, id, args[1]); err != nil {
+				return err
+			}
+			booking, err := c.bookings.GetBookingByID(utils.SystemClaims, id)
+			if err != nil {
+				return err
+			}
+			return c.printBookings([]*dto.BookingResponse{booking.In(c.bookings.DisplayLocation(utils.SystemClaims, booking.ServiceID))})
+		},
+	}
+}
+
+func (c *cli) expireCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "expire",
+		Short: "Cancel the bookings pending for longer than the pending TTL, as the background task does every minute",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			expired := c.bookings.ExpirePendingBookings()
+			if c.json {
+				return c.printJSON(expired)
+			}
+			fmt.Fprintf(c.out, "%d bookings expired %v\n", len(expired), expired)
+			return nil
+		},
+	}
+}
+
+// creditCheckResult is the outcome of the credit check of one booking
+type creditCheckResult struct {
+	BookingID int                  `json:"booking_id"`
+	Payment   *dto.PaymentResponse `json:"payment,omitempty"`
+	Error     string               `json:"error,omitempty"`
+}
+
+func (c *cli) creditCheckCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "credit-check [id...]",
+		Short: "Run the payment and credit check again for pending bookings",
+		Long: "Run the payment and credit check again for the given pending bookings, or for " +
+			"every pending booking. Bookings with a payment in progress keep it, so no booking is charged twice.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids := []int{}
+			for _, arg := range args {
+				id, err := parseID(arg)
+				if err != nil {
+					return err
+				}
+				ids = append(ids, id)
+			}
+			if len(ids) == 0 {
+				pending, err := c.find(bookingFilter{status: models.StatusPending}, func(*dto.BookingResponse) bool { return true })
+				if err != nil {
+					return err
+				}
+				for _, booking := range pending {
+					ids = append(ids, booking.ID)
+				}
+			}
+
+			results := []creditCheckResult{}
+			for _, id := range ids {
+				result := creditCheckResult{BookingID: id}
+				payment, err := c.bookings.StartPayment(id)
+				if err != nil {
+					result.Error = err.Error()
+				}
+				result.Payment = payment
+				results = append(results, result)
+			}
+			if c.json {
+				return c.printJSON(results)
+			}
+
+			w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "BOOKING\tPAYMENT\tSTATUS\tAMOUNT\tMESSAGE")
+			for _, result := range results {
+				if result.Payment == nil {
+					fmt.Fprintf(w, "%d\t-\tfailed\t-\t%s\n", result.BookingID, result.Error)
+					continue
+				}
+				fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\n", result.BookingID, result.Payment.ID, result.Payment.Status,
+					result.Payment.Amount, result.Payment.Message)
+			}
+			return w.Flush()
+		},
+	}
+}
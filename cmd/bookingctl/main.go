@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/spf13/cobra"
+)
+
+// bookingctl runs the fixes ops used to make by restarting the server. It
+// loads the same config as the server and works on the repositories of one
+// tenant through the usecase layer, so every change goes through the same
+// validation, state machine and booking history. The repositories are only
+// in memory for now, so it refuses to run unless asked to work on a store of
+// its own.
+func main() {
+	// interrupts stop audit --follow
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := newRootCommand(os.Stdout).ExecuteContext(ctx); err != nil {
+		stop()
+		os.Exit(1)
+	}
+}
+
+// cli is the state shared by the commands, bookings is set once the config
+// is loaded
+type cli struct {
+	out      io.Writer
+	tenant   string
+	json     bool
+	inMemory bool
+	bookings usecase.BookingUsecase
+}
+
+func newRootCommand(out io.Writer) *cobra.Command {
+	c := &cli{out: out}
+	root := &cobra.Command{
+		Use:          "bookingctl",
+		Short:        "Inspect and fix bookings without restarting the server",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.load()
+		},
+	}
+	root.PersistentFlags().StringVar(&c.tenant, "tenant", "", "tenant to work on, the default tenant when empty")
+	root.PersistentFlags().BoolVar(&c.json, "json", false, "print JSON instead of tables")
+	root.PersistentFlags().BoolVar(&c.inMemory, "in-memory", false, "work on a seeded in-memory store of its own instead of the bookings of the server")
+
+	root.AddCommand(
+		c.listCommand(),
+		c.searchCommand(),
+		c.showCommand(),
+		c.setStatusCommand(),
+		c.expireCommand(),
+		c.creditCheckCommand(),
+		c.auditCommand(),
+	)
+	return root
+}
+
+// errInMemory is returned while NewTenant only has in-memory repositories,
+// the bookings of a running server live in its own memory
+var errInMemory = errors.New("only in-memory repositories are configured, bookingctl cannot reach the bookings of the server; pass --in-memory to work on a seeded store of its own")
+
+// load builds the usecases of the tenant from the config
+func (c *cli) load() error {
+	if !c.inMemory {
+		return errInMemory
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if c.tenant == "" {
+		c.tenant = cfg.DefaultTenant
+	}
+	if !cfg.HasTenant(c.tenant) {
+		return fmt.Errorf("unknown tenant %s, tenants are %v", c.tenant, cfg.TenantIDs())
+	}
+	c.bookings = usecase.NewTenant(cfg.ForTenant(c.tenant), usecase.NewPaymentProvider(cfg)).Bookings
+	return nil
+}
+
+// printJSON writes v indented, used by every command with --json
+func (c *cli) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(c.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -36,25 +36,86 @@ func main() {
 	//Allow all origins
 	app.Use(cors.New(cors.Config{
         AllowOrigins: "*",                // Allow all origins
-        AllowHeaders: "Origin, Content-Type, Accept",
-        AllowMethods: "GET,POST,DELETE",
+        AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-Api-Key, Accept-Timezone",
+        ExposeHeaders: "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After",
+        AllowMethods: "GET,POST,PUT,PATCH,DELETE",
     }))
 
+	var wg sync.WaitGroup
+
+	// RS256/ES256 tokens are verified with the keys of the key files, other
+	// services can fetch them from the JWKS endpoint, HS256 keeps using the
+	// shared secret
+	var keySet *utils.KeySet
+	if config.JWTAlgorithm == "HS256" && config.JWTSecret == "" {
+		log.Fatal("JWT_SECRET is required with the HS256 algorithm")
+	}
+	if config.JWTAlgorithm != "HS256" {
+		keySet, err = utils.LoadKeySet(config.JWTAlgorithm, config.JWTKeyFiles)
+		if err != nil {
+			log.Fatalf("Failed to load JWT keys: %v", err)
+		}
+		if config.JWTKeyReload > 0 {
+			keySet.StartReload(config.JWTKeyReload, &wg)
+		}
+		router.SetupJWKSRoutes(app, handler.NewJWKSHandler(keySet))
+	}
+
+	apiKeyRepo := repository.NewMockApiKeyRepository()
+	apiKeyUsecase := usecase.NewApiKeyUsecase(apiKeyRepo)
+	if config.APIKey != "" {
+		if err := apiKeyUsecase.ImportApiKey(config.DefaultTenant, "config", config.APIKey); err != nil {
+			log.Fatalf("Failed to import API key: %v", err)
+		}
+	}
+	apiKeyHandler := handler.NewApiKeyHandler(apiKeyUsecase)
+
 	loggerMiddleware := middleware.NewLoggerMiddleware()
-	// authMiddleware := middleware.NewAuthMiddleware()
+	rateLimitMiddleware := middleware.NewRateLimitMiddleware(middleware.NewInMemoryRateLimitStore())
+	authMiddleware := middleware.NewAuthMiddleware(config, keySet, apiKeyUsecase)
 
-	bookingRepo := repository.NewMockBookingRepository()
-	cache := utils.NewInMemoryCache()
+	paymentProvider := usecase.NewPaymentProvider(config)
 
-	bookingUsecase := usecase.NewBookingUsecase(bookingRepo, cache)
-	bookingHandler := handler.NewBookingHandler(bookingUsecase)
+	// every tenant runs on its own repositories with its own settings, the
+	// handlers pick the tenant the middlewares resolved for the request
+	tenants := map[string]*usecase.Tenant{}
+	for _, id := range config.TenantIDs() {
+		tenants[id] = usecase.NewTenant(config.ForTenant(id), paymentProvider)
+	}
+	defaultTenant := tenants[config.DefaultTenant]
+
+	bookingHandler := handler.NewBookingHandler(defaultTenant.Bookings)
+	pricingHandler := handler.NewPricingHandler(defaultTenant.Pricing)
+	couponHandler := handler.NewCouponHandler(defaultTenant.Coupons)
+	businessHoursHandler := handler.NewBusinessHoursHandler(defaultTenant.BusinessHours)
+	resourceHandler := handler.NewResourceHandler(defaultTenant.Resources)
+	bookingHandler.Tenants = map[string]usecase.BookingUsecase{}
+	pricingHandler.Tenants = map[string]usecase.PricingUsecase{}
+	couponHandler.Tenants = map[string]usecase.CouponUsecase{}
+	businessHoursHandler.Tenants = map[string]usecase.BusinessHoursUsecase{}
+	resourceHandler.Tenants = map[string]usecase.ResourceUsecase{}
+	for id, t := range tenants {
+		bookingHandler.Tenants[id] = t.Bookings
+		pricingHandler.Tenants[id] = t.Pricing
+		couponHandler.Tenants[id] = t.Coupons
+		businessHoursHandler.Tenants[id] = t.BusinessHours
+		resourceHandler.Tenants[id] = t.Resources
+	}
 
-	router.SetupRoutes(app, bookingHandler, loggerMiddleware)
+	router.SetupRoutes(app, config, bookingHandler, loggerMiddleware, authMiddleware, rateLimitMiddleware)
+	router.SetupApiKeyRoutes(app, config, bookingHandler, loggerMiddleware, authMiddleware, rateLimitMiddleware)
+	router.SetupAdminRoutes(app, apiKeyHandler, couponHandler, businessHoursHandler, resourceHandler, loggerMiddleware, authMiddleware)
+	router.SetupPricingRoutes(app, config, pricingHandler, loggerMiddleware, authMiddleware, rateLimitMiddleware)
+	router.SetupBusinessHoursRoutes(app, config, businessHoursHandler, loggerMiddleware, authMiddleware, rateLimitMiddleware)
+	router.SetupResourceRoutes(app, config, resourceHandler, loggerMiddleware, authMiddleware, rateLimitMiddleware)
+	router.SetupPaymentRoutes(app, config, bookingHandler, loggerMiddleware)
+	router.SetupCalendarRoutes(app, config, bookingHandler, loggerMiddleware, rateLimitMiddleware)
 
 	app.Get("/swagger/*", swagger.HandlerDefault)
 
-	var wg sync.WaitGroup
-	bookingUsecase.BackgroundTaskBooking(&wg)
+	for _, t := range tenants {
+		t.Bookings.BackgroundTaskBooking(&wg)
+	}
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -78,3 +139,4 @@ func main() {
 	}
 
 }
+
@@ -1,16 +1,319 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	models "github.com/Eursukkul/fiber-booking-system/model"
 	"github.com/joho/godotenv"
 )
 
+// RateLimit allows Requests per Period, Requests 0 disables the limit
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// BookingPolicy limits what a single user can book
+type BookingPolicy struct {
+	// MaxPendingPerUser is the number of pending bookings a user can hold, 0 disables it
+	MaxPendingPerUser int
+	// MaxDailySpend is the total price a user can book per day in its
+	// currency, 0 disables it
+	MaxDailySpend models.Money
+	// Velocity limits how many bookings a user can create in a period
+	Velocity        RateLimit
+	BlockedUsers    []int
+	BlockedServices []int
+}
+
+// ReschedulePolicy are the rules for moving a booking to another slot
+type ReschedulePolicy struct {
+	// Fee is charged when rescheduling later than FreeBefore the start
+	Fee        models.Money
+	FreeBefore time.Duration
+	// MinNotice is how long before the start a booking can still be moved
+	MinNotice time.Duration
+	// MaxReschedules per booking, 0 means unlimited
+	MaxReschedules int
+}
+
+// CancellationTier charges FeePercent of the paid amount when a booking is
+// canceled at least Before its start
+type CancellationTier struct {
+	Before     time.Duration
+	FeePercent float64
+}
+
+// CancellationPolicy are the rules for refunding a canceled booking
+type CancellationPolicy struct {
+	// FreeBefore is how long before the start a booking can be canceled for free
+	FreeBefore time.Duration
+	// Tiers apply when canceling later than FreeBefore, closer than every
+	// tier the whole amount is kept
+	Tiers []CancellationTier
+	// NonRefundable bookings are never refunded
+	NonRefundable bool
+}
+
+// FeePercent is the part of the paid amount kept when a booking is canceled
+// untilStart before it starts
+func (p CancellationPolicy) FeePercent(untilStart time.Duration) float64 {
+	if p.NonRefundable {
+		return 100
+	}
+	if untilStart >= p.FreeBefore {
+		return 0
+	}
+	fee, matched := 100.0, time.Duration(-1)
+	for _, tier := range p.Tiers {
+		if untilStart >= tier.Before && tier.Before > matched {
+			fee, matched = tier.FeePercent, tier.Before
+		}
+	}
+	return fee
+}
+
+// DurationMultiplier scales the price of bookings lasting at least MinDuration
+type DurationMultiplier struct {
+	MinDuration time.Duration
+	Multiplier  float64
+}
+
+// PricingRules adjust the base price of a service, percentages are of the
+// price so far and applied in the order of the fields
+type PricingRules struct {
+	DurationMultipliers []DurationMultiplier
+	// PeakStart and PeakEnd are the hours [start, end) of the peak surcharge,
+	// equal hours disable it
+	PeakStart     int
+	PeakEnd       int
+	PeakSurcharge float64
+	// WeekendSurcharge applies to slots starting on Saturday or Sunday
+	WeekendSurcharge float64
+	// TierDiscounts are percentages off by user tier, UserTiers maps user
+	// ids to their tier
+	TierDiscounts map[string]float64
+	UserTiers     map[int]string
+}
+
+// InvoiceSettings are printed on invoices, Location is the time zone of the
+// issue dates
+type InvoiceSettings struct {
+	IssuerName    string
+	IssuerTaxID   string
+	IssuerAddress string
+	Location      *time.Location
+}
+
+// PaymentSettings choose the payment provider, Provider is fake or http
+type PaymentSettings struct {
+	Provider string
+	URL      string
+	APIKey   string
+	// CallbackSecret signs the callbacks of the provider
+	CallbackSecret string
+	Timeout        time.Duration
+}
+
+// DepositPolicy splits the payment of high value bookings into a deposit
+// taken when the booking is made and a balance due before the start
+type DepositPolicy struct {
+	// Percent of the price taken as deposit, 0 takes the whole price at once
+	Percent         float64
+	ServicePercents map[int]float64
+	// BalanceDueBefore is how long before the start the balance is due,
+	// bookings without a slot are due BalanceDueWithin after they are made
+	BalanceDueBefore time.Duration
+	BalanceDueWithin time.Duration
+	// ReminderBefore is how long before the due date the customer is reminded
+	ReminderBefore time.Duration
+}
+
+// TimezoneSettings are the zones times are shown in. Services are run in
+// their zone, so its peak hours and weekends apply, and users see times in
+// their own zone, then in the zone of the service. Zones left unset are UTC.
+type TimezoneSettings struct {
+	Default  *time.Location
+	Services map[int]*time.Location
+	Users    map[int]*time.Location
+}
+
+// ServiceLocation returns the time zone of a service
+func (t TimezoneSettings) ServiceLocation(serviceID int) *time.Location {
+	if location, exists := t.Services[serviceID]; exists {
+		return location
+	}
+	if t.Default != nil {
+		return t.Default
+	}
+	return time.UTC
+}
+
+// DisplayLocation returns the zone the user sees the times of a booking of
+// the service in
+func (t TimezoneSettings) DisplayLocation(userID, serviceID int) *time.Location {
+	if location, exists := t.Users[userID]; exists {
+		return location
+	}
+	return t.ServiceLocation(serviceID)
+}
+
+// CalendarSettings are used for the iCalendar exports, Domain makes the
+// event UIDs unique and Location is the time zone the events are written in
+type CalendarSettings struct {
+	Name     string
+	Domain   string
+	Location *time.Location
+}
+
+// TenantSettings are the settings of one business unit. Requests for one of
+// its Hosts are served by the tenant, settings left unset use the global ones.
+type TenantSettings struct {
+	Hosts               []string
+	PendingTTL          time.Duration
+	HighValueThresholds map[string]models.Money
+	Pricing             *PricingRules
+}
+
 type Config struct {
 	Port      string
 	JWTSecret string
-	APIKey    string
+	// APIKey is imported as a key with every scope when it is set
+	APIKey string
+
+	// JWTAlgorithm is HS256 (shared JWTSecret), RS256 or ES256
+	JWTAlgorithm string
+	// JWTKeyFiles are PEM private keys or glob patterns of them, the issuer
+	// signs with the same keys
+	JWTKeyFiles []string
+	// JWTKeyReload reads the key files again on this interval, 0 disables it
+	JWTKeyReload time.Duration
+
+	// RateLimitRead and RateLimitWrite are applied per API key, JWT subject or IP of a tenant
+	RateLimitRead  RateLimit
+	RateLimitWrite RateLimit
+
+	// Currency is the ISO 4217 currency of the services and of amounts in
+	// the environment
+	Currency string
+	// HighValueThresholds are the prices per currency above which a booking
+	// is high value, currencies without one have no high value bookings
+	HighValueThresholds map[string]models.Money
+
+	BookingPolicy    BookingPolicy
+	ReschedulePolicy ReschedulePolicy
+
+	Pricing PricingRules
+
+	// CancellationPolicy applies to every service without its own policy
+	CancellationPolicy          CancellationPolicy
+	ServiceCancellationPolicies map[int]CancellationPolicy
+
+	// VATRate is the VAT percentage included in the price of every service
+	// without its own rate in ServiceVATRates
+	VATRate         float64
+	ServiceVATRates map[int]float64
+	Invoice         InvoiceSettings
+
+	Payment PaymentSettings
+	Deposit DepositPolicy
+
+	// WaitlistAcceptWindow is how long a waitlisted user has to accept a
+	// freed slot before it is offered to the next one
+	WaitlistAcceptWindow time.Duration
+
+	Calendar CalendarSettings
+
+	Timezones TimezoneSettings
+
+	// PendingTTL is how long a booking can stay pending before it expires
+	PendingTTL time.Duration
+
+	// DefaultTenant serves the requests no other tenant is resolved for,
+	// Tenants are the other business units of the deployment
+	DefaultTenant string
+	Tenants       map[string]TenantSettings
+}
+
+// HasTenant reports whether id is the default tenant or a configured one
+func (c *Config) HasTenant(id string) bool {
+	_, exists := c.Tenants[id]
+	return exists || id == c.DefaultTenant
+}
+
+// TenantIDs returns the default tenant followed by the other tenants
+func (c *Config) TenantIDs() []string {
+	ids := []string{}
+	for id := range c.Tenants {
+		if id != c.DefaultTenant {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return append([]string{c.DefaultTenant}, ids...)
+}
+
+// TenantForHost returns the tenant serving the host name
+func (c *Config) TenantForHost(host string) (string, bool) {
+	host = strings.ToLower(host)
+	for id, settings := range c.Tenants {
+		for _, h := range settings.Hosts {
+			if strings.ToLower(h) == host {
+				return id, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ForTenant returns the config the tenant is run with, the global config
+// with the settings of the tenant applied
+func (c *Config) ForTenant(id string) *Config {
+	settings, exists := c.Tenants[id]
+	if !exists {
+		return c
+	}
+	tenant := *c
+	if settings.PendingTTL > 0 {
+		tenant.PendingTTL = settings.PendingTTL
+	}
+	if settings.HighValueThresholds != nil {
+		tenant.HighValueThresholds = settings.HighValueThresholds
+	}
+	if settings.Pricing != nil {
+		tenant.Pricing = *settings.Pricing
+	}
+	return &tenant
+}
+
+// CancellationPolicyFor returns the cancellation policy of a service
+func (c *Config) CancellationPolicyFor(serviceID int) CancellationPolicy {
+	if policy, exists := c.ServiceCancellationPolicies[serviceID]; exists {
+		return policy
+	}
+	return c.CancellationPolicy
+}
+
+// VATRateFor returns the VAT percentage of a service
+func (c *Config) VATRateFor(serviceID int) float64 {
+	if rate, exists := c.ServiceVATRates[serviceID]; exists {
+		return rate
+	}
+	return c.VATRate
+}
+
+// DepositPercentFor returns the deposit percentage of a service
+func (c *Config) DepositPercentFor(serviceID int) float64 {
+	if percent, exists := c.Deposit.ServicePercents[serviceID]; exists {
+		return percent
+	}
+	return c.Deposit.Percent
 }
 
 func LoadConfig() (*Config, error) {
@@ -20,11 +323,317 @@ func LoadConfig() (*Config, error) {
 		log.Println("No .env file found, using default configurations")
 	}
 
-	return &Config{
+	currency := strings.ToUpper(getEnv("CURRENCY", "THB"))
+	if !models.IsCurrency(currency) {
+		log.Printf("Unknown currency %s, using THB", currency)
+		currency = "THB"
+	}
+
+	cfg := &Config{
 		Port:      getEnv("PORT", ":3000"),
-		JWTSecret: getEnv("JWT_SECRET", "your_default_jwt_secret"),
-		APIKey:    getEnv("API_KEY", "your_default_api_key"),
-	}, nil
+		JWTSecret: getEnv("JWT_SECRET", ""),
+		APIKey:    getEnv("API_KEY", ""),
+
+		JWTAlgorithm: getEnv("JWT_ALGORITHM", "HS256"),
+		JWTKeyFiles:  getEnvList("JWT_KEY_FILES"),
+		JWTKeyReload: getEnvDuration("JWT_KEY_RELOAD", 0),
+
+		RateLimitRead:  getEnvRateLimit("RATE_LIMIT_READ", RateLimit{Requests: 120, Period: time.Minute}),
+		RateLimitWrite: getEnvRateLimit("RATE_LIMIT_WRITE", RateLimit{Requests: 20, Period: time.Minute}),
+
+		Currency:            currency,
+		HighValueThresholds: getEnvMoneyList("HIGH_VALUE_THRESHOLDS", map[string]models.Money{"THB": models.NewMoney(5000000, "THB")}),
+
+		BookingPolicy: BookingPolicy{
+			MaxPendingPerUser: getEnvInt("BOOKING_MAX_PENDING_PER_USER", 3),
+			MaxDailySpend:     getEnvMoney("BOOKING_MAX_DAILY_SPEND", currency, "200000"),
+			Velocity:          getEnvRateLimit("BOOKING_VELOCITY", RateLimit{Requests: 5, Period: 10 * time.Minute}),
+			BlockedUsers:      getEnvIntList("BOOKING_BLOCKED_USERS"),
+			BlockedServices:   getEnvIntList("BOOKING_BLOCKED_SERVICES"),
+		},
+		ReschedulePolicy: ReschedulePolicy{
+			Fee:            getEnvMoney("RESCHEDULE_FEE", currency, "0"),
+			FreeBefore:     getEnvDuration("RESCHEDULE_FREE_BEFORE", 24*time.Hour),
+			MinNotice:      getEnvDuration("RESCHEDULE_MIN_NOTICE", 2*time.Hour),
+			MaxReschedules: getEnvInt("RESCHEDULE_MAX", 3),
+		},
+
+		Pricing: getEnvPricing("", PricingRules{
+			DurationMultipliers: []DurationMultiplier{},
+			PeakStart:           17,
+			PeakEnd:             20,
+			TierDiscounts:       map[string]float64{},
+			UserTiers:           map[int]string{},
+		}),
+
+		CancellationPolicy: getEnvCancellationPolicy("CANCEL_POLICY", CancellationPolicy{
+			FreeBefore: 24 * time.Hour,
+			Tiers: []CancellationTier{
+				{Before: 12 * time.Hour, FeePercent: 25},
+				{Before: 2 * time.Hour, FeePercent: 50},
+			},
+		}),
+		ServiceCancellationPolicies: getEnvCancellationPolicies("CANCEL_POLICY_SERVICE_"),
+
+		VATRate:         getEnvFloat("VAT_RATE", 7),
+		ServiceVATRates: getEnvPercents("VAT_RATE_SERVICE_"),
+		Invoice: InvoiceSettings{
+			IssuerName:    getEnv("INVOICE_ISSUER_NAME", "SPD Fiber Booking"),
+			IssuerTaxID:   getEnv("INVOICE_ISSUER_TAX_ID", ""),
+			IssuerAddress: getEnv("INVOICE_ISSUER_ADDRESS", ""),
+			Location:      getEnvLocation("INVOICE_TIMEZONE", "Asia/Bangkok"),
+		},
+
+		Payment: PaymentSettings{
+			Provider:       getEnv("PAYMENT_PROVIDER", "fake"),
+			URL:            getEnv("PAYMENT_URL", ""),
+			APIKey:         getEnv("PAYMENT_API_KEY", ""),
+			CallbackSecret: getEnv("PAYMENT_CALLBACK_SECRET", ""),
+			Timeout:        getEnvDuration("PAYMENT_TIMEOUT", 10*time.Second),
+		},
+		Deposit: DepositPolicy{
+			Percent:          getEnvFloat("DEPOSIT_PERCENT", 30),
+			ServicePercents:  getEnvPercents("DEPOSIT_PERCENT_SERVICE_"),
+			BalanceDueBefore: getEnvDuration("DEPOSIT_BALANCE_DUE_BEFORE", 72*time.Hour),
+			BalanceDueWithin: getEnvDuration("DEPOSIT_BALANCE_DUE_WITHIN", 7*24*time.Hour),
+			ReminderBefore:   getEnvDuration("DEPOSIT_REMINDER_BEFORE", 24*time.Hour),
+		},
+
+		WaitlistAcceptWindow: getEnvDuration("WAITLIST_ACCEPT_WINDOW", 30*time.Minute),
+
+		Calendar: CalendarSettings{
+			Name:     getEnv("CALENDAR_NAME", "SPD Fiber Booking"),
+			Domain:   getEnv("CALENDAR_DOMAIN", "fiber-booking-system"),
+			Location: getEnvLocation("CALENDAR_TIMEZONE", "Asia/Bangkok"),
+		},
+
+		Timezones: TimezoneSettings{
+			Default:  getEnvLocation("TIMEZONE", "Asia/Bangkok"),
+			Services: getEnvLocations("TIMEZONE_SERVICE_"),
+			Users:    getEnvLocations("TIMEZONE_USER_"),
+		},
+
+		PendingTTL: getEnvDuration("BOOKING_PENDING_TTL", 5*time.Minute),
+
+		DefaultTenant: strings.ToLower(getEnv("DEFAULT_TENANT", "default")),
+	}
+	cfg.Tenants = getEnvTenants(cfg.DefaultTenant, cfg.Pricing)
+	return cfg, nil
+}
+
+// getEnvPricing reads the pricing rules from the PRICING_ variables after
+// prefix, rules without a variable keep their value from defaultValue
+func getEnvPricing(prefix string, defaultValue PricingRules) PricingRules {
+	rules := defaultValue
+	if _, exists := os.LookupEnv(prefix + "PRICING_DURATION_MULTIPLIERS"); exists {
+		rules.DurationMultipliers = getEnvDurationMultipliers(prefix + "PRICING_DURATION_MULTIPLIERS")
+	}
+	rules.PeakStart = getEnvInt(prefix+"PRICING_PEAK_START", defaultValue.PeakStart)
+	rules.PeakEnd = getEnvInt(prefix+"PRICING_PEAK_END", defaultValue.PeakEnd)
+	rules.PeakSurcharge = getEnvFloat(prefix+"PRICING_PEAK_SURCHARGE", defaultValue.PeakSurcharge)
+	rules.WeekendSurcharge = getEnvFloat(prefix+"PRICING_WEEKEND_SURCHARGE", defaultValue.WeekendSurcharge)
+	if _, exists := os.LookupEnv(prefix + "PRICING_TIER_DISCOUNTS"); exists {
+		rules.TierDiscounts = getEnvTierDiscounts(prefix + "PRICING_TIER_DISCOUNTS")
+	}
+	if _, exists := os.LookupEnv(prefix + "PRICING_USER_TIERS"); exists {
+		rules.UserTiers = getEnvUserTiers(prefix + "PRICING_USER_TIERS")
+	}
+	return rules
+}
+
+// getEnvTenants reads the tenants listed in TENANTS from variables named
+// TENANT_ followed by the tenant id, e.g. TENANT_ACME_HOSTS=acme.example.com.
+// Tenants without pricing variables use the global pricing rules.
+func getEnvTenants(defaultTenant string, pricing PricingRules) map[string]TenantSettings {
+	tenants := map[string]TenantSettings{}
+	for _, id := range getEnvList("TENANTS") {
+		id = strings.ToLower(id)
+		if id == defaultTenant {
+			log.Printf("Tenant %s in TENANTS is the default tenant, ignoring it", id)
+			continue
+		}
+		prefix := "TENANT_" + strings.ToUpper(strings.ReplaceAll(id, "-", "_")) + "_"
+		settings := TenantSettings{
+			Hosts:               getEnvList(prefix + "HOSTS"),
+			PendingTTL:          getEnvDuration(prefix+"PENDING_TTL", 0),
+			HighValueThresholds: getEnvMoneyList(prefix+"HIGH_VALUE_THRESHOLDS", nil),
+		}
+		for _, env := range os.Environ() {
+			if strings.HasPrefix(env, prefix+"PRICING_") {
+				rules := getEnvPricing(prefix, pricing)
+				settings.Pricing = &rules
+				break
+			}
+		}
+		tenants[id] = settings
+	}
+	return tenants
+}
+
+// getEnvLocation loads an IANA time zone such as Asia/Bangkok, invalid zones
+// fall back to UTC
+func getEnvLocation(key, defaultValue string) *time.Location {
+	location, err := time.LoadLocation(getEnv(key, defaultValue))
+	if err != nil {
+		log.Printf("Invalid %s, using UTC: %v", key, err)
+		return time.UTC
+	}
+	return location
+}
+
+// getEnvDurationMultipliers parses multipliers written as "2h:0.95,4h:0.9"
+func getEnvDurationMultipliers(key string) []DurationMultiplier {
+	multipliers := []DurationMultiplier{}
+	for _, value := range getEnvList(key) {
+		before, after, found := strings.Cut(value, ":")
+		duration, err := time.ParseDuration(before)
+		multiplier, merr := strconv.ParseFloat(after, 64)
+		if !found || err != nil || merr != nil || multiplier <= 0 {
+			log.Printf("Invalid duration multiplier %q in %s, ignoring it", value, key)
+			continue
+		}
+		multipliers = append(multipliers, DurationMultiplier{MinDuration: duration, Multiplier: multiplier})
+	}
+	return multipliers
+}
+
+// getEnvTierDiscounts parses discounts written as "silver:5,gold:10"
+func getEnvTierDiscounts(key string) map[string]float64 {
+	discounts := map[string]float64{}
+	for _, value := range getEnvList(key) {
+		tier, percent, found := strings.Cut(value, ":")
+		discount, err := strconv.ParseFloat(percent, 64)
+		if !found || err != nil || discount < 0 || discount > 100 {
+			log.Printf("Invalid tier discount %q in %s, ignoring it", value, key)
+			continue
+		}
+		discounts[tier] = discount
+	}
+	return discounts
+}
+
+// getEnvUserTiers parses user tiers written as "42:gold,43:silver"
+func getEnvUserTiers(key string) map[int]string {
+	tiers := map[int]string{}
+	for _, value := range getEnvList(key) {
+		user, tier, found := strings.Cut(value, ":")
+		id, err := strconv.Atoi(user)
+		if !found || err != nil {
+			log.Printf("Invalid user tier %q in %s, ignoring it", value, key)
+			continue
+		}
+		tiers[id] = tier
+	}
+	return tiers
+}
+
+// getEnvCancellationPolicy parses policies written as
+// "free_before=24h tiers=12h:25,2h:50" or "non_refundable"
+func getEnvCancellationPolicy(key string, defaultValue CancellationPolicy) CancellationPolicy {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	policy, err := parseCancellationPolicy(value)
+	if err != nil {
+		log.Printf("Invalid cancellation policy for %s, using the default: %v", key, err)
+		return defaultValue
+	}
+	return policy
+}
+
+// getEnvCancellationPolicies reads one policy per service from variables
+// named prefix followed by the service id, e.g. CANCEL_POLICY_SERVICE_3
+func getEnvCancellationPolicies(prefix string) map[int]CancellationPolicy {
+	policies := map[int]CancellationPolicy{}
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(key, prefix))
+		if err != nil {
+			log.Printf("Invalid service id in %s, ignoring it", key)
+			continue
+		}
+		policy, err := parseCancellationPolicy(value)
+		if err != nil {
+			log.Printf("Invalid cancellation policy for %s, ignoring it: %v", key, err)
+			continue
+		}
+		policies[id] = policy
+	}
+	return policies
+}
+
+// getEnvPercents reads one percentage per service from variables named
+// prefix followed by the service id, e.g. VAT_RATE_SERVICE_3=0
+func getEnvPercents(prefix string) map[int]float64 {
+	rates := map[int]float64{}
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(key, prefix))
+		rate, rerr := strconv.ParseFloat(value, 64)
+		if err != nil || rerr != nil || rate < 0 || rate > 100 {
+			log.Printf("Invalid percentage in %s, ignoring it", key)
+			continue
+		}
+		rates[id] = rate
+	}
+	return rates
+}
+
+// getEnvLocations reads one time zone per id from variables named prefix
+// followed by the id, e.g. TIMEZONE_USER_42=Asia/Tokyo
+func getEnvLocations(prefix string) map[int]*time.Location {
+	locations := map[int]*time.Location{}
+	for _, env := range os.Environ() {
+		key, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(key, prefix))
+		location, lerr := time.LoadLocation(value)
+		if err != nil || lerr != nil {
+			log.Printf("Invalid time zone in %s, ignoring it", key)
+			continue
+		}
+		locations[id] = location
+	}
+	return locations
+}
+
+func parseCancellationPolicy(value string) (CancellationPolicy, error) {
+	policy := CancellationPolicy{}
+	for _, field := range strings.Fields(value) {
+		name, setting, _ := strings.Cut(field, "=")
+		switch name {
+		case "non_refundable":
+			policy.NonRefundable = true
+		case "free_before":
+			duration, err := time.ParseDuration(setting)
+			if err != nil {
+				return policy, err
+			}
+			policy.FreeBefore = duration
+		case "tiers":
+			for _, tier := range strings.Split(setting, ",") {
+				before, percent, found := strings.Cut(tier, ":")
+				duration, err := time.ParseDuration(before)
+				fee, ferr := strconv.ParseFloat(percent, 64)
+				if !found || err != nil || ferr != nil || fee < 0 || fee > 100 {
+					return policy, fmt.Errorf("invalid tier %q", tier)
+				}
+				policy.Tiers = append(policy.Tiers, CancellationTier{Before: duration, FeePercent: fee})
+			}
+		default:
+			return policy, fmt.Errorf("unknown setting %q", name)
+		}
+	}
+	return policy, nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -32,4 +641,115 @@ func getEnv(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func getEnvList(key string) []string {
+	values := []string{}
+	for _, value := range strings.Split(getEnv(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s, using %s", key, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
+// getEnvRateLimit parses limits written as "20/1m"
+func getEnvRateLimit(key string, defaultValue RateLimit) RateLimit {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		log.Printf("Invalid rate limit for %s, using %d/%s", key, defaultValue.Requests, defaultValue.Period)
+		return defaultValue
+	}
+	requests, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	period, perr := time.ParseDuration(strings.TrimSpace(parts[1]))
+	if err != nil || perr != nil || requests < 0 || period <= 0 {
+		log.Printf("Invalid rate limit for %s, using %d/%s", key, defaultValue.Requests, defaultValue.Period)
+		return defaultValue
+	}
+	return RateLimit{Requests: requests, Period: period}
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid number for %s, using %d", key, defaultValue)
+		return defaultValue
+	}
+	return number
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Invalid number for %s, using %v", key, defaultValue)
+		return defaultValue
+	}
+	return number
+}
+
+// getEnvMoney parses a decimal amount such as "150.50" in the currency
+func getEnvMoney(key, currency, defaultValue string) models.Money {
+	value := getEnv(key, defaultValue)
+	money, err := models.ParseMoney(value, currency)
+	if err != nil {
+		log.Printf("Invalid amount for %s, using %s", key, defaultValue)
+		money, _ = models.ParseMoney(defaultValue, currency)
+	}
+	return money
+}
+
+// getEnvMoneyList parses amounts per currency written as "THB:50000,USD:1500"
+func getEnvMoneyList(key string, defaultValue map[string]models.Money) map[string]models.Money {
+	if _, exists := os.LookupEnv(key); !exists {
+		return defaultValue
+	}
+	amounts := map[string]models.Money{}
+	for _, value := range getEnvList(key) {
+		currency, amount, _ := strings.Cut(value, ":")
+		money, err := models.ParseMoney(amount, strings.ToUpper(currency))
+		if err != nil {
+			log.Printf("Invalid amount %q in %s, ignoring it: %v", value, key, err)
+			continue
+		}
+		amounts[money.Currency] = money
+	}
+	return amounts
+}
+
+func getEnvIntList(key string) []int {
+	numbers := []int{}
+	for _, value := range getEnvList(key) {
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			log.Printf("Invalid number %q in %s, ignoring it", value, key)
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
@@ -0,0 +1,33 @@
+package dto
+
+type (
+	// ApiKeyRequest creates a key acting as staff of the tenant by default,
+	// customer keys act for the user of UserID
+	ApiKeyRequest struct {
+		Name        string   `json:"name" validate:"required"`
+		Role        string   `json:"role"`
+		UserID      int      `json:"user_id"`
+		Scopes      []string `json:"scopes" validate:"required"`
+		QuotaPerDay int      `json:"quota_per_day"`
+	}
+
+	ApiKeyResponse struct {
+		ID          int      `json:"id"`
+		Name        string   `json:"name"`
+		Prefix      string   `json:"prefix"`
+		Role        string   `json:"role"`
+		UserID      int      `json:"user_id,omitempty"`
+		Scopes      []string `json:"scopes"`
+		QuotaPerDay int      `json:"quota_per_day"`
+		UsedToday   int      `json:"used_today"`
+		CreatedAt   string   `json:"created_at"`
+		LastUsedAt  string   `json:"last_used_at,omitempty"`
+		RevokedAt   string   `json:"revoked_at,omitempty"`
+	}
+
+	// ApiKeyCreatedResponse is the only response that contains the plain key
+	ApiKeyCreatedResponse struct {
+		ApiKeyResponse
+		Key string `json:"key"`
+	}
+)
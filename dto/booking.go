@@ -1,20 +1,99 @@
 package dto
 
+import (
+	"time"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
 type (
 	BookingRequest struct {
-		UserID    int     `json:"user_id" validate:"required"`
-		ServiceID int     `json:"service_id" validate:"required"`
-		Price     float64 `json:"price" validate:"required,gt=0"`
+		UserID    int `json:"user_id" validate:"required"`
+		ServiceID int `json:"service_id" validate:"required"`
+		// Price is calculated by the pricing rules, a different price is only
+		// accepted from staff
+		Price models.Money `json:"price,omitzero"`
+		// StartAt is an RFC3339 time, bookings without it do not take a slot
+		StartAt string `json:"start_at,omitempty"`
+		// Duration is a multiple of the service duration, one slot by default
+		Duration   string `json:"duration,omitempty"`
+		Notes      string `json:"notes,omitempty"`
+		CouponCode string `json:"coupon_code,omitempty"`
+		// SeriesID is set by the booking series that creates the booking
+		SeriesID int `json:"-"`
+	}
+
+	// RescheduleRequest moves a booking to another slot, service or both
+	RescheduleRequest struct {
+		ServiceID int    `json:"service_id,omitempty"`
+		StartAt   string `json:"start_at,omitempty"`
+		Reason    string `json:"reason,omitempty"`
+	}
+
+	BookingStatusRequest struct {
+		Status string `json:"status" validate:"required"`
 	}
 
 	BookingResponse struct {
-		ID        int     `json:"id"`
-		UserID    int     `json:"user_id"`
-		ServiceID int     `json:"service_id"`
-		Price     float64 `json:"price"`
-		Status    string  `json:"status"`
-		CreatedAt string  `json:"created_at"`
-		UpdatedAt string  `json:"updated_at"`
+		ID              int             `json:"id"`
+		UserID          int             `json:"user_id"`
+		ServiceID       int             `json:"service_id"`
+		Price           models.Money    `json:"price"`
+		Status          string          `json:"status"`
+		StartAt         time.Time       `json:"start_at,omitzero"`
+		EndAt           time.Time       `json:"end_at,omitzero"`
+		Duration        string          `json:"duration,omitempty"`
+		RescheduleCount int             `json:"reschedule_count,omitempty"`
+		RescheduleFees  models.Money    `json:"reschedule_fees,omitzero"`
+		Notes           string          `json:"notes,omitempty"`
+		CancellationFee models.Money    `json:"cancellation_fee,omitzero"`
+		RefundAmount    models.Money    `json:"refund_amount,omitzero"`
+		CouponCode      string          `json:"coupon_code,omitempty"`
+		Discount        models.Money    `json:"discount,omitzero"`
+		PriceBreakdown  *PriceBreakdown `json:"price_breakdown,omitempty"`
+		SeriesID        int             `json:"series_id,omitempty"`
+		// Resources are assigned to bookings with a slot of services that
+		// need them
+		Resources []BookingResource `json:"resources,omitempty"`
+		// HighValue is set when the price is above the threshold of its
+		// currency, these bookings go through the credit check
+		HighValue bool `json:"high_value,omitempty"`
+		// Deposit is paid up front by high value bookings, the rest of the
+		// price is due at BalanceDueAt
+		Deposit           models.Money `json:"deposit,omitzero"`
+		BalanceDueAt      time.Time    `json:"balance_due_at,omitzero"`
+		BalanceRemindedAt time.Time    `json:"balance_reminded_at,omitzero"`
+		// times are kept in UTC, In renders them in a display time zone
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	// CancellationQuote is what canceling the booking now would cost
+	CancellationQuote struct {
+		BookingID     int          `json:"booking_id"`
+		Paid          models.Money `json:"paid"`
+		FeePercent    float64      `json:"fee_percent"`
+		Fee           models.Money `json:"fee"`
+		Refund        models.Money `json:"refund"`
+		NonRefundable bool         `json:"non_refundable,omitempty"`
+		// FreeUntil is the last moment the booking can be canceled for free
+		FreeUntil time.Time `json:"free_until,omitzero"`
+	}
+
+	FieldChange struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+
+	BookingHistoryResponse struct {
+		ID        int                    `json:"id"`
+		BookingID int                    `json:"booking_id"`
+		Action    string                 `json:"action"`
+		Changes   map[string]FieldChange `json:"changes,omitempty"`
+		Reason    string                 `json:"reason,omitempty"`
+		ActorID   int                    `json:"actor_id,omitempty"`
+		ActorRole string                 `json:"actor_role,omitempty"`
+		CreatedAt time.Time              `json:"created_at"`
 	}
 
 	// SwaggerResponse represents a standard API response
@@ -26,5 +105,6 @@ type (
 	// ErrorResponse represents an error response
 	ErrorResponse struct {
 		Message string `json:"message"`
+		Code    string `json:"code,omitempty"`
 	}
 )
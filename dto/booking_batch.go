@@ -0,0 +1,58 @@
+package dto
+
+// batch operations
+const (
+	BatchCreate = "create"
+	BatchCancel = "cancel"
+	BatchUpdate = "update"
+)
+
+// batch modes
+const (
+	// BatchAllOrNothing applies every operation or none of them
+	BatchAllOrNothing = "all_or_nothing"
+	// BatchPartial applies the operations that succeed and reports the others
+	BatchPartial = "partial"
+)
+
+// statuses of a batch operation
+const (
+	BatchItemApplied    = "applied"
+	BatchItemFailed     = "failed"
+	BatchItemRolledBack = "rolled_back"
+)
+
+type (
+	// BookingBatchRequest runs the operations in order, each one sees the
+	// changes of the operations before it
+	BookingBatchRequest struct {
+		Mode       string             `json:"mode,omitempty"`
+		Operations []BookingOperation `json:"operations" validate:"required"`
+	}
+
+	// BookingOperation creates a booking from Booking, cancels booking ID or
+	// applies Patch to it
+	BookingOperation struct {
+		Op      string          `json:"op" validate:"required"`
+		ID      int             `json:"id,omitempty"`
+		Booking *BookingRequest `json:"booking,omitempty"`
+		Patch   *BookingPatch   `json:"patch,omitempty"`
+	}
+
+	// BookingBatchResult is the outcome of one operation
+	BookingBatchResult struct {
+		Index   int              `json:"index"`
+		Op      string           `json:"op"`
+		Status  string           `json:"status"`
+		Booking *BookingResponse `json:"booking,omitempty"`
+		Message string           `json:"message,omitempty"`
+		Code    string           `json:"code,omitempty"`
+	}
+
+	BookingBatchResponse struct {
+		Mode    string               `json:"mode"`
+		Applied int                  `json:"applied"`
+		Failed  int                  `json:"failed"`
+		Results []BookingBatchResult `json:"results"`
+	}
+)
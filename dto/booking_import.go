@@ -0,0 +1,60 @@
+package dto
+
+// import modes
+const (
+	// ImportAtomic commits every row or none of them
+	ImportAtomic = "atomic"
+	// ImportBestEffort commits the valid rows and reports the others
+	ImportBestEffort = "best_effort"
+)
+
+// statuses of an imported row
+const (
+	ImportRowImported = "imported"
+	ImportRowValid    = "valid"
+	ImportRowFailed   = "failed"
+)
+
+type (
+	// BookingImportRow is a booking to import, it is booked like a new
+	// booking and then moved to Status, pending by default. CreatedAt and
+	// UpdatedAt are only kept by migrations.
+	BookingImportRow struct {
+		BookingRequest
+		Status    string `json:"status,omitempty"`
+		CreatedAt string `json:"created_at,omitempty"`
+		UpdatedAt string `json:"updated_at,omitempty"`
+		// Line is the line of the row in the imported file
+		Line int `json:"-"`
+		// Error is set when the row could not be read, it fails as it is
+		Error string `json:"-"`
+	}
+
+	// BookingImportOptions tells how rows are imported, a dry run validates
+	// them without saving anything. A migration keeps the rows as they were
+	// in the system they come from instead of booking them.
+	BookingImportOptions struct {
+		Mode    string
+		DryRun  bool
+		Migrate bool
+	}
+
+	// BookingImportResult is the outcome of one row
+	BookingImportResult struct {
+		Line    int              `json:"line"`
+		Status  string           `json:"status"`
+		Booking *BookingResponse `json:"booking,omitempty"`
+		Message string           `json:"message,omitempty"`
+		Code    string           `json:"code,omitempty"`
+	}
+
+	BookingImportResponse struct {
+		Mode     string                `json:"mode"`
+		DryRun   bool                  `json:"dry_run"`
+		Migrate  bool                  `json:"migrate"`
+		Total    int                   `json:"total"`
+		Imported int                   `json:"imported"`
+		Failed   int                   `json:"failed"`
+		Rows     []BookingImportResult `json:"rows"`
+	}
+)
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+// BookingPatch is a JSON Merge Patch (RFC 7396) of a booking. Fields left out
+// of the document are not changed, "notes": null removes the notes.
+type BookingPatch struct {
+	Notes      *string       `json:"notes,omitempty"`
+	ClearNotes bool          `json:"-"`
+	ServiceID  *int          `json:"service_id,omitempty"`
+	Price      *models.Money `json:"price,omitempty"`
+	Status     *string       `json:"status,omitempty"`
+}
+
+// UnmarshalJSON rejects fields that cannot be patched and nulls on fields
+// that cannot be removed
+func (p *BookingPatch) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("patch must be a JSON object")
+	}
+
+	*p = BookingPatch{}
+	for name, raw := range fields {
+		isNull := string(raw) == "null"
+		var err error
+		switch name {
+		case "notes":
+			if isNull {
+				p.ClearNotes = true
+				continue
+			}
+			err = json.Unmarshal(raw, &p.Notes)
+		case "service_id":
+			if !isNull {
+				err = json.Unmarshal(raw, &p.ServiceID)
+			}
+		case "price":
+			if !isNull {
+				err = json.Unmarshal(raw, &p.Price)
+			}
+		case "status":
+			if !isNull {
+				err = json.Unmarshal(raw, &p.Status)
+			}
+		default:
+			return fmt.Errorf("field %s cannot be changed", name)
+		}
+		if isNull {
+			return fmt.Errorf("field %s cannot be removed", name)
+		}
+		if err != nil {
+			return fmt.Errorf("field %s has the wrong type", name)
+		}
+	}
+	return nil
+}
+
+// IsEmpty reports whether the patch changes nothing
+func (p BookingPatch) IsEmpty() bool {
+	return p.Notes == nil && !p.ClearNotes && p.ServiceID == nil && p.Price == nil && p.Status == nil
+}
@@ -0,0 +1,53 @@
+package dto
+
+import "time"
+
+type (
+	// BookingSeriesRequest books every occurrence of an RFC 5545 RRULE, e.g.
+	// "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10", from StartAt. Exceptions are
+	// RFC3339 occurrences that are not booked.
+	BookingSeriesRequest struct {
+		UserID     int      `json:"user_id,omitempty"`
+		ServiceID  int      `json:"service_id" validate:"required"`
+		StartAt    string   `json:"start_at" validate:"required"`
+		RRule      string   `json:"rrule" validate:"required"`
+		Exceptions []string `json:"exceptions,omitempty"`
+		Duration   string   `json:"duration,omitempty"`
+		Notes      string   `json:"notes,omitempty"`
+	}
+
+	// BookingSeriesUpdate changes the occurrence starting at From and every
+	// following one. StartAt is the new start of the From occurrence, the
+	// following ones move by the same amount.
+	BookingSeriesUpdate struct {
+		From      string  `json:"from" validate:"required"`
+		StartAt   string  `json:"start_at,omitempty"`
+		ServiceID int     `json:"service_id,omitempty"`
+		Notes     *string `json:"notes,omitempty"`
+	}
+
+	// SeriesConflict is an occurrence of a series that could not be booked
+	// or changed
+	SeriesConflict struct {
+		StartAt   time.Time `json:"start_at"`
+		BookingID int       `json:"booking_id,omitempty"`
+		Message   string    `json:"message"`
+		Code      string    `json:"code,omitempty"`
+	}
+
+	BookingSeriesResponse struct {
+		ID         int                `json:"id"`
+		UserID     int                `json:"user_id"`
+		ServiceID  int                `json:"service_id"`
+		RRule      string             `json:"rrule"`
+		StartAt    time.Time          `json:"start_at"`
+		Exceptions []time.Time        `json:"exceptions,omitempty"`
+		Duration   string             `json:"duration,omitempty"`
+		Notes      string             `json:"notes,omitempty"`
+		Status     string             `json:"status"`
+		Bookings   []*BookingResponse `json:"bookings"`
+		Conflicts  []SeriesConflict   `json:"conflicts,omitempty"`
+		CreatedAt  time.Time          `json:"created_at"`
+		UpdatedAt  time.Time          `json:"updated_at"`
+	}
+)
@@ -0,0 +1,78 @@
+package dto
+
+import "time"
+
+type (
+	// OpeningHours is a part of a day, times are 15:04 in the time zone of
+	// the service and close may be 24:00
+	OpeningHours struct {
+		Open  string `json:"open" validate:"required"`
+		Close string `json:"close" validate:"required"`
+	}
+
+	// BusinessHoursRequest replaces the weekly hours of a service, keys are
+	// weekdays such as monday and missing weekdays are closed
+	BusinessHoursRequest struct {
+		Weekly map[string][]OpeningHours `json:"weekly" validate:"required"`
+	}
+
+	BusinessHoursResponse struct {
+		ServiceID int    `json:"service_id"`
+		Timezone  string `json:"timezone"`
+		// AlwaysOpen is set when the service has no weekly hours
+		AlwaysOpen bool                      `json:"always_open,omitempty"`
+		Weekly     map[string][]OpeningHours `json:"weekly,omitempty"`
+		// Overrides are the ones from today on, including the ones for every
+		// service
+		Overrides []*HoursOverrideResponse `json:"overrides"`
+		UpdatedAt time.Time                `json:"updated_at,omitzero"`
+	}
+
+	// HoursOverrideRequest replaces the hours of a service on one date,
+	// without hours the service is closed all day and without service_id
+	// every service is
+	HoursOverrideRequest struct {
+		ServiceID int            `json:"service_id,omitempty"`
+		Date      string         `json:"date" validate:"required"`
+		Hours     []OpeningHours `json:"hours,omitempty"`
+		Reason    string         `json:"reason,omitempty"`
+	}
+
+	HoursOverrideResponse struct {
+		ID        int            `json:"id"`
+		ServiceID int            `json:"service_id,omitempty"`
+		Date      string         `json:"date"`
+		Closed    bool           `json:"closed"`
+		Hours     []OpeningHours `json:"hours,omitempty"`
+		Reason    string         `json:"reason,omitempty"`
+		Source    string         `json:"source"`
+		CreatedAt time.Time      `json:"created_at"`
+	}
+
+	// HolidayImportResponse lists the closures an iCalendar file created and
+	// the events that were left out
+	HolidayImportResponse struct {
+		Imported []*HoursOverrideResponse `json:"imported"`
+		Skipped  []SkippedHoliday         `json:"skipped,omitempty"`
+	}
+
+	SkippedHoliday struct {
+		UID     string `json:"uid,omitempty"`
+		Summary string `json:"summary,omitempty"`
+		Reason  string `json:"reason"`
+	}
+
+	// ScheduleDay is when a service is open on a date, after the overrides
+	ScheduleDay struct {
+		Date   string         `json:"date"`
+		Closed bool           `json:"closed"`
+		Hours  []OpeningHours `json:"hours,omitempty"`
+		Reason string         `json:"reason,omitempty"`
+	}
+
+	ScheduleResponse struct {
+		ServiceID int           `json:"service_id"`
+		Timezone  string        `json:"timezone"`
+		Days      []ScheduleDay `json:"days"`
+	}
+)
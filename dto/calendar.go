@@ -0,0 +1,18 @@
+package dto
+
+type (
+	// CalendarFeedRequest creates the calendar feed of a user, UserID is only
+	// accepted from staff
+	CalendarFeedRequest struct {
+		UserID int `json:"user_id,omitempty"`
+	}
+
+	// CalendarFeedResponse is only returned when the feed is created, the
+	// token cannot be shown again
+	CalendarFeedResponse struct {
+		UserID    int    `json:"user_id"`
+		Token     string `json:"token"`
+		URL       string `json:"url"`
+		CreatedAt string `json:"created_at"`
+	}
+)
@@ -0,0 +1,37 @@
+package dto
+
+import models "github.com/Eursukkul/fiber-booking-system/model"
+
+type (
+	// CouponRequest creates or replaces a coupon, percent coupons set
+	// Percent and fixed coupons set Amount, times are RFC3339
+	CouponRequest struct {
+		Code           string       `json:"code" validate:"required"`
+		Type           string       `json:"type" validate:"required,oneof=percent fixed"`
+		Percent        float64      `json:"percent,omitempty"`
+		Amount         models.Money `json:"amount,omitzero"`
+		ValidFrom      string       `json:"valid_from,omitempty"`
+		ValidUntil     string       `json:"valid_until,omitempty"`
+		MaxRedemptions int          `json:"max_redemptions,omitempty"`
+		MaxPerUser     int          `json:"max_per_user,omitempty"`
+		ServiceIDs     []int        `json:"service_ids,omitempty"`
+		Disabled       bool         `json:"disabled,omitempty"`
+	}
+
+	CouponResponse struct {
+		ID             int          `json:"id"`
+		Code           string       `json:"code"`
+		Type           string       `json:"type"`
+		Percent        float64      `json:"percent,omitempty"`
+		Amount         models.Money `json:"amount,omitzero"`
+		ValidFrom      string       `json:"valid_from,omitempty"`
+		ValidUntil     string       `json:"valid_until,omitempty"`
+		MaxRedemptions int          `json:"max_redemptions,omitempty"`
+		MaxPerUser     int          `json:"max_per_user,omitempty"`
+		ServiceIDs     []int        `json:"service_ids,omitempty"`
+		Disabled       bool         `json:"disabled,omitempty"`
+		Redeemed       int          `json:"redeemed"`
+		CreatedAt      string       `json:"created_at"`
+		UpdatedAt      string       `json:"updated_at"`
+	}
+)
@@ -0,0 +1,40 @@
+package dto
+
+import models "github.com/Eursukkul/fiber-booking-system/model"
+
+type (
+	InvoiceLineResponse struct {
+		Description string       `json:"description"`
+		Net         models.Money `json:"net"`
+		Tax         models.Money `json:"tax"`
+		Total       models.Money `json:"total"`
+	}
+
+	// InvoiceResponse is a tax invoice or a credit note, amounts include VAT
+	InvoiceResponse struct {
+		Number        string                `json:"number"`
+		Kind          string                `json:"kind"`
+		BookingID     int                   `json:"booking_id"`
+		UserID        int                   `json:"user_id"`
+		ServiceID     int                   `json:"service_id"`
+		IssuerName    string                `json:"issuer_name"`
+		IssuerTaxID   string                `json:"issuer_tax_id,omitempty"`
+		IssuerAddress string                `json:"issuer_address,omitempty"`
+		TaxRate       float64               `json:"tax_rate"`
+		Lines         []InvoiceLineResponse `json:"lines"`
+		Net           models.Money          `json:"net"`
+		Tax           models.Money          `json:"tax"`
+		Total         models.Money          `json:"total"`
+		RefersTo      string                `json:"refers_to,omitempty"`
+		IssuedAt      string                `json:"issued_at"`
+	}
+
+	// BookingInvoiceResponse are the documents of a booking, Balance is
+	// what was invoiced minus what was credited
+	BookingInvoiceResponse struct {
+		BookingID   int                `json:"booking_id"`
+		Invoices    []*InvoiceResponse `json:"invoices"`
+		CreditNotes []*InvoiceResponse `json:"credit_notes"`
+		Balance     models.Money       `json:"balance"`
+	}
+)
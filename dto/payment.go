@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+type (
+	PaymentResponse struct {
+		ID        int          `json:"id"`
+		BookingID int          `json:"booking_id"`
+		Kind      string       `json:"kind"`
+		Provider  string       `json:"provider"`
+		Reference string       `json:"reference"`
+		Amount    models.Money `json:"amount"`
+		Refunded  models.Money `json:"refunded,omitzero"`
+		Status    string       `json:"status"`
+		Message   string       `json:"message,omitempty"`
+		CreatedAt time.Time    `json:"created_at"`
+		UpdatedAt time.Time    `json:"updated_at"`
+	}
+
+	LedgerEntryResponse struct {
+		ID          int          `json:"id"`
+		PaymentID   int          `json:"payment_id"`
+		PaymentKind string       `json:"payment_kind"`
+		Kind        string       `json:"kind"`
+		Reference   string       `json:"reference"`
+		Amount      models.Money `json:"amount"`
+		CreatedAt   time.Time    `json:"created_at"`
+	}
+
+	// PaymentLedgerResponse lists every charge and refund of a booking, Paid
+	// is charged minus refunded and BalanceDue what is left to pay
+	PaymentLedgerResponse struct {
+		BookingID    int                    `json:"booking_id"`
+		Entries      []*LedgerEntryResponse `json:"entries"`
+		Charged      models.Money           `json:"charged"`
+		Refunded     models.Money           `json:"refunded"`
+		Paid         models.Money           `json:"paid"`
+		BalanceDue   models.Money           `json:"balance_due"`
+		BalanceDueAt time.Time              `json:"balance_due_at,omitzero"`
+	}
+
+	// PaymentCallback is sent by the payment provider when a pending
+	// capture finishes, Status is succeeded or declined
+	PaymentCallback struct {
+		Reference string `json:"reference"`
+		Status    string `json:"status"`
+		Message   string `json:"message,omitempty"`
+	}
+)
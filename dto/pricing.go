@@ -0,0 +1,32 @@
+package dto
+
+import models "github.com/Eursukkul/fiber-booking-system/model"
+
+type (
+	// PriceQuoteRequest asks what a booking would cost without creating it
+	PriceQuoteRequest struct {
+		UserID    int    `json:"user_id,omitempty"`
+		ServiceID int    `json:"service_id" validate:"required"`
+		StartAt   string `json:"start_at,omitempty"`
+		Duration  string `json:"duration,omitempty"`
+	}
+
+	// PriceAdjustment is one pricing rule applied to the price, negative
+	// amounts are discounts
+	PriceAdjustment struct {
+		Rule        string       `json:"rule"`
+		Description string       `json:"description"`
+		Amount      models.Money `json:"amount"`
+	}
+
+	// PriceBreakdown shows how the price of a booking was calculated,
+	// Override is set when staff replaced the calculated total
+	PriceBreakdown struct {
+		ServiceID   int               `json:"service_id"`
+		BasePrice   models.Money      `json:"base_price"`
+		Units       int               `json:"units"`
+		Adjustments []PriceAdjustment `json:"adjustments,omitempty"`
+		Total       models.Money      `json:"total"`
+		Override    bool              `json:"override,omitempty"`
+	}
+)
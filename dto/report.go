@@ -0,0 +1,81 @@
+package dto
+
+import models "github.com/Eursukkul/fiber-booking-system/model"
+
+// report groupings, the time groupings use the time the booking was made
+const (
+	ReportByDay     = "day"
+	ReportByWeek    = "week"
+	ReportByMonth   = "month"
+	ReportByService = "service"
+	ReportByStatus  = "status"
+)
+
+// ReportTotalKey is the key of the row that sums up every group
+const ReportTotalKey = "total"
+
+type (
+	// ReportFilter picks the bookings of a report, From and To are dates in
+	// the time zone of the report and both days are included
+	ReportFilter struct {
+		From      string
+		To        string
+		GroupBy   string
+		ServiceID int
+	}
+
+	// BookingReportRow counts the bookings of one group. Canceled does not
+	// count expired bookings, Converted counts the bookings that were
+	// confirmed at some point, even when they were canceled later.
+	BookingReportRow struct {
+		Key              string  `json:"key"`
+		Bookings         int     `json:"bookings"`
+		Pending          int     `json:"pending"`
+		DepositPaid      int     `json:"deposit_paid"`
+		Confirmed        int     `json:"confirmed"`
+		Rejected         int     `json:"rejected"`
+		Canceled         int     `json:"canceled"`
+		Expired          int     `json:"expired"`
+		Converted        int     `json:"converted"`
+		ConversionRate   float64 `json:"conversion_rate"`
+		CancellationRate float64 `json:"cancellation_rate"`
+		ExpiryRate       float64 `json:"expiry_rate"`
+		// CreditChecks are the payment authorizations of the bookings,
+		// AvgCreditCheckMs is how long they took on average
+		CreditChecks     int     `json:"credit_checks"`
+		AvgCreditCheckMs float64 `json:"avg_credit_check_ms"`
+	}
+
+	BookingReportResponse struct {
+		From     string             `json:"from"`
+		To       string             `json:"to"`
+		Timezone string             `json:"timezone"`
+		GroupBy  string             `json:"group_by"`
+		Rows     []BookingReportRow `json:"rows"`
+		Total    BookingReportRow   `json:"total"`
+	}
+
+	// RevenueReportRow is the money of one group in one currency, Bookings
+	// counts every booking of the group priced in it. Booked is the price of
+	// the bookings that are confirmed or have their deposit paid, Collected
+	// and Refunded are the charges and refunds of their payment ledger.
+	RevenueReportRow struct {
+		Key       string       `json:"key"`
+		Currency  string       `json:"currency"`
+		Bookings  int          `json:"bookings"`
+		Booked    models.Money `json:"booked"`
+		Collected models.Money `json:"collected"`
+		Refunded  models.Money `json:"refunded"`
+		Net       models.Money `json:"net"`
+	}
+
+	RevenueReportResponse struct {
+		From     string             `json:"from"`
+		To       string             `json:"to"`
+		Timezone string             `json:"timezone"`
+		GroupBy  string             `json:"group_by"`
+		Rows     []RevenueReportRow `json:"rows"`
+		// Totals has a row per currency
+		Totals []RevenueReportRow `json:"totals"`
+	}
+)
@@ -0,0 +1,69 @@
+package dto
+
+import "time"
+
+type (
+	// ResourceRequest creates or replaces a resource, type is staff, room
+	// or equipment
+	ResourceRequest struct {
+		Name     string   `json:"name" validate:"required"`
+		Type     string   `json:"type" validate:"required,oneof=staff room equipment"`
+		Skills   []string `json:"skills,omitempty"`
+		Disabled bool     `json:"disabled,omitempty"`
+	}
+
+	ResourceResponse struct {
+		ID        int       `json:"id"`
+		Name      string    `json:"name"`
+		Type      string    `json:"type"`
+		Skills    []string  `json:"skills,omitempty"`
+		Disabled  bool      `json:"disabled,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	// ResourceRequirement is the number of resources of a type, with all of
+	// the skills, each booking of a service needs
+	ResourceRequirement struct {
+		Type     string   `json:"type" validate:"required,oneof=staff room equipment"`
+		Skills   []string `json:"skills,omitempty"`
+		Quantity int      `json:"quantity" validate:"required,min=1"`
+	}
+
+	// ServiceRequirementsRequest replaces the requirements of a service, an
+	// empty list removes them
+	ServiceRequirementsRequest struct {
+		Requirements []ResourceRequirement `json:"requirements"`
+	}
+
+	ServiceRequirementsResponse struct {
+		ServiceID    int                   `json:"service_id"`
+		Requirements []ResourceRequirement `json:"requirements"`
+	}
+
+	// BookingResource is a resource assigned to a booking
+	BookingResource struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}
+
+	// ResourceScheduleResponse lists the active bookings a resource is
+	// assigned to, times are in Timezone
+	ResourceScheduleResponse struct {
+		Resource *ResourceResponse         `json:"resource"`
+		Timezone string                    `json:"timezone"`
+		From     string                    `json:"from"`
+		To       string                    `json:"to"`
+		Bookings []ResourceScheduleBooking `json:"bookings"`
+	}
+
+	ResourceScheduleBooking struct {
+		BookingID int       `json:"booking_id"`
+		ServiceID int       `json:"service_id"`
+		UserID    int       `json:"user_id"`
+		Status    string    `json:"status"`
+		StartAt   time.Time `json:"start_at"`
+		EndAt     time.Time `json:"end_at"`
+	}
+)
@@ -0,0 +1,92 @@
+package dto
+
+import "time"
+
+// inLocation keeps zero times zero so they stay out of the JSON
+func inLocation(t time.Time, location *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(location)
+}
+
+// In returns a copy of the booking with its times in location
+func (b *BookingResponse) In(location *time.Location) *BookingResponse {
+	res := *b
+	res.StartAt = inLocation(b.StartAt, location)
+	res.EndAt = inLocation(b.EndAt, location)
+	res.BalanceDueAt = inLocation(b.BalanceDueAt, location)
+	res.BalanceRemindedAt = inLocation(b.BalanceRemindedAt, location)
+	res.CreatedAt = inLocation(b.CreatedAt, location)
+	res.UpdatedAt = inLocation(b.UpdatedAt, location)
+	return &res
+}
+
+// In returns a copy of the quote with its times in location
+func (q *CancellationQuote) In(location *time.Location) *CancellationQuote {
+	res := *q
+	res.FreeUntil = inLocation(q.FreeUntil, location)
+	return &res
+}
+
+// In returns a copy of the history entry with its times in location
+func (h *BookingHistoryResponse) In(location *time.Location) *BookingHistoryResponse {
+	res := *h
+	res.CreatedAt = inLocation(h.CreatedAt, location)
+	return &res
+}
+
+// In returns a copy of the payment with its times in location
+func (p *PaymentResponse) In(location *time.Location) *PaymentResponse {
+	res := *p
+	res.CreatedAt = inLocation(p.CreatedAt, location)
+	res.UpdatedAt = inLocation(p.UpdatedAt, location)
+	return &res
+}
+
+// In returns a copy of the ledger with its times in location
+func (l *PaymentLedgerResponse) In(location *time.Location) *PaymentLedgerResponse {
+	res := *l
+	res.BalanceDueAt = inLocation(l.BalanceDueAt, location)
+	res.Entries = make([]*LedgerEntryResponse, len(l.Entries))
+	for i, entry := range l.Entries {
+		entryCopy := *entry
+		entryCopy.CreatedAt = inLocation(entry.CreatedAt, location)
+		res.Entries[i] = &entryCopy
+	}
+	return &res
+}
+
+// In returns a copy of the waitlist entry with its times in location
+func (w *WaitlistResponse) In(location *time.Location) *WaitlistResponse {
+	res := *w
+	res.StartAt = inLocation(w.StartAt, location)
+	res.EndAt = inLocation(w.EndAt, location)
+	res.OfferExpiresAt = inLocation(w.OfferExpiresAt, location)
+	res.CreatedAt = inLocation(w.CreatedAt, location)
+	res.UpdatedAt = inLocation(w.UpdatedAt, location)
+	return &res
+}
+
+// In returns a copy of the series and its bookings with their times in
+// location
+func (s *BookingSeriesResponse) In(location *time.Location) *BookingSeriesResponse {
+	res := *s
+	res.StartAt = inLocation(s.StartAt, location)
+	res.CreatedAt = inLocation(s.CreatedAt, location)
+	res.UpdatedAt = inLocation(s.UpdatedAt, location)
+	res.Exceptions = nil
+	for _, exception := range s.Exceptions {
+		res.Exceptions = append(res.Exceptions, inLocation(exception, location))
+	}
+	res.Bookings = make([]*BookingResponse, len(s.Bookings))
+	for i, booking := range s.Bookings {
+		res.Bookings[i] = booking.In(location)
+	}
+	res.Conflicts = nil
+	for _, conflict := range s.Conflicts {
+		conflict.StartAt = inLocation(conflict.StartAt, location)
+		res.Conflicts = append(res.Conflicts, conflict)
+	}
+	return &res
+}
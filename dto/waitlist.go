@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+type (
+	// WaitlistRequest joins the waitlist of a full slot, UserID is only
+	// accepted from staff
+	WaitlistRequest struct {
+		UserID    int    `json:"user_id,omitempty"`
+		ServiceID int    `json:"service_id" validate:"required"`
+		StartAt   string `json:"start_at" validate:"required"`
+	}
+
+	// WaitlistResponse is a waitlist entry, Position is the place in the
+	// queue of a waiting entry and BookingID is set once an offer is accepted
+	WaitlistResponse struct {
+		ID             int       `json:"id"`
+		UserID         int       `json:"user_id"`
+		ServiceID      int       `json:"service_id"`
+		StartAt        time.Time `json:"start_at"`
+		EndAt          time.Time `json:"end_at"`
+		Status         string    `json:"status"`
+		Position       int       `json:"position,omitempty"`
+		OfferExpiresAt time.Time `json:"offer_expires_at,omitzero"`
+		BookingID      int       `json:"booking_id,omitempty"`
+		CreatedAt      time.Time `json:"created_at"`
+		UpdatedAt      time.Time `json:"updated_at"`
+	}
+)
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	ApiKeyHandler struct {
+		ApiKeyUsecase usecase.ApiKeyUsecase
+	}
+)
+
+func NewApiKeyHandler(ApiKeyUsecase usecase.ApiKeyUsecase) *ApiKeyHandler {
+	return &ApiKeyHandler{ApiKeyUsecase: ApiKeyUsecase}
+}
+
+// CreateApiKey godoc
+// @Summary Create an API key
+// @Description Issue a new API key of the tenant of the admin with scopes and an optional daily quota, the key is only returned once
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param apikey body dto.ApiKeyRequest true "API Key Request"
+// @Success 201 {object} dto.SwaggerResponse{data=dto.ApiKeyCreatedResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /admin/api-keys [post]
+func (h *ApiKeyHandler) CreateApiKey(c *fiber.Ctx) error {
+	var req dto.ApiKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	key, err := h.ApiKeyUsecase.CreateApiKey(utils.TenantFromCtx(c), req)
+	if errors.Is(err, usecase.ErrInvalidApiKeyRequest) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(key)
+}
+
+// GetAllApiKeys godoc
+// @Summary List API keys
+// @Description List the API keys of the tenant with their scopes and usage
+// @Tags api-keys
+// @Produce json
+// @Success 200 {object} dto.SwaggerResponse{data=[]dto.ApiKeyResponse}
+// @Router /admin/api-keys [get]
+func (h *ApiKeyHandler) GetAllApiKeys(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(h.ApiKeyUsecase.GetAllApiKeys(utils.TenantFromCtx(c)))
+}
+
+// RevokeApiKey godoc
+// @Summary Revoke an API key
+// @Description Revoke an API key of the tenant by ID, it can no longer be used
+// @Tags api-keys
+// @Produce json
+// @Param id path int true "API Key ID"
+// @Success 200 {object} dto.SwaggerResponse
+// @Failure 400,404 {object} dto.ErrorResponse
+// @Router /admin/api-keys/{id} [delete]
+func (h *ApiKeyHandler) RevokeApiKey(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid API key ID",
+		})
+	}
+
+	if err := h.ApiKeyUsecase.RevokeApiKey(utils.TenantFromCtx(c), id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "API key revoked successfully",
+	})
+}
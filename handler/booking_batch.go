@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExecuteBatch godoc
+// @Summary Create, cancel and update bookings in one call
+// @Description Runs up to 100 create, cancel and update operations in order with the same checks as the single endpoints, each operation sees the ones before it. mode=all_or_nothing (default) applies every operation or none, mode=partial applies the ones that succeed. Every operation is reported with its index.
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param batch body dto.BookingBatchRequest true "Operations"
+// @Success 200 {object} dto.BookingBatchResponse
+// @Failure 400,403 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.BookingBatchResponse
+// @Router /bookings/batch [post]
+func (h *BookingHandler) ExecuteBatch(c *fiber.Ctx) error {
+	var req dto.BookingBatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	bookings := h.bookings(c)
+	res, err := bookings.ExecuteBatch(utils.ClaimsFromCtx(c), req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	for i, result := range res.Results {
+		if result.Booking == nil {
+			continue
+		}
+		if result.Op == dto.BatchCreate && result.Booking.Status == models.StatusPending {
+			h.startPayment(bookings, result.Booking.ID)
+		}
+		res.Results[i].Booking = result.Booking.In(h.location(c, result.Booking.ServiceID))
+	}
+
+	status := fiber.StatusOK
+	if res.Mode == dto.BatchAllOrNothing && res.Failed > 0 {
+		status = fiber.StatusUnprocessableEntity
+	}
+	return c.Status(status).JSON(res)
+}
@@ -0,0 +1,331 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// formats of exported and imported bookings
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl"
+)
+
+// bookingColumns are the columns of exported CSV files, imports read the
+// columns of a booking request and ignore the others
+var bookingColumns = []string{
+	"id", "user_id", "service_id", "status", "price", "currency", "start_at", "end_at", "duration",
+	"notes", "coupon_code", "discount", "high_value", "deposit", "refund_amount", "created_at", "updated_at",
+}
+
+// ExportBookings godoc
+// @Summary Export bookings
+// @Description Streams the bookings the caller can list as CSV or JSON Lines, with the filters of the list endpoint. Times are in the display time zone.
+// @Tags bookings
+// @Produce text/csv,application/x-ndjson
+// @Param format query string true "csv or jsonl"
+// @Param sort query string false "Sort by (price or date)"
+// @Param high-value query bool false "Filter high value bookings"
+// @Success 200 {string} string "Bookings"
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /bookings/export [get]
+func (h *BookingHandler) ExportBookings(c *fiber.Ctx) error {
+	format := c.Query("format")
+	if format != formatCSV && format != formatJSONL {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "format must be csv or jsonl",
+		})
+	}
+
+	bookings, err := h.bookings(c).GetAllBookings(utils.ClaimsFromCtx(c), c.Query("sort", "id"), c.Query("high-value"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve bookings",
+		})
+	}
+	// the ctx is released before the body is streamed, times are rendered
+	// while it is still valid
+	bookings = h.bookingsIn(c, bookings)
+
+	filename := "bookings." + format
+	if format == formatCSV {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	} else {
+		c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	}
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		var err error
+		if format == formatCSV {
+			err = writeBookingsCSV(w, bookings)
+		} else {
+			err = writeBookingsJSONL(w, bookings)
+		}
+		if err != nil {
+			// the status is sent already, the client sees a cut off file
+			fmt.Fprintf(w, "\nexport failed: %v\n", err)
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+func writeBookingsCSV(w io.Writer, bookings []*dto.BookingResponse) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(bookingColumns); err != nil {
+		return err
+	}
+	for _, booking := range bookings {
+		record := []string{
+			strconv.Itoa(booking.ID),
+			strconv.Itoa(booking.UserID),
+			strconv.Itoa(booking.ServiceID),
+			booking.Status,
+			booking.Price.Decimal(),
+			booking.Price.Currency,
+			csvTime(booking.StartAt),
+			csvTime(booking.EndAt),
+			booking.Duration,
+			booking.Notes,
+			booking.CouponCode,
+			csvMoney(booking.Discount),
+			strconv.FormatBool(booking.HighValue),
+			csvMoney(booking.Deposit),
+			csvMoney(booking.RefundAmount),
+			csvTime(booking.CreatedAt),
+			csvTime(booking.UpdatedAt),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeBookingsJSONL(w io.Writer, bookings []*dto.BookingResponse) error {
+	encoder := json.NewEncoder(w)
+	for _, booking := range bookings {
+		if err := encoder.Encode(booking); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// csvTime leaves the cell empty for unset times
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// csvMoney writes the amount without its currency, the currency column is
+// the currency of every amount of the booking
+func csvMoney(m models.Money) string {
+	if m.IsZero() {
+		return ""
+	}
+	return m.Decimal()
+}
+
+// ImportBookings godoc
+// @Summary Import bookings
+// @Description Books every row of a CSV or JSON Lines file like a new booking and moves it to its status (pending by default). mode=atomic (default) imports every row or none, mode=best_effort imports the valid rows. dry_run=true only validates the rows. migrate=true keeps the rows as they were in the system they come from: past slots, any status, the price and created_at/updated_at are kept, booking policies are skipped and nothing is charged or invoiced, active future slots still need room. Every row is reported with its line. Staff only.
+// @Tags bookings
+// @Accept text/csv,application/x-ndjson
+// @Produce json
+// @Param format query string false "csv or jsonl, taken from the Content-Type when empty"
+// @Param mode query string false "atomic or best_effort"
+// @Param dry_run query bool false "Validate without importing"
+// @Param migrate query bool false "Keep the rows as they were in the system they come from"
+// @Success 200 {object} dto.BookingImportResponse
+// @Failure 400,403 {object} dto.ErrorResponse
+// @Failure 422 {object} dto.BookingImportResponse
+// @Router /bookings/import [post]
+func (h *BookingHandler) ImportBookings(c *fiber.Ctx) error {
+	format := c.Query("format")
+	if format == "" {
+		format = importFormat(c.Get(fiber.HeaderContentType))
+	}
+
+	var rows []dto.BookingImportRow
+	var err error
+	switch format {
+	case formatCSV:
+		rows, err = readBookingsCSV(bytes.NewReader(c.Body()))
+	case formatJSONL:
+		rows, err = readBookingsJSONL(bytes.NewReader(c.Body()))
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "format must be csv or jsonl",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	options := dto.BookingImportOptions{
+		Mode:    c.Query("mode"),
+		DryRun:  c.QueryBool("dry_run"),
+		Migrate: c.QueryBool("migrate"),
+	}
+	bookings := h.bookings(c)
+	res, err := bookings.ImportBookings(utils.ClaimsFromCtx(c), rows, options)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	for i, row := range res.Rows {
+		if row.Booking == nil {
+			continue
+		}
+		// migrated bookings were charged by the system they come from
+		if row.Booking.Status == models.StatusPending && !res.Migrate {
+			h.startPayment(bookings, row.Booking.ID)
+		}
+		res.Rows[i].Booking = row.Booking.In(h.location(c, row.Booking.ServiceID))
+	}
+
+	status := fiber.StatusOK
+	if res.Mode == dto.ImportAtomic && res.Failed > 0 {
+		status = fiber.StatusUnprocessableEntity
+	}
+	return c.Status(status).JSON(res)
+}
+
+// importFormat is the format of a Content-Type
+func importFormat(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case "text/csv":
+		return formatCSV
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return formatJSONL
+	}
+	return ""
+}
+
+// readBookingsCSV reads the rows of a CSV file with a header line. Rows
+// that cannot be read are kept with their error so they are reported with
+// the others, only a file without the needed columns is an error.
+func readBookingsCSV(r io.Reader) ([]dto.BookingImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv file needs a header line")
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		// spreadsheets often start the file with a byte order mark
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"user_id", "service_id"} {
+		if _, exists := columns[name]; !exists {
+			return nil, fmt.Errorf("csv file needs a %s column", name)
+		}
+	}
+
+	rows := []dto.BookingImportRow{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			rows = append(rows, dto.BookingImportRow{Line: parseErr.Line, Error: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) != len(header) {
+			rows = append(rows, dto.BookingImportRow{Line: line, Error: "row has a different number of columns than the header"})
+			continue
+		}
+		rows = append(rows, bookingRowFromCSV(line, columns, record))
+	}
+	return rows, nil
+}
+
+func bookingRowFromCSV(line int, columns map[string]int, record []string) dto.BookingImportRow {
+	cell := func(name string) string {
+		if i, exists := columns[name]; exists {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	row := dto.BookingImportRow{
+		Line:      line,
+		Status:    cell("status"),
+		CreatedAt: cell("created_at"),
+		UpdatedAt: cell("updated_at"),
+		BookingRequest: dto.BookingRequest{
+			StartAt:    cell("start_at"),
+			Duration:   cell("duration"),
+			Notes:      cell("notes"),
+			CouponCode: cell("coupon_code"),
+		},
+	}
+
+	var err error
+	if row.UserID, err = strconv.Atoi(cell("user_id")); err != nil {
+		row.Error = "user_id must be a number"
+		return row
+	}
+	if row.ServiceID, err = strconv.Atoi(cell("service_id")); err != nil {
+		row.Error = "service_id must be a number"
+		return row
+	}
+	if price := cell("price"); price != "" {
+		if row.Price, err = models.ParseMoney(price, strings.ToUpper(cell("currency"))); err != nil {
+			row.Error = "price: " + err.Error()
+		}
+	}
+	return row
+}
+
+// readBookingsJSONL reads one booking request per line, blank lines are
+// skipped
+func readBookingsJSONL(r io.Reader) ([]dto.BookingImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	rows := []dto.BookingImportRow{}
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		row := dto.BookingImportRow{}
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			row = dto.BookingImportRow{Error: "invalid json: " + err.Error()}
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
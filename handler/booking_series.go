@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateBookingSeries godoc
+// @Summary Create a recurring booking
+// @Description Books every occurrence of an RFC 5545 RRULE (FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL, COUNT or UNTIL, BYDAY) from start_at, except the exceptions. Occurrences that cannot be booked are listed in conflicts.
+// @Tags booking-series
+// @Accept json
+// @Produce json
+// @Param series body dto.BookingSeriesRequest true "Booking Series Request"
+// @Success 201 {object} dto.BookingSeriesResponse
+// @Failure 400,404,409 {object} dto.ErrorResponse
+// @Router /booking-series [post]
+func (h *BookingHandler) CreateBookingSeries(c *fiber.Ctx) error {
+	var req dto.BookingSeriesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	series, err := h.bookings(c).CreateBookingSeries(utils.ClaimsFromCtx(c), req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	for _, booking := range series.Bookings {
+		h.startPayment(h.bookings(c), booking.ID)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(series.In(h.location(c, series.ServiceID)))
+}
+
+// GetBookingSeries godoc
+// @Summary Get a recurring booking
+// @Description Get a series with its bookings
+// @Tags booking-series
+// @Produce json
+// @Param id path int true "Series ID"
+// @Success 200 {object} dto.BookingSeriesResponse
+// @Failure 400,403,404 {object} dto.ErrorResponse
+// @Router /booking-series/{id} [get]
+func (h *BookingHandler) GetBookingSeries(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid series ID",
+		})
+	}
+
+	series, err := h.bookings(c).GetBookingSeries(utils.ClaimsFromCtx(c), id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(series.In(h.location(c, series.ServiceID)))
+}
+
+// UpdateBookingSeries godoc
+// @Summary Change this and following occurrences
+// @Description Moves the occurrence starting at from to start_at and every following one by the same amount, and/or changes their service or notes. Bookings that cannot be changed are listed in conflicts.
+// @Tags booking-series
+// @Accept json
+// @Produce json
+// @Param id path int true "Series ID"
+// @Param update body dto.BookingSeriesUpdate true "Booking Series Update"
+// @Success 200 {object} dto.BookingSeriesResponse
+// @Failure 400,403,404 {object} dto.ErrorResponse
+// @Router /booking-series/{id} [patch]
+func (h *BookingHandler) UpdateBookingSeries(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid series ID",
+		})
+	}
+	var req dto.BookingSeriesUpdate
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	series, err := h.bookings(c).UpdateBookingSeries(utils.ClaimsFromCtx(c), id, req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(series.In(h.location(c, series.ServiceID)))
+}
+
+// CancelBookingSeries godoc
+// @Summary Cancel this and following occurrences
+// @Description Cancels the occurrence starting at from and every following one, without from every upcoming booking and the series are canceled
+// @Tags booking-series
+// @Produce json
+// @Param id path int true "Series ID"
+// @Param from query string false "RFC3339 start of the first occurrence to cancel"
+// @Success 200 {object} dto.BookingSeriesResponse
+// @Failure 400,403,404 {object} dto.ErrorResponse
+// @Router /booking-series/{id} [delete]
+func (h *BookingHandler) CancelBookingSeries(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid series ID",
+		})
+	}
+
+	series, err := h.bookings(c).CancelBookingSeries(utils.ClaimsFromCtx(c), id, c.Query("from"))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(series.In(h.location(c, series.ServiceID)))
+}
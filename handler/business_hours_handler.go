@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	BusinessHoursHandler struct {
+		BusinessHoursUsecase usecase.BusinessHoursUsecase
+		Tenants              map[string]usecase.BusinessHoursUsecase
+	}
+)
+
+func NewBusinessHoursHandler(businessHoursUsecase usecase.BusinessHoursUsecase) *BusinessHoursHandler {
+	return &BusinessHoursHandler{BusinessHoursUsecase: businessHoursUsecase}
+}
+
+// GetBusinessHours godoc
+// @Summary Get the business hours of a service
+// @Description Weekly opening hours in the time zone of the service and the overrides from today on
+// @Tags business-hours
+// @Produce json
+// @Param id path int true "Service ID"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.BusinessHoursResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /admin/services/{id}/hours [get]
+func (h *BusinessHoursHandler) GetBusinessHours(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid service ID",
+		})
+	}
+
+	hours, err := h.hours(c).GetBusinessHours(id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(hours)
+}
+
+// SetBusinessHours godoc
+// @Summary Set the business hours of a service
+// @Description Replace the weekly opening hours, weekdays left out are closed. Bookings outside the hours are rejected from now on, existing ones are kept.
+// @Tags business-hours
+// @Accept json
+// @Produce json
+// @Param id path int true "Service ID"
+// @Param hours body dto.BusinessHoursRequest true "Weekly hours"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.BusinessHoursResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /admin/services/{id}/hours [put]
+func (h *BusinessHoursHandler) SetBusinessHours(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid service ID",
+		})
+	}
+
+	var req dto.BusinessHoursRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	hours, err := h.hours(c).SetBusinessHours(id, req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(hours)
+}
+
+// DeleteBusinessHours godoc
+// @Summary Remove the business hours of a service
+// @Description The service is open all the time again, date overrides still apply
+// @Tags business-hours
+// @Produce json
+// @Param id path int true "Service ID"
+// @Success 200 {object} dto.SwaggerResponse
+// @Failure 400,404 {object} dto.ErrorResponse
+// @Router /admin/services/{id}/hours [delete]
+func (h *BusinessHoursHandler) DeleteBusinessHours(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid service ID",
+		})
+	}
+
+	if err := h.hours(c).DeleteBusinessHours(id); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Business hours removed successfully",
+	})
+}
+
+// GetHoursOverrides godoc
+// @Summary List date overrides
+// @Description List holidays, closures and special hours of a service including the ones for every service, without service_id only the ones for every service
+// @Tags business-hours
+// @Produce json
+// @Param service_id query int false "Service ID"
+// @Success 200 {object} dto.SwaggerResponse{data=[]dto.HoursOverrideResponse}
+// @Router /admin/hours-overrides [get]
+func (h *BusinessHoursHandler) GetHoursOverrides(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(h.hours(c).GetHoursOverrides(c.QueryInt("service_id")))
+}
+
+// CreateHoursOverride godoc
+// @Summary Override the hours of a date
+// @Description Close a service, or every service without service_id, on a date or set special hours for it. Replaces the override of the same date.
+// @Tags business-hours
+// @Accept json
+// @Produce json
+// @Param override body dto.HoursOverrideRequest true "Date override"
+// @Success 201 {object} dto.SwaggerResponse{data=dto.HoursOverrideResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /admin/hours-overrides [post]
+func (h *BusinessHoursHandler) CreateHoursOverride(c *fiber.Ctx) error {
+	var req dto.HoursOverrideRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	override, err := h.hours(c).CreateHoursOverride(req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(override)
+}
+
+// DeleteHoursOverride godoc
+// @Summary Delete a date override
+// @Description The date follows the weekly hours again
+// @Tags business-hours
+// @Produce json
+// @Param id path int true "Override ID"
+// @Success 200 {object} dto.SwaggerResponse
+// @Failure 400,404 {object} dto.ErrorResponse
+// @Router /admin/hours-overrides/{id} [delete]
+func (h *BusinessHoursHandler) DeleteHoursOverride(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid override ID",
+		})
+	}
+
+	if err := h.hours(c).DeleteHoursOverride(id); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Override deleted successfully",
+	})
+}
+
+// ImportHolidays godoc
+// @Summary Import holidays from an iCalendar file
+// @Description Close a service, or every service without service_id, on each day of the all-day events of the file. Importing the file again replaces the closures of the same dates.
+// @Tags business-hours
+// @Accept text/calendar
+// @Produce json
+// @Param service_id query int false "Service ID"
+// @Param calendar body string true "iCalendar file"
+// @Success 201 {object} dto.SwaggerResponse{data=dto.HolidayImportResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /admin/holidays/import [post]
+func (h *BusinessHoursHandler) ImportHolidays(c *fiber.Ctx) error {
+	res, err := h.hours(c).ImportHolidays(c.QueryInt("service_id"), string(c.Body()))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+// GetSchedule godoc
+// @Summary Get the opening hours of a service by date
+// @Description When the service can be booked on each date from from to to, a week from today by default and at most 62 days
+// @Tags business-hours
+// @Produce json
+// @Param id path int true "Service ID"
+// @Param from query string false "First date, 2006-01-02"
+// @Param to query string false "Last date, 2006-01-02"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.ScheduleResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /services/{id}/schedule [get]
+func (h *BusinessHoursHandler) GetSchedule(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid service ID",
+		})
+	}
+
+	schedule, err := h.hours(c).GetSchedule(id, c.Query("from"), c.Query("to"))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(schedule)
+}
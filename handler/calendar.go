@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// mimeTextCalendar is the content type of iCalendar files
+const mimeTextCalendar = "text/calendar; charset=utf-8"
+
+// GetBookingCalendar godoc
+// @Summary Export a booking to a calendar
+// @Description Returns the slot of the booking as an RFC 5545 iCalendar file, canceled bookings have STATUS:CANCELLED
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path int true "Booking ID"
+// @Success 200 {string} string "iCalendar file"
+// @Failure 400,403,404,409 {object} dto.ErrorResponse
+// @Router /bookings/{id}/ics [get]
+func (h *BookingHandler) GetBookingCalendar(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid booking ID",
+		})
+	}
+
+	calendar, err := h.bookings(c).GetBookingCalendar(utils.ClaimsFromCtx(c), id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, mimeTextCalendar)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="booking-%d.ics"`, id))
+	return c.Status(fiber.StatusOK).SendString(calendar)
+}
+
+// CreateCalendarFeed godoc
+// @Summary Create a calendar subscription feed
+// @Description Returns a secret feed URL calendar apps can subscribe to, creating a new feed revokes the previous one. Staff can create the feed of another user.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param feed body dto.CalendarFeedRequest false "Calendar Feed Request"
+// @Success 201 {object} dto.CalendarFeedResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /calendar/feed [post]
+func (h *BookingHandler) CreateCalendarFeed(c *fiber.Ctx) error {
+	var req dto.CalendarFeedRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Invalid request body",
+			})
+		}
+	}
+
+	feed, err := h.bookings(c).CreateCalendarFeed(utils.ClaimsFromCtx(c), req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(feed)
+}
+
+// GetCalendarFeed godoc
+// @Summary Calendar subscription feed
+// @Description Every booking with a slot of the feed owner as RFC 5545 VEVENTs, the token in the URL authenticates the request
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token"
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /calendar/{token}.ics [get]
+func (h *BookingHandler) GetCalendarFeed(c *fiber.Ctx) error {
+	var calendar string
+	err := h.findInTenants(c, usecase.ErrCalendarFeedNotFound, func(bookings usecase.BookingUsecase) (err error) {
+		calendar, err = bookings.GetCalendarFeed(c.Params("token"))
+		return err
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, mimeTextCalendar)
+	return c.Status(fiber.StatusOK).SendString(calendar)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	CouponHandler struct {
+		CouponUsecase usecase.CouponUsecase
+		Tenants       map[string]usecase.CouponUsecase
+	}
+)
+
+func NewCouponHandler(couponUsecase usecase.CouponUsecase) *CouponHandler {
+	return &CouponHandler{CouponUsecase: couponUsecase}
+}
+
+// CreateCoupon godoc
+// @Summary Create a coupon
+// @Description Create a percent or fixed promo code with an optional validity window, usage limits and services
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Param coupon body dto.CouponRequest true "Coupon Request"
+// @Success 201 {object} dto.SwaggerResponse{data=dto.CouponResponse}
+// @Failure 400,409 {object} dto.ErrorResponse
+// @Router /admin/coupons [post]
+func (h *CouponHandler) CreateCoupon(c *fiber.Ctx) error {
+	var req dto.CouponRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	coupon, err := h.coupons(c).CreateCoupon(req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(coupon)
+}
+
+// GetAllCoupons godoc
+// @Summary List coupons
+// @Description List coupons with the number of redemptions in use
+// @Tags coupons
+// @Produce json
+// @Success 200 {object} dto.SwaggerResponse{data=[]dto.CouponResponse}
+// @Router /admin/coupons [get]
+func (h *CouponHandler) GetAllCoupons(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(h.coupons(c).GetAllCoupons())
+}
+
+// GetCoupon godoc
+// @Summary Get a coupon
+// @Description Get a coupon by ID
+// @Tags coupons
+// @Produce json
+// @Param id path int true "Coupon ID"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.CouponResponse}
+// @Failure 400,404 {object} dto.ErrorResponse
+// @Router /admin/coupons/{id} [get]
+func (h *CouponHandler) GetCoupon(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid coupon ID",
+		})
+	}
+
+	coupon, err := h.coupons(c).GetCoupon(id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(coupon)
+}
+
+// UpdateCoupon godoc
+// @Summary Update a coupon
+// @Description Replace the settings of a coupon, redemptions already made are kept
+// @Tags coupons
+// @Accept json
+// @Produce json
+// @Param id path int true "Coupon ID"
+// @Param coupon body dto.CouponRequest true "Coupon Request"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.CouponResponse}
+// @Failure 400,404,409 {object} dto.ErrorResponse
+// @Router /admin/coupons/{id} [put]
+func (h *CouponHandler) UpdateCoupon(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid coupon ID",
+		})
+	}
+
+	var req dto.CouponRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	coupon, err := h.coupons(c).UpdateCoupon(id, req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(coupon)
+}
+
+// DeleteCoupon godoc
+// @Summary Delete a coupon
+// @Description Delete a coupon by ID, bookings keep the discount they were given
+// @Tags coupons
+// @Produce json
+// @Param id path int true "Coupon ID"
+// @Success 200 {object} dto.SwaggerResponse
+// @Failure 400,404 {object} dto.ErrorResponse
+// @Router /admin/coupons/{id} [delete]
+func (h *CouponHandler) DeleteCoupon(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid coupon ID",
+		})
+	}
+
+	if err := h.coupons(c).DeleteCoupon(id); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Coupon deleted successfully",
+	})
+}
@@ -1,19 +1,22 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
-	"math/rand"
 	"strconv"
 	"time"
 
 	"github.com/Eursukkul/fiber-booking-system/dto"
 	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
 	"github.com/gofiber/fiber/v2"
 )
 
 type (
 	BookingHandler struct {
 		BookingUsecase usecase.BookingUsecase
+		Tenants        map[string]usecase.BookingUsecase
 	}
 )
 
@@ -23,13 +26,13 @@ func NewBookingHandler(BookingUsecase usecase.BookingUsecase) *BookingHandler {
 
 // CreateBooking godoc
 // @Summary Create a new booking
-// @Description Create a new booking with user_id, service_id, and price
+// @Description Create a new booking with user_id, service_id, and price, start_at books a slot of the service
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param booking body dto.BookingRequest true "Booking Request"
 // @Success 201 {object} dto.SwaggerResponse{data=dto.BookingResponse}
-// @Failure 400 {object} dto.ErrorResponse
+// @Failure 400,403,409,422,429 {object} dto.ErrorResponse
 // @Router /bookings [post]
 func (h *BookingHandler) CreateBooking(c *fiber.Ctx) error {
 	var req dto.BookingRequest
@@ -39,30 +42,141 @@ func (h *BookingHandler) CreateBooking(c *fiber.Ctx) error {
 		})
 	}
 
-	booking, err := h.BookingUsecase.CreateBooking(req)
+	booking, err := h.bookings(c).CreateBooking(utils.ClaimsFromCtx(c), req)
+	var policyErr *usecase.PolicyError
+	if errors.As(err, &policyErr) {
+		return utils.NewResponse(c).Error(
+			policyErrorStatus(policyErr),
+			string(policyErr.Code),
+			policyErr.Message,
+		).Res()
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
 			"message": err.Error(),
 		})
 	}
 
-	if booking.Price > 50000 {
-		go func(id int) {
-			//Random status confirm or rejected
-			status := "confirmed"
-			if rand.Intn(2) == 0 {
-				status = "rejected"
-			}
-			time.Sleep(time.Second * 1) //Delay 1 second
-			//Update status
-			err := h.BookingUsecase.UpdateBookingStatus(id, status)
-			if err != nil {
-				log.Printf("Failed to update booking status: %v", err)
-			}
-		}(booking.ID)
+	h.startPayment(h.bookings(c), booking.ID)
+
+	return c.Status(fiber.StatusCreated).JSON(booking.In(h.location(c, booking.ServiceID)))
+}
+
+// bookingError writes the error of creating a booking, broken booking
+// policies carry their code
+func (h *BookingHandler) bookingError(c *fiber.Ctx, err error) error {
+	var policyErr *usecase.PolicyError
+	if errors.As(err, &policyErr) {
+		return utils.NewResponse(c).Error(
+			policyErrorStatus(policyErr),
+			string(policyErr.Code),
+			policyErr.Message,
+		).Res()
+	}
+	return c.Status(errorStatus(err)).JSON(fiber.Map{
+		"message": err.Error(),
+	})
+}
+
+// location is the time zone response times are rendered in, the zone asked
+// for with tz or Accept-Timezone wins over the display zone of the caller
+// and the service
+func (h *BookingHandler) location(c *fiber.Ctx, serviceID int) *time.Location {
+	if location := utils.LocationFromCtx(c); location != nil {
+		return location
+	}
+	return h.bookings(c).DisplayLocation(utils.ClaimsFromCtx(c), serviceID)
+}
+
+// bookingsIn renders every booking in its display time zone
+func (h *BookingHandler) bookingsIn(c *fiber.Ctx, bookings []*dto.BookingResponse) []*dto.BookingResponse {
+	res := make([]*dto.BookingResponse, len(bookings))
+	for i, booking := range bookings {
+		res[i] = booking.In(h.location(c, booking.ServiceID))
+	}
+	return res
+}
+
+// startPayment pays a new booking of the tenant in the background, the
+// payment confirms or rejects it
+func (h *BookingHandler) startPayment(bookings usecase.BookingUsecase, id int) {
+	go func() {
+		if _, err := bookings.StartPayment(id); err != nil {
+			log.Printf("Failed to start payment of booking %d: %v", id, err)
+		}
+	}()
+}
+
+// errorStatus maps usecase errors to HTTP status codes
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrBookingNotFound),
+		errors.Is(err, usecase.ErrInvoiceNotFound),
+		errors.Is(err, usecase.ErrPaymentNotFound),
+		errors.Is(err, usecase.ErrWaitlistNotFound),
+		errors.Is(err, usecase.ErrSeriesNotFound),
+		errors.Is(err, usecase.ErrCalendarFeedNotFound),
+		errors.Is(err, usecase.ErrBusinessHoursNotFound),
+		errors.Is(err, usecase.ErrHoursOverrideNotFound),
+		errors.Is(err, usecase.ErrResourceNotFound),
+		errors.Is(err, usecase.ErrCouponNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, usecase.ErrForbidden),
+		errors.Is(err, usecase.ErrFieldForbidden):
+		return fiber.StatusForbidden
+	case errors.Is(err, usecase.ErrInvalidStatus),
+		errors.Is(err, usecase.ErrInvalidPatch),
+		errors.Is(err, usecase.ErrInvalidCouponRequest),
+		errors.Is(err, usecase.ErrInvalidSlot),
+		errors.Is(err, usecase.ErrInvalidPrice),
+		errors.Is(err, usecase.ErrInvalidPaymentCallback),
+		errors.Is(err, usecase.ErrCurrencyMismatch),
+		errors.Is(err, usecase.ErrInvalidSeries),
+		errors.Is(err, usecase.ErrInvalidCalendarFeed),
+		errors.Is(err, usecase.ErrInvalidBusinessHours),
+		errors.Is(err, usecase.ErrInvalidHolidayCalendar),
+		errors.Is(err, usecase.ErrInvalidImport),
+		errors.Is(err, usecase.ErrInvalidBatch),
+		errors.Is(err, usecase.ErrInvalidReport),
+		errors.Is(err, usecase.ErrInvalidResource),
+		errors.Is(err, usecase.ErrServiceNotFound):
+		return fiber.StatusBadRequest
+	case errors.Is(err, usecase.ErrSlotFull),
+		errors.Is(err, usecase.ErrInvalidTransition),
+		errors.Is(err, usecase.ErrCouponUsedUp),
+		errors.Is(err, usecase.ErrCouponCodeTaken),
+		errors.Is(err, usecase.ErrSlotAvailable),
+		errors.Is(err, usecase.ErrAlreadyWaitlisted),
+		errors.Is(err, usecase.ErrNoWaitlistOffer),
+		errors.Is(err, usecase.ErrWaitlistEntryClosed),
+		errors.Is(err, usecase.ErrSeriesConflict),
+		errors.Is(err, usecase.ErrNoSlot),
+		errors.Is(err, usecase.ErrResourceInUse),
+		errors.Is(err, usecase.ErrNoResourceAvailable):
+		return fiber.StatusConflict
+	case errors.Is(err, usecase.ErrRescheduleNotAllowed),
+		errors.Is(err, usecase.ErrServiceClosed),
+		errors.Is(err, usecase.ErrCouponInvalid):
+		return fiber.StatusUnprocessableEntity
+	case errors.Is(err, usecase.ErrInvalidPaymentSignature):
+		return fiber.StatusUnauthorized
+	case errors.Is(err, usecase.ErrPaymentFailed):
+		return fiber.StatusBadGateway
 	}
+	return fiber.StatusInternalServerError
+}
 
-	return c.Status(fiber.StatusCreated).JSON(booking)
+// policyErrorStatus maps a broken booking policy to the HTTP status
+func policyErrorStatus(err *usecase.PolicyError) int {
+	switch err.Code {
+	case usecase.BlockedUserErr, usecase.BlockedServiceErr:
+		return fiber.StatusForbidden
+	case usecase.PendingLimitErr:
+		return fiber.StatusConflict
+	case usecase.VelocityErr:
+		return fiber.StatusTooManyRequests
+	}
+	return fiber.StatusUnprocessableEntity
 }
 
 // GetBookingByID godoc
@@ -73,7 +187,7 @@ func (h *BookingHandler) CreateBooking(c *fiber.Ctx) error {
 // @Produce json
 // @Param id path int true "Booking ID"
 // @Success 200 {object} dto.SwaggerResponse{data=dto.BookingResponse}
-// @Failure 404 {object} dto.ErrorResponse
+// @Failure 403,404 {object} dto.ErrorResponse
 // @Router /bookings/{id} [get]
 func (h *BookingHandler) GetBookingByID(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
@@ -83,14 +197,19 @@ func (h *BookingHandler) GetBookingByID(c *fiber.Ctx) error {
 		})
 	}
 
-	booking, err := h.BookingUsecase.GetBookingByID(id)
+	booking, err := h.bookings(c).GetBookingByID(utils.ClaimsFromCtx(c), id)
+	if errors.Is(err, usecase.ErrForbidden) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"message": "Booking not found",
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(booking)
+	return c.Status(fiber.StatusOK).JSON(booking.In(h.location(c, booking.ServiceID)))
 }
 
 // GetAllBookings godoc
@@ -107,25 +226,25 @@ func (h *BookingHandler) GetBookingByID(c *fiber.Ctx) error {
 func (h *BookingHandler) GetAllBookings(c *fiber.Ctx) error {
 	sortBy := c.Query("sort", "id")
 	highValue := c.Query("high-value")
-	bookings, err := h.BookingUsecase.GetAllBookings(sortBy, highValue)
+	bookings, err := h.bookings(c).GetAllBookings(utils.ClaimsFromCtx(c), sortBy, highValue)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve bookings",
 		})
 	}
 
-	return c.JSON(bookings)
+	return c.JSON(h.bookingsIn(c, bookings))
 }
 
 // CancelBooking godoc
 // @Summary Cancel a booking
-// @Description Cancel an existing booking by ID
+// @Description Cancel an existing booking by ID, the refund follows the cancellation policy of the service
 // @Tags bookings
 // @Accept json
 // @Produce json
 // @Param id path int true "Booking ID"
-// @Success 200 {object} dto.SwaggerResponse
-// @Failure 400,404 {object} dto.ErrorResponse
+// @Success 200 {object} dto.SwaggerResponse{data=dto.BookingResponse}
+// @Failure 400,403,404,409 {object} dto.ErrorResponse
 // @Router /bookings/{id} [delete]
 func (h *BookingHandler) CancelBooking(c *fiber.Ctx) error {
 	id, err := strconv.Atoi(c.Params("id"))
@@ -135,29 +254,203 @@ func (h *BookingHandler) CancelBooking(c *fiber.Ctx) error {
 		})
 	}
 
-	// ตรวจสอบสถานะก่อนยกเลิก
-	booking, err := h.BookingUsecase.GetBookingByID(id)
+	claims := utils.ClaimsFromCtx(c)
+
+	// ยกเลิกการจอง
+	err = h.bookings(c).CancelBooking(claims, id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	booking, err := h.bookings(c).GetBookingByID(claims, id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Booking canceled successfully",
+		"data":    booking.In(h.location(c, booking.ServiceID)),
+	})
+}
+
+// QuoteCancellation godoc
+// @Summary Quote the cancellation of a booking
+// @Description Show the fee and refund of canceling the booking now, nothing is changed
+// @Tags bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.CancellationQuote}
+// @Failure 400,403,404,409 {object} dto.ErrorResponse
+// @Router /bookings/{id}/cancellation-quote [get]
+func (h *BookingHandler) QuoteCancellation(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid booking ID",
+		})
+	}
+
+	quote, err := h.bookings(c).QuoteCancellation(utils.ClaimsFromCtx(c), id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	// quotes and history do not carry the service, they are shown in the
+	// zone of the caller
+	return c.Status(fiber.StatusOK).JSON(quote.In(h.location(c, 0)))
+}
+
+// UpdateBookingStatus godoc
+// @Summary Override the status of a booking
+// @Description Staff and admins can set the status of any booking
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param status body dto.BookingStatusRequest true "New status"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.BookingResponse}
+// @Failure 400,403,404,409 {object} dto.ErrorResponse
+// @Router /bookings/{id}/status [put]
+func (h *BookingHandler) UpdateBookingStatus(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid booking ID",
+		})
+	}
+
+	var req dto.BookingStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	claims := utils.ClaimsFromCtx(c)
+	if err := h.bookings(c).UpdateBooking(claims, id, req.Status); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	booking, err := h.bookings(c).GetBookingByID(claims, id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"message": "Booking not found",
 		})
 	}
 
-	if booking.Status == "confirmed" {
+	return c.Status(fiber.StatusOK).JSON(booking.In(h.location(c, booking.ServiceID)))
+}
+
+// PatchBooking godoc
+// @Summary Partially update a booking
+// @Description Apply a JSON Merge Patch to notes, service_id, price (staff only) and status, the status follows the booking state machine
+// @Tags bookings
+// @Accept application/merge-patch+json,json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param patch body dto.BookingPatch true "Fields to change"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.BookingResponse}
+// @Failure 400,403,404,409 {object} dto.ErrorResponse
+// @Router /bookings/{id} [patch]
+func (h *BookingHandler) PatchBooking(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid booking ID",
+		})
+	}
+
+	// merge patch documents are decoded by hand, BodyParser only knows the
+	// plain json content type
+	var patch dto.BookingPatch
+	if err := json.Unmarshal(c.Body(), &patch); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"message": "Cannot cancel a confirmed booking",
+			"message": err.Error(),
 		})
 	}
 
-	// ยกเลิกการจอง
-	err = h.BookingUsecase.CancelBooking(id)
+	booking, err := h.bookings(c).PatchBooking(utils.ClaimsFromCtx(c), id, patch)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
 			"message": err.Error(),
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Booking canceled successfully",
-	})
+	return c.Status(fiber.StatusOK).JSON(booking.In(h.location(c, booking.ServiceID)))
+}
+
+// RescheduleBooking godoc
+// @Summary Reschedule a booking
+// @Description Move a booking to a new slot and/or service, capacity and price are checked again and fees may apply
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param reschedule body dto.RescheduleRequest true "New slot or service"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.BookingResponse}
+// @Failure 400,403,404,409,422 {object} dto.ErrorResponse
+// @Router /bookings/{id}/reschedule [post]
+func (h *BookingHandler) RescheduleBooking(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid booking ID",
+		})
+	}
+
+	var req dto.RescheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	booking, err := h.bookings(c).RescheduleBooking(utils.ClaimsFromCtx(c), id, req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(booking.In(h.location(c, booking.ServiceID)))
+}
+
+// GetBookingHistory godoc
+// @Summary Get the history of a booking
+// @Description List every change made to a booking, oldest first
+// @Tags bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} dto.SwaggerResponse{data=[]dto.BookingHistoryResponse}
+// @Failure 400,403,404 {object} dto.ErrorResponse
+// @Router /bookings/{id}/history [get]
+func (h *BookingHandler) GetBookingHistory(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid booking ID",
+		})
+	}
+
+	history, err := h.bookings(c).GetBookingHistory(utils.ClaimsFromCtx(c), id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	location := h.location(c, 0)
+	res := make([]*dto.BookingHistoryResponse, len(history))
+	for i, entry := range history {
+		res[i] = entry.In(location)
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
 }
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"html/template"
+	"strings"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// invoiceTemplate renders the documents of a booking as a printable page,
+// browsers can save it as PDF
+var invoiceTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"title": func(kind string) string {
+		if kind == "credit_note" {
+			return "Credit Note"
+		}
+		return "Tax Invoice"
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="th">
+<head>
+<meta charset="utf-8">
+<title>Booking #{{.BookingID}} invoices</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+.document { page-break-after: always; margin-bottom: 3em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+td.amount, th.amount { text-align: right; }
+</style>
+</head>
+<body>
+{{range .Documents}}
+<div class="document">
+<h1>{{title .Kind}} {{.Number}}</h1>
+<p>{{.IssuerName}}{{if .IssuerTaxID}}<br>Tax ID {{.IssuerTaxID}}{{end}}{{if .IssuerAddress}}<br>{{.IssuerAddress}}{{end}}</p>
+<p>Issued {{.IssuedAt}}<br>Booking #{{.BookingID}}, customer #{{.UserID}}{{if .RefersTo}}<br>Corrects invoice {{.RefersTo}}{{end}}</p>
+<table>
+<tr><th>Description</th><th class="amount">Net</th><th class="amount">VAT {{.TaxRate}}%</th><th class="amount">Total</th></tr>
+{{range .Lines}}<tr><td>{{.Description}}</td><td class="amount">{{.Net}}</td><td class="amount">{{.Tax}}</td><td class="amount">{{.Total}}</td></tr>
+{{end}}<tr><th>Total</th><th class="amount">{{.Net}}</th><th class="amount">{{.Tax}}</th><th class="amount">{{.Total}}</th></tr>
+</table>
+</div>
+{{end}}
+</body>
+</html>
+`))
+
+// GetInvoice godoc
+// @Summary Get the invoices of a booking
+// @Description Invoices are issued when a booking is confirmed and credit notes when it is refunded, format=html or Accept text/html returns a printable document
+// @Tags bookings
+// @Produce json,html
+// @Param id path int true "Booking ID"
+// @Param format query string false "json or html"
+// @Success 200 {object} dto.BookingInvoiceResponse
+// @Failure 400,403,404 {object} dto.ErrorResponse
+// @Router /bookings/{id}/invoice [get]
+func (h *BookingHandler) GetInvoice(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid booking ID",
+		})
+	}
+
+	invoice, err := h.bookings(c).GetInvoice(utils.ClaimsFromCtx(c), id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	format := c.Query("format")
+	if format == "" && c.Accepts(fiber.MIMEApplicationJSON, fiber.MIMETextHTML) == fiber.MIMETextHTML {
+		format = "html"
+	}
+	if format != "html" {
+		return c.Status(fiber.StatusOK).JSON(invoice)
+	}
+
+	var page strings.Builder
+	err = invoiceTemplate.Execute(&page, struct {
+		BookingID int
+		Documents []*dto.InvoiceResponse
+	}{invoice.BookingID, append(append([]*dto.InvoiceResponse{}, invoice.Invoices...), invoice.CreditNotes...)})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "Failed to render invoice",
+		})
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Status(fiber.StatusOK).SendString(page.String())
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	JWKSHandler struct {
+		Keys *utils.KeySet
+	}
+)
+
+func NewJWKSHandler(keys *utils.KeySet) *JWKSHandler {
+	return &JWKSHandler{Keys: keys}
+}
+
+// GetJWKS godoc
+// @Summary Get the JSON Web Key Set
+// @Description Public keys other services use to verify tokens issued by this API
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(h.Keys.JWKS())
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetPayments godoc
+// @Summary Get the payments of a booking
+// @Description Payments are started when a booking is created, a captured payment confirms the booking
+// @Tags bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} dto.SwaggerResponse{data=[]dto.PaymentResponse}
+// @Failure 400,403,404 {object} dto.ErrorResponse
+// @Router /bookings/{id}/payments [get]
+func (h *BookingHandler) GetPayments(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid booking ID",
+		})
+	}
+
+	payments, err := h.bookings(c).GetPayments(utils.ClaimsFromCtx(c), id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	location := h.location(c, 0)
+	res := make([]*dto.PaymentResponse, len(payments))
+	for i, payment := range payments {
+		res[i] = payment.In(location)
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// PayBalance godoc
+// @Summary Pay the balance of a booking
+// @Description Charges what is left of the price of a booking that paid its deposit, a captured balance confirms the booking. Returns 204 when a price cut left nothing to pay.
+// @Tags bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} dto.PaymentResponse
+// @Success 204
+// @Failure 400,403,404,409,502 {object} dto.ErrorResponse
+// @Router /bookings/{id}/balance [post]
+func (h *BookingHandler) PayBalance(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid booking ID",
+		})
+	}
+
+	payment, err := h.bookings(c).PayBalance(utils.ClaimsFromCtx(c), id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if payment == nil {
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(payment.In(h.location(c, 0)))
+}
+
+// GetPaymentLedger godoc
+// @Summary Get the payment ledger of a booking
+// @Description Every charge and refund of a booking, the paid total and the balance still due
+// @Tags bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} dto.PaymentLedgerResponse
+// @Failure 400,403,404 {object} dto.ErrorResponse
+// @Router /bookings/{id}/ledger [get]
+func (h *BookingHandler) GetPaymentLedger(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid booking ID",
+		})
+	}
+
+	ledger, err := h.bookings(c).GetPaymentLedger(utils.ClaimsFromCtx(c), id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ledger.In(h.location(c, 0)))
+}
+
+// PaymentCallback godoc
+// @Summary Finish a pending payment
+// @Description Called by the payment provider when a capture succeeds or is declined, the body is signed with HMAC-SHA256 in X-Payment-Signature
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param X-Payment-Signature header string true "hex HMAC-SHA256 of the body"
+// @Param callback body dto.PaymentCallback true "Callback"
+// @Success 200 {object} dto.PaymentResponse
+// @Failure 400,401,404 {object} dto.ErrorResponse
+// @Router /payments/callback [post]
+func (h *BookingHandler) PaymentCallback(c *fiber.Ctx) error {
+	var payment *dto.PaymentResponse
+	err := h.findInTenants(c, usecase.ErrPaymentNotFound, func(bookings usecase.BookingUsecase) (err error) {
+		payment, err = bookings.HandlePaymentCallback(c.Get("X-Payment-Signature"), c.Body())
+		return err
+	})
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(payment)
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	PricingHandler struct {
+		PricingUsecase usecase.PricingUsecase
+		Tenants        map[string]usecase.PricingUsecase
+	}
+)
+
+func NewPricingHandler(pricingUsecase usecase.PricingUsecase) *PricingHandler {
+	return &PricingHandler{PricingUsecase: pricingUsecase}
+}
+
+// Quote godoc
+// @Summary Quote the price of a booking
+// @Description Calculate the price of a booking from the service base price and the pricing rules without creating it
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param quote body dto.PriceQuoteRequest true "Booking to price"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.PriceBreakdown}
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /pricing/quote [post]
+func (h *PricingHandler) Quote(c *fiber.Ctx) error {
+	var req dto.PriceQuoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	quote, err := h.pricing(c).Quote(utils.ClaimsFromCtx(c), req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(quote)
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// reportFilter reads the filters every report takes
+func reportFilter(c *fiber.Ctx) dto.ReportFilter {
+	return dto.ReportFilter{
+		From:      c.Query("from"),
+		To:        c.Query("to"),
+		GroupBy:   c.Query("group_by"),
+		ServiceID: c.QueryInt("service_id"),
+	}
+}
+
+// reportFormat is json unless csv is asked for
+func reportFormat(c *fiber.Ctx) (string, bool) {
+	format := c.Query("format", "json")
+	return format, format == "json" || format == formatCSV
+}
+
+// BookingReport godoc
+// @Summary Booking report
+// @Description Counts the bookings made in the range by status, with the conversion (pending to confirmed), cancellation and expiry rates and the average credit check latency of each group. Days, weeks and months without bookings are reported with zeros. Staff only.
+// @Tags reports
+// @Produce json,text/csv
+// @Param group_by query string false "day (default), week, month, service or status"
+// @Param from query string false "First day, e.g. 2024-12-01, 30 days before to by default"
+// @Param to query string false "Last day, today by default"
+// @Param service_id query int false "Only bookings of this service"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} dto.BookingReportResponse
+// @Failure 400,403 {object} dto.ErrorResponse
+// @Router /reports/bookings [get]
+func (h *BookingHandler) BookingReport(c *fiber.Ctx) error {
+	format, ok := reportFormat(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "format must be json or csv",
+		})
+	}
+
+	report, err := h.bookings(c).BookingReport(utils.ClaimsFromCtx(c), reportFilter(c), h.location(c, c.QueryInt("service_id")))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if format != formatCSV {
+		return c.Status(fiber.StatusOK).JSON(report)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="booking-report.csv"`)
+	return writeBookingReportCSV(c, report)
+}
+
+func writeBookingReportCSV(w io.Writer, report *dto.BookingReportResponse) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{
+		report.GroupBy, "bookings", "pending", "deposit_paid", "confirmed", "rejected", "canceled", "expired",
+		"converted", "conversion_rate", "cancellation_rate", "expiry_rate", "credit_checks", "avg_credit_check_ms",
+	})
+	for _, row := range append(report.Rows, report.Total) {
+		writer.Write([]string{
+			row.Key,
+			strconv.Itoa(row.Bookings),
+			strconv.Itoa(row.Pending),
+			strconv.Itoa(row.DepositPaid),
+			strconv.Itoa(row.Confirmed),
+			strconv.Itoa(row.Rejected),
+			strconv.Itoa(row.Canceled),
+			strconv.Itoa(row.Expired),
+			strconv.Itoa(row.Converted),
+			csvFloat(row.ConversionRate),
+			csvFloat(row.CancellationRate),
+			csvFloat(row.ExpiryRate),
+			strconv.Itoa(row.CreditChecks),
+			csvFloat(row.AvgCreditCheckMs),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// RevenueReport godoc
+// @Summary Revenue report
+// @Description Sums the booked price of confirmed bookings and the money collected and refunded for the bookings made in the range, by group and currency. Days, weeks and months without bookings are reported with zeros. Staff only.
+// @Tags reports
+// @Produce json,text/csv
+// @Param group_by query string false "day (default), week, month, service or status"
+// @Param from query string false "First day, e.g. 2024-12-01, 30 days before to by default"
+// @Param to query string false "Last day, today by default"
+// @Param service_id query int false "Only bookings of this service"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} dto.RevenueReportResponse
+// @Failure 400,403 {object} dto.ErrorResponse
+// @Router /reports/revenue [get]
+func (h *BookingHandler) RevenueReport(c *fiber.Ctx) error {
+	format, ok := reportFormat(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "format must be json or csv",
+		})
+	}
+
+	report, err := h.bookings(c).RevenueReport(utils.ClaimsFromCtx(c), reportFilter(c), h.location(c, c.QueryInt("service_id")))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+	if format != formatCSV {
+		return c.Status(fiber.StatusOK).JSON(report)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="revenue-report.csv"`)
+	return writeRevenueReportCSV(c, report)
+}
+
+func writeRevenueReportCSV(w io.Writer, report *dto.RevenueReportResponse) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{report.GroupBy, "currency", "bookings", "booked", "collected", "refunded", "net"})
+	for _, row := range append(report.Rows, report.Totals...) {
+		writer.Write([]string{
+			row.Key,
+			row.Currency,
+			strconv.Itoa(row.Bookings),
+			row.Booked.Decimal(),
+			row.Collected.Decimal(),
+			row.Refunded.Decimal(),
+			row.Net.Decimal(),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	ResourceHandler struct {
+		ResourceUsecase usecase.ResourceUsecase
+		Tenants         map[string]usecase.ResourceUsecase
+	}
+)
+
+func NewResourceHandler(resourceUsecase usecase.ResourceUsecase) *ResourceHandler {
+	return &ResourceHandler{ResourceUsecase: resourceUsecase}
+}
+
+// CreateResource godoc
+// @Summary Create a resource
+// @Description Create a technician (staff), room or equipment with skills, resources are assigned to bookings of services that need them
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Param resource body dto.ResourceRequest true "Resource Request"
+// @Success 201 {object} dto.SwaggerResponse{data=dto.ResourceResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /admin/resources [post]
+func (h *ResourceHandler) CreateResource(c *fiber.Ctx) error {
+	var req dto.ResourceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	resource, err := h.resources(c).CreateResource(req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(resource)
+}
+
+// GetAllResources godoc
+// @Summary List resources
+// @Description List every resource including disabled ones
+// @Tags resources
+// @Produce json
+// @Success 200 {object} dto.SwaggerResponse{data=[]dto.ResourceResponse}
+// @Router /admin/resources [get]
+func (h *ResourceHandler) GetAllResources(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(h.resources(c).GetAllResources())
+}
+
+// GetResource godoc
+// @Summary Get a resource
+// @Description Get a resource by ID
+// @Tags resources
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.ResourceResponse}
+// @Failure 400,404 {object} dto.ErrorResponse
+// @Router /admin/resources/{id} [get]
+func (h *ResourceHandler) GetResource(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid resource ID",
+		})
+	}
+
+	resource, err := h.resources(c).GetResource(id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(resource)
+}
+
+// UpdateResource godoc
+// @Summary Update a resource
+// @Description Replace the name, type, skills and disabled flag of a resource, bookings keep the resources they were assigned
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Param resource body dto.ResourceRequest true "Resource Request"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.ResourceResponse}
+// @Failure 400,404 {object} dto.ErrorResponse
+// @Router /admin/resources/{id} [put]
+func (h *ResourceHandler) UpdateResource(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid resource ID",
+		})
+	}
+
+	var req dto.ResourceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	resource, err := h.resources(c).UpdateResource(id, req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(resource)
+}
+
+// DeleteResource godoc
+// @Summary Delete a resource
+// @Description Delete a resource no upcoming booking is assigned to, disable it otherwise
+// @Tags resources
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Success 200 {object} dto.SwaggerResponse
+// @Failure 400,404,409 {object} dto.ErrorResponse
+// @Router /admin/resources/{id} [delete]
+func (h *ResourceHandler) DeleteResource(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid resource ID",
+		})
+	}
+
+	if err := h.resources(c).DeleteResource(id); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Resource deleted successfully",
+	})
+}
+
+// GetServiceRequirements godoc
+// @Summary Get the resource requirements of a service
+// @Description The resources each booking of the service needs
+// @Tags resources
+// @Produce json
+// @Param id path int true "Service ID"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.ServiceRequirementsResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /admin/services/{id}/requirements [get]
+func (h *ResourceHandler) GetServiceRequirements(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid service ID",
+		})
+	}
+
+	requirements, err := h.resources(c).GetServiceRequirements(id)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(requirements)
+}
+
+// SetServiceRequirements godoc
+// @Summary Set the resource requirements of a service
+// @Description Replace the resources each booking of the service needs, e.g. 1 staff with fiber and 1 room. An empty list removes them.
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Param id path int true "Service ID"
+// @Param requirements body dto.ServiceRequirementsRequest true "Requirements"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.ServiceRequirementsResponse}
+// @Failure 400 {object} dto.ErrorResponse
+// @Router /admin/services/{id}/requirements [put]
+func (h *ResourceHandler) SetServiceRequirements(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid service ID",
+		})
+	}
+
+	var req dto.ServiceRequirementsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	requirements, err := h.resources(c).SetServiceRequirements(id, req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(requirements)
+}
+
+// GetResourceSchedule godoc
+// @Summary Get the schedule of a resource
+// @Description The active bookings the resource is assigned to from from to to, a week from today by default and at most 62 days. Dates and times are in the zone asked for with tz or Accept-Timezone.
+// @Tags resources
+// @Produce json
+// @Param id path int true "Resource ID"
+// @Param from query string false "First date, 2006-01-02"
+// @Param to query string false "Last date, 2006-01-02"
+// @Param tz query string false "IANA time zone"
+// @Success 200 {object} dto.SwaggerResponse{data=dto.ResourceScheduleResponse}
+// @Failure 400,404 {object} dto.ErrorResponse
+// @Router /resources/{id}/schedule [get]
+func (h *ResourceHandler) GetResourceSchedule(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid resource ID",
+		})
+	}
+
+	schedule, err := h.resources(c).GetResourceSchedule(id, c.Query("from"), c.Query("to"), utils.LocationFromCtx(c))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(schedule)
+}
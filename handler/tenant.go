@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// forTenant returns the usecase of the tenant of the request. Every tenant
+// has its own usecases and repositories so one tenant never reads the data
+// of another. The Tenants field of every handler holds the usecases by
+// tenant id, requests of tenants missing from it use fallback.
+func forTenant[T any](c *fiber.Ctx, tenants map[string]T, fallback T) T {
+	if tenantUsecase, exists := tenants[utils.TenantFromCtx(c)]; exists {
+		return tenantUsecase
+	}
+	return fallback
+}
+
+func (h *BookingHandler) bookings(c *fiber.Ctx) usecase.BookingUsecase {
+	return forTenant(c, h.Tenants, h.BookingUsecase)
+}
+
+// findInTenants runs find with the usecase of the request, then with the
+// other tenants while find returns notFound. Payment callbacks and feed
+// tokens carry no tenant but are unique across tenants.
+func (h *BookingHandler) findInTenants(c *fiber.Ctx, notFound error, find func(usecase.BookingUsecase) error) error {
+	first := h.bookings(c)
+	err := find(first)
+	ids := make([]string, 0, len(h.Tenants))
+	for id := range h.Tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if !errors.Is(err, notFound) {
+			break
+		}
+		if h.Tenants[id] != first {
+			err = find(h.Tenants[id])
+		}
+	}
+	return err
+}
+
+func (h *PricingHandler) pricing(c *fiber.Ctx) usecase.PricingUsecase {
+	return forTenant(c, h.Tenants, h.PricingUsecase)
+}
+
+func (h *CouponHandler) coupons(c *fiber.Ctx) usecase.CouponUsecase {
+	return forTenant(c, h.Tenants, h.CouponUsecase)
+}
+
+func (h *BusinessHoursHandler) hours(c *fiber.Ctx) usecase.BusinessHoursUsecase {
+	return forTenant(c, h.Tenants, h.BusinessHoursUsecase)
+}
+
+func (h *ResourceHandler) resources(c *fiber.Ctx) usecase.ResourceUsecase {
+	return forTenant(c, h.Tenants, h.ResourceUsecase)
+}
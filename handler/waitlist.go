@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// JoinWaitlist godoc
+// @Summary Join the waitlist of a full slot
+// @Description Queue for a slot of a service that has no capacity left, the next waiting user is offered the slot when a booking in it is canceled, rejected or expires
+// @Tags waitlist
+// @Accept json
+// @Produce json
+// @Param entry body dto.WaitlistRequest true "Waitlist Request"
+// @Success 201 {object} dto.WaitlistResponse
+// @Failure 400,409 {object} dto.ErrorResponse
+// @Router /waitlist [post]
+func (h *BookingHandler) JoinWaitlist(c *fiber.Ctx) error {
+	var req dto.WaitlistRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid request body",
+		})
+	}
+
+	entry, err := h.bookings(c).JoinWaitlist(utils.ClaimsFromCtx(c), req)
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(entry.In(h.location(c, entry.ServiceID)))
+}
+
+// GetWaitlist godoc
+// @Summary Get waitlist entries
+// @Description Customers get their own entries, staff get every entry
+// @Tags waitlist
+// @Produce json
+// @Success 200 {array} dto.WaitlistResponse
+// @Router /waitlist [get]
+func (h *BookingHandler) GetWaitlist(c *fiber.Ctx) error {
+	entries, err := h.bookings(c).GetWaitlist(utils.ClaimsFromCtx(c))
+	if err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	res := make([]*dto.WaitlistResponse, len(entries))
+	for i, entry := range entries {
+		res[i] = entry.In(h.location(c, entry.ServiceID))
+	}
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// LeaveWaitlist godoc
+// @Summary Leave the waitlist
+// @Description Takes the entry out of the queue, an open offer goes to the next user
+// @Tags waitlist
+// @Produce json
+// @Param id path int true "Waitlist entry ID"
+// @Success 200 {object} dto.SwaggerResponse
+// @Failure 400,403,404,409 {object} dto.ErrorResponse
+// @Router /waitlist/{id} [delete]
+func (h *BookingHandler) LeaveWaitlist(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid waitlist entry ID",
+		})
+	}
+
+	if err := h.bookings(c).LeaveWaitlist(utils.ClaimsFromCtx(c), id); err != nil {
+		return c.Status(errorStatus(err)).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Left the waitlist",
+	})
+}
+
+// AcceptWaitlistOffer godoc
+// @Summary Accept a waitlist offer
+// @Description Books the slot offered to the entry before the offer expires, the booking is paid like a new booking
+// @Tags waitlist
+// @Produce json
+// @Param id path int true "Waitlist entry ID"
+// @Success 201 {object} dto.BookingResponse
+// @Failure 400,403,404,409,422,429 {object} dto.ErrorResponse
+// @Router /waitlist/{id}/accept [post]
+func (h *BookingHandler) AcceptWaitlistOffer(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Invalid waitlist entry ID",
+		})
+	}
+
+	booking, err := h.bookings(c).AcceptWaitlistOffer(utils.ClaimsFromCtx(c), id)
+	if err != nil {
+		return h.bookingError(c, err)
+	}
+	h.startPayment(h.bookings(c), booking.ID)
+
+	return c.Status(fiber.StatusCreated).JSON(booking.In(h.location(c, booking.ServiceID)))
+}
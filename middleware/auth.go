@@ -1,9 +1,12 @@
 package middleware
 
 import (
-	"os"
+	"errors"
 	"strings"
 
+	"github.com/Eursukkul/fiber-booking-system/config"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
 	"github.com/Eursukkul/fiber-booking-system/utils"
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,14 +16,35 @@ type middlewareHandlersErrCode string
 const (
 	routerCheckErr middlewareHandlersErrCode = "middlware-001"
 	jwtAuthErr     middlewareHandlersErrCode = "middlware-002"
+	roleErr        middlewareHandlersErrCode = "middlware-003"
 	apiKeyErr      middlewareHandlersErrCode = "middlware-005"
+	scopeErr       middlewareHandlersErrCode = "middlware-006"
+	quotaErr       middlewareHandlersErrCode = "middlware-007"
 )
 
 type AuthMiddleware struct {
+	cfg       *config.Config
+	jwtSecret string
+	keys      *utils.KeySet
+	apiKeys   usecase.ApiKeyUsecase
 }
 
-func NewAuthMiddleware() *AuthMiddleware {
-	return &AuthMiddleware{}
+// NewAuthMiddleware verifies tokens with keys when it is set (RS256/ES256),
+// otherwise with the shared HMAC secret from the config
+func NewAuthMiddleware(cfg *config.Config, keys *utils.KeySet, apiKeys usecase.ApiKeyUsecase) *AuthMiddleware {
+	return &AuthMiddleware{
+		cfg:       cfg,
+		jwtSecret: cfg.JWTSecret,
+		keys:      keys,
+		apiKeys:   apiKeys,
+	}
+}
+
+func (m *AuthMiddleware) parseToken(token string) (*utils.AuthMapClaims, error) {
+	if m.keys != nil {
+		return utils.ParseTokenWithKeySet(m.keys, token)
+	}
+	return utils.ParseToken(m.jwtSecret, token)
 }
 
 func (m *AuthMiddleware) JwtAuth() fiber.Handler {
@@ -34,7 +58,7 @@ func (m *AuthMiddleware) JwtAuth() fiber.Handler {
 				"Missing or invalid token",
 			).Res()
 		}
-		claims, err := utils.ParseToken(os.Getenv("JWT_SECRET"), token)
+		claims, err := m.parseToken(token)
 		if err != nil {
 			return utils.NewResponse(c).Error(
 				fiber.StatusUnauthorized,
@@ -45,20 +69,76 @@ func (m *AuthMiddleware) JwtAuth() fiber.Handler {
 
 		c.Locals("claims", claims)
 
+		tenant := ""
+		if claims.Claims != nil {
+			tenant = claims.Tenant
+		}
+		return setTenant(c, m.cfg, tenant, true)
+	}
+}
+
+// RequireRole must be placed after JwtAuth, it only lets callers with one of
+// the given roles through
+func (m *AuthMiddleware) RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims := utils.ClaimsFromCtx(c)
+		if claims == nil {
+			return utils.NewResponse(c).Error(
+				fiber.StatusUnauthorized,
+				string(jwtAuthErr),
+				"Missing or invalid token",
+			).Res()
+		}
+		if !claims.HasRole(roles...) {
+			return utils.NewResponse(c).Error(
+				fiber.StatusForbidden,
+				string(roleErr),
+				"You do not have permission to access this resource",
+			).Res()
+		}
 		return c.Next()
 	}
 }
 
 func (m *AuthMiddleware) ApiKeyAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		key := c.Get("X-Api-Key")
-		if _, err := utils.ParseApiKey(os.Getenv("API_KEY"), key); err != nil {
+		key, err := m.apiKeys.Authenticate(c.Get("X-Api-Key"))
+		if errors.Is(err, usecase.ErrApiKeyQuotaExceeded) {
+			return utils.NewResponse(c).Error(
+				fiber.StatusTooManyRequests,
+				string(quotaErr),
+				err.Error(),
+			).Res()
+		}
+		if err != nil {
 			return utils.NewResponse(c).Error(
 				fiber.ErrUnauthorized.Code,
 				string(apiKeyErr),
-				"apikey is invalid or required",
+				err.Error(),
+			).Res()
+		}
+
+		// the key acts with its role in its tenant, its scopes limit what it
+		// can do
+		c.Locals("apikey", key)
+		c.Locals("claims", &utils.AuthMapClaims{Claims: &utils.Claims{Id: key.UserID, Role: key.Role, Tenant: key.Tenant}})
+
+		return setTenant(c, m.cfg, key.Tenant, true)
+	}
+}
+
+// RequireScope must be placed after ApiKeyAuth, it only lets keys that were
+// granted the scope through
+func (m *AuthMiddleware) RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key, ok := c.Locals("apikey").(*models.ApiKey)
+		if !ok || !key.HasScope(scope) {
+			return utils.NewResponse(c).Error(
+				fiber.StatusForbidden,
+				string(scopeErr),
+				"apikey is missing the "+scope+" scope",
 			).Res()
 		}
 		return c.Next()
 	}
-}
\ No newline at end of file
+}
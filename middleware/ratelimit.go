@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const rateLimitErr middlewareHandlersErrCode = "middlware-008"
+
+type (
+	// RateLimitStore keeps the token buckets. The in-memory store only works for
+	// a single instance, a shared store (e.g. Redis) can implement the same interface.
+	RateLimitStore interface {
+		Take(key string, limit config.RateLimit, now time.Time) RateLimitResult
+	}
+
+	RateLimitResult struct {
+		Allowed    bool
+		Remaining  int
+		RetryAfter time.Duration
+		ResetAfter time.Duration
+	}
+
+	tokenBucket struct {
+		tokens float64
+		last   time.Time
+		period time.Duration
+	}
+
+	InMemoryRateLimitStore struct {
+		buckets   map[string]*tokenBucket
+		lastSweep time.Time
+		mu        sync.Mutex
+	}
+
+	RateLimitMiddleware struct {
+		store RateLimitStore
+	}
+)
+
+func NewInMemoryRateLimitStore() RateLimitStore {
+	return &InMemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Take removes one token from the bucket of the key, the bucket holds
+// limit.Requests tokens and refills them evenly over limit.Period
+func (s *InMemoryRateLimitStore) Take(key string, limit config.RateLimit, now time.Time) RateLimitResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Period.Seconds()
+
+	s.sweep(now)
+
+	bucket, exists := s.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: capacity, last: now, period: limit.Period}
+		s.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.last).Seconds()*rate)
+	bucket.last = now
+
+	result := RateLimitResult{}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+	}
+	result.Remaining = int(bucket.tokens)
+	result.ResetAfter = time.Duration((capacity - bucket.tokens) / rate * float64(time.Second))
+	return result
+}
+
+// sweep drops buckets that have been idle long enough to be full again
+func (s *InMemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.last) > bucket.period {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func NewRateLimitMiddleware(store RateLimitStore) *RateLimitMiddleware {
+	return &RateLimitMiddleware{store: store}
+}
+
+// Limit returns a handler that limits requests of the route group name. The
+// bucket is chosen by API key, then JWT subject, then client IP within the
+// tenant of the request, so it must be placed after JwtAuth or ApiKeyAuth on
+// authenticated routes.
+func (m *RateLimitMiddleware) Limit(name string, limit config.RateLimit) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if limit.Requests <= 0 || limit.Period <= 0 {
+			return c.Next()
+		}
+
+		result := m.store.Take(name+":"+rateLimitKey(c), limit, time.Now())
+
+		c.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			return utils.NewResponse(c).Error(
+				fiber.StatusTooManyRequests,
+				string(rateLimitErr),
+				"Too many requests, please retry later",
+			).Res()
+		}
+		return c.Next()
+	}
+}
+
+// rateLimitKey checks the API key before the claims, ApiKeyAuth sets claims
+// for every key and staff keys all carry user id 0
+func rateLimitKey(c *fiber.Ctx) string {
+	tenant := utils.TenantFromCtx(c) + ":"
+	if key, ok := c.Locals("apikey").(*models.ApiKey); ok {
+		return tenant + "key:" + strconv.Itoa(key.ID)
+	}
+	if claims := utils.ClaimsFromCtx(c); claims != nil {
+		return tenant + "user:" + strconv.Itoa(claims.Id)
+	}
+	return tenant + "ip:" + c.IP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
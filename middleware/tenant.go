@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const tenantErr middlewareHandlersErrCode = "middlware-010"
+
+// resolveTenant picks the tenant of the request. Authenticated requests
+// belong to the tenant of their token or API key, the default tenant when it
+// names none, and it must match the tenant of the host when the host belongs
+// to one. Anonymous requests belong to the tenant of the host, then to the
+// default tenant. Anyone can send any Host header, so anonymous routes only
+// give out public data such as quotes and schedules.
+func resolveTenant(c *fiber.Ctx, cfg *config.Config, claimed string, authenticated bool) (string, error) {
+	host := c.Hostname()
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	hostTenant, byHost := cfg.TenantForHost(host)
+
+	if !authenticated {
+		if byHost {
+			return hostTenant, nil
+		}
+		return cfg.DefaultTenant, nil
+	}
+	if claimed == "" {
+		claimed = cfg.DefaultTenant
+	}
+	if !cfg.HasTenant(claimed) {
+		return "", fmt.Errorf("unknown tenant %s", claimed)
+	}
+	if byHost && hostTenant != claimed {
+		return "", fmt.Errorf("credentials of tenant %s cannot be used on %s", claimed, host)
+	}
+	return claimed, nil
+}
+
+// setTenant resolves the tenant and stores it for utils.TenantFromCtx
+func setTenant(c *fiber.Ctx, cfg *config.Config, claimed string, authenticated bool) error {
+	tenant, err := resolveTenant(c, cfg, claimed, authenticated)
+	if err != nil {
+		return utils.NewResponse(c).Error(
+			fiber.StatusForbidden,
+			string(tenantErr),
+			err.Error(),
+		).Res()
+	}
+	c.Locals(utils.LocalsTenant, tenant)
+	return c.Next()
+}
+
+// Tenant resolves the tenant of the public routes, the tenant of the host
+// for anonymous requests. The JwtAuth and ApiKeyAuth middlewares resolve it
+// from the credentials.
+func Tenant(cfg *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if claims := utils.ClaimsFromCtx(c); claims != nil {
+			return setTenant(c, cfg, claims.Tenant, true)
+		}
+		return setTenant(c, cfg, "", false)
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+const timezoneErr middlewareHandlersErrCode = "middlware-009"
+
+// Timezone reads the IANA time zone the caller wants response times in from
+// the tz query parameter or the Accept-Timezone header, handlers read it with
+// utils.LocationFromCtx
+func Timezone() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		name := c.Query("tz")
+		if name == "" {
+			name = c.Get(utils.HeaderAcceptTimezone)
+		}
+		if name == "" {
+			return c.Next()
+		}
+
+		// Local would be the zone of the server
+		location, err := time.LoadLocation(name)
+		if err != nil || name == "Local" {
+			return utils.NewResponse(c).Error(
+				fiber.StatusBadRequest,
+				string(timezoneErr),
+				"Invalid time zone "+name+", use an IANA name such as Asia/Bangkok",
+			).Res()
+		}
+		c.Locals(utils.LocalsTimezone, location)
+		return c.Next()
+	}
+}
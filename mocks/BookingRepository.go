@@ -4,6 +4,7 @@ package mocks
 
 import (
 	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -13,8 +14,8 @@ type MockBookingRepository struct {
 }
 
 // Create mock data
-func (m *MockBookingRepository) Create(req dto.BookingRequest) *dto.BookingResponse {
-	args := m.Called(req)
+func (m *MockBookingRepository) Create(booking dto.BookingResponse) *dto.BookingResponse {
+	args := m.Called(booking)
 	return args.Get(0).(*dto.BookingResponse)
 }
 
@@ -30,9 +31,27 @@ func (m *MockBookingRepository) GetAll() []*dto.BookingResponse {
 	return args.Get(0).([]*dto.BookingResponse)
 }
 
+// GetByUserID mock data
+func (m *MockBookingRepository) GetByUserID(userID int) []*dto.BookingResponse {
+	args := m.Called(userID)
+	return args.Get(0).([]*dto.BookingResponse)
+}
+
+// GetByServiceID mock data
+func (m *MockBookingRepository) GetByServiceID(serviceID int) []*dto.BookingResponse {
+	args := m.Called(serviceID)
+	return args.Get(0).([]*dto.BookingResponse)
+}
+
+// UpdateBooking mock data
+func (m *MockBookingRepository) UpdateBooking(booking *dto.BookingResponse) error {
+	args := m.Called(booking)
+	return args.Error(0)
+}
+
 // GetHighValueBookings mock data
-func (m *MockBookingRepository) GetHighValueBookings(threshold float64) []*dto.BookingResponse {
-	args := m.Called(threshold)
+func (m *MockBookingRepository) GetHighValueBookings(thresholds map[string]models.Money) []*dto.BookingResponse {
+	args := m.Called(thresholds)
 	return args.Get(0).([]*dto.BookingResponse)
 }
 
@@ -41,3 +60,9 @@ func (m *MockBookingRepository) UpdateBookingStatus(id int, status string) error
 	args := m.Called(id, status)
 	return args.Error(0)
 }
+
+// Delete mock data
+func (m *MockBookingRepository) Delete(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
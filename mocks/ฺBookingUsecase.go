@@ -3,8 +3,10 @@ package mocks
 
 import (
 	"sync"
+	"time"
 
 	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/utils"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -13,27 +15,233 @@ type MockBookingUsecase struct {
 	mock.Mock
 }
 
-func (m *MockBookingUsecase) CreateBooking(req dto.BookingRequest) (*dto.BookingResponse, error) {
-	args := m.Called(req)
+func (m *MockBookingUsecase) CreateBooking(claims *utils.Claims, req dto.BookingRequest) (*dto.BookingResponse, error) {
+	args := m.Called(claims, req)
 	if args.Get(0) != nil {
 		return args.Get(0).(*dto.BookingResponse), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *MockBookingUsecase) GetBookingByID(id int) (*dto.BookingResponse, error) {
-	args := m.Called(id)
+func (m *MockBookingUsecase) GetBookingByID(claims *utils.Claims, id int) (*dto.BookingResponse, error) {
+	args := m.Called(claims, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.BookingResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) CancelBooking(claims *utils.Claims, id int) error {
+	args := m.Called(claims, id)
+	return args.Error(0)
+}
+
+func (m *MockBookingUsecase) RescheduleBooking(claims *utils.Claims, id int, req dto.RescheduleRequest) (*dto.BookingResponse, error) {
+	args := m.Called(claims, id, req)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.BookingResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) GetBookingHistory(claims *utils.Claims, id int) ([]*dto.BookingHistoryResponse, error) {
+	args := m.Called(claims, id)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*dto.BookingHistoryResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) PatchBooking(claims *utils.Claims, id int, patch dto.BookingPatch) (*dto.BookingResponse, error) {
+	args := m.Called(claims, id, patch)
 	if args.Get(0) != nil {
 		return args.Get(0).(*dto.BookingResponse), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *MockBookingUsecase) CancelBooking(id int) error {
+func (m *MockBookingUsecase) QuoteCancellation(claims *utils.Claims, id int) (*dto.CancellationQuote, error) {
+	args := m.Called(claims, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.CancellationQuote), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) GetInvoice(claims *utils.Claims, id int) (*dto.BookingInvoiceResponse, error) {
+	args := m.Called(claims, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.BookingInvoiceResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) StartPayment(id int) (*dto.PaymentResponse, error) {
 	args := m.Called(id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.PaymentResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) HandlePaymentCallback(signature string, payload []byte) (*dto.PaymentResponse, error) {
+	args := m.Called(signature, payload)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.PaymentResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) GetPayments(claims *utils.Claims, id int) ([]*dto.PaymentResponse, error) {
+	args := m.Called(claims, id)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*dto.PaymentResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) PayBalance(claims *utils.Claims, id int) (*dto.PaymentResponse, error) {
+	args := m.Called(claims, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.PaymentResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) GetPaymentLedger(claims *utils.Claims, id int) (*dto.PaymentLedgerResponse, error) {
+	args := m.Called(claims, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.PaymentLedgerResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) JoinWaitlist(claims *utils.Claims, req dto.WaitlistRequest) (*dto.WaitlistResponse, error) {
+	args := m.Called(claims, req)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.WaitlistResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) GetWaitlist(claims *utils.Claims) ([]*dto.WaitlistResponse, error) {
+	args := m.Called(claims)
+	if args.Get(0) != nil {
+		return args.Get(0).([]*dto.WaitlistResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) LeaveWaitlist(claims *utils.Claims, id int) error {
+	args := m.Called(claims, id)
 	return args.Error(0)
 }
 
+func (m *MockBookingUsecase) AcceptWaitlistOffer(claims *utils.Claims, id int) (*dto.BookingResponse, error) {
+	args := m.Called(claims, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.BookingResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) CreateBookingSeries(claims *utils.Claims, req dto.BookingSeriesRequest) (*dto.BookingSeriesResponse, error) {
+	args := m.Called(claims, req)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.BookingSeriesResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) GetBookingSeries(claims *utils.Claims, id int) (*dto.BookingSeriesResponse, error) {
+	args := m.Called(claims, id)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.BookingSeriesResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) UpdateBookingSeries(claims *utils.Claims, id int, req dto.BookingSeriesUpdate) (*dto.BookingSeriesResponse, error) {
+	args := m.Called(claims, id, req)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.BookingSeriesResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) CancelBookingSeries(claims *utils.Claims, id int, from string) (*dto.BookingSeriesResponse, error) {
+	args := m.Called(claims, id, from)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.BookingSeriesResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) GetBookingCalendar(claims *utils.Claims, id int) (string, error) {
+	args := m.Called(claims, id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockBookingUsecase) CreateCalendarFeed(claims *utils.Claims, req dto.CalendarFeedRequest) (*dto.CalendarFeedResponse, error) {
+	args := m.Called(claims, req)
+	if args.Get(0) != nil {
+		return args.Get(0).(*dto.CalendarFeedResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBookingUsecase) GetCalendarFeed(token string) (string, error) {
+	args := m.Called(token)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockBookingUsecase) DisplayLocation(claims *utils.Claims, serviceID int) *time.Location {
+	args := m.Called(claims, serviceID)
+	return args.Get(0).(*time.Location)
+}
+
+func (m *MockBookingUsecase) ExpirePendingBookings() []int {
+	args := m.Called()
+	return args.Get(0).([]int)
+}
+
+func (m *MockBookingUsecase) GetAuditLog(after int) []*dto.BookingHistoryResponse {
+	args := m.Called(after)
+	return args.Get(0).([]*dto.BookingHistoryResponse)
+}
+
+func (m *MockBookingUsecase) ImportBookings(claims *utils.Claims, rows []dto.BookingImportRow, options dto.BookingImportOptions) (*dto.BookingImportResponse, error) {
+	args := m.Called(claims, rows, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.BookingImportResponse), args.Error(1)
+}
+
+func (m *MockBookingUsecase) ExecuteBatch(claims *utils.Claims, req dto.BookingBatchRequest) (*dto.BookingBatchResponse, error) {
+	args := m.Called(claims, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.BookingBatchResponse), args.Error(1)
+}
+
+func (m *MockBookingUsecase) BookingReport(claims *utils.Claims, filter dto.ReportFilter, location *time.Location) (*dto.BookingReportResponse, error) {
+	args := m.Called(claims, filter, location)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.BookingReportResponse), args.Error(1)
+}
+
+func (m *MockBookingUsecase) RevenueReport(claims *utils.Claims, filter dto.ReportFilter, location *time.Location) (*dto.RevenueReportResponse, error) {
+	args := m.Called(claims, filter, location)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.RevenueReportResponse), args.Error(1)
+}
+
 func (m *MockBookingUsecase) CheckExpiredBookings() {
 	m.Called()
 }
@@ -42,8 +250,8 @@ func (m *MockBookingUsecase) BackgroundTaskBooking(wg *sync.WaitGroup) {
 	m.Called(wg)
 }
 
-func (m *MockBookingUsecase) GetAllBookings(sort string, highValue string) ([]*dto.BookingResponse, error) {
-	args := m.Called(sort, highValue)
+func (m *MockBookingUsecase) GetAllBookings(claims *utils.Claims, sort string, highValue string) ([]*dto.BookingResponse, error) {
+	args := m.Called(claims, sort, highValue)
 
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -52,8 +260,8 @@ func (m *MockBookingUsecase) GetAllBookings(sort string, highValue string) ([]*d
 	return args.Get(0).([]*dto.BookingResponse), args.Error(1)
 }
 
-func (m *MockBookingUsecase) UpdateBooking(id int, status string) error {
-	args := m.Called(id, status)
+func (m *MockBookingUsecase) UpdateBooking(claims *utils.Claims, id int, status string) error {
+	args := m.Called(claims, id, status)
 	return args.Error(0)
 }
 
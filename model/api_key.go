@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// api key scopes
+const (
+	ScopeBookingsRead  = "bookings:read"
+	ScopeBookingsWrite = "bookings:write"
+)
+
+type (
+	// ApiKey only keeps the hash of the key, the plain key is shown once on
+	// creation. Tenant is the business unit the key acts for, Role and UserID
+	// are who it acts as.
+	ApiKey struct {
+		ID          int
+		Tenant      string
+		Name        string
+		Prefix      string
+		Hash        string
+		Role        string
+		UserID      int
+		Scopes      []string
+		QuotaPerDay int
+		UsageDay    string
+		UsageCount  int
+		CreatedAt   time.Time
+		LastUsedAt  time.Time
+		RevokedAt   time.Time
+	}
+)
+
+// HasScope reports whether the key was granted the scope
+func (k *ApiKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRevoked reports whether the key can no longer be used
+func (k *ApiKey) IsRevoked() bool {
+	return !k.RevokedAt.IsZero()
+}
@@ -7,7 +7,7 @@ type (
 		ID        int
 		UserID    int
 		ServiceID int
-		Price     float64
+		Price     Money
 		Status    string
 		CreatedAt time.Time
 		UpdatedAt time.Time
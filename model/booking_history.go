@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// booking history actions
+const (
+	HistoryCreated       = "created"
+	HistoryStatusChanged = "status_changed"
+	HistoryCanceled      = "canceled"
+	HistoryExpired       = "expired"
+	HistoryRescheduled   = "rescheduled"
+	HistoryUpdated       = "updated"
+	// HistoryBalanceReminder is recorded when the customer is reminded of a
+	// balance that is due soon
+	HistoryBalanceReminder = "balance_reminder"
+)
+
+type (
+	// FieldChange is the value of a booking field before and after a change
+	FieldChange struct {
+		From string
+		To   string
+	}
+
+	// BookingHistory is one change made to a booking, ActorID is 0 for
+	// changes made by the system
+	BookingHistory struct {
+		ID        int
+		BookingID int
+		Action    string
+		Changes   map[string]FieldChange
+		Reason    string
+		ActorID   int
+		ActorRole string
+		CreatedAt time.Time
+	}
+)
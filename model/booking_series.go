@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// booking series statuses
+const (
+	SeriesActive   = "active"
+	SeriesCanceled = "canceled"
+)
+
+// BookingSeries is a recurring booking, every occurrence of RRule from
+// StartAt except the Exceptions is booked as a child booking
+type BookingSeries struct {
+	ID         int
+	UserID     int
+	ServiceID  int
+	RRule      string
+	StartAt    time.Time
+	Exceptions []time.Time
+	Duration   string
+	Notes      string
+	Status     string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,43 @@
+package models
+
+// booking statuses, high value bookings are deposit_paid between paying the
+// deposit and paying the balance
+const (
+	StatusPending     = "pending"
+	StatusDepositPaid = "deposit_paid"
+	StatusConfirmed   = "confirmed"
+	StatusRejected    = "rejected"
+	StatusCanceled    = "canceled"
+)
+
+// bookingTransitions is the booking state machine, rejected and canceled
+// bookings are final
+var bookingTransitions = map[string][]string{
+	StatusPending:     {StatusDepositPaid, StatusConfirmed, StatusRejected, StatusCanceled},
+	StatusDepositPaid: {StatusConfirmed, StatusCanceled},
+	StatusConfirmed:   {StatusCanceled},
+}
+
+// IsBookingStatus reports whether status is a known booking status
+func IsBookingStatus(status string) bool {
+	switch status {
+	case StatusPending, StatusDepositPaid, StatusConfirmed, StatusRejected, StatusCanceled:
+		return true
+	}
+	return false
+}
+
+// IsActiveStatus reports whether a booking in status still holds its slot
+func IsActiveStatus(status string) bool {
+	return status == StatusPending || status == StatusDepositPaid || status == StatusConfirmed
+}
+
+// CanTransition reports whether a booking can move from one status to another
+func CanTransition(from, to string) bool {
+	for _, next := range bookingTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout is the layout of calendar dates such as holidays
+const DateLayout = "2006-01-02"
+
+// where a date override came from
+const (
+	OverrideManual = "manual"
+	OverrideICS    = "ics"
+)
+
+type (
+	// OpeningHours is a part of a day a service is open, Open and Close are
+	// minutes after midnight in the time zone of the service and Close may
+	// be 24:00
+	OpeningHours struct {
+		Open  int
+		Close int
+	}
+
+	// BusinessHours are the weekly opening hours of a service, weekdays
+	// without hours are closed. Services without business hours are open
+	// all the time.
+	BusinessHours struct {
+		ServiceID int
+		Weekly    map[time.Weekday][]OpeningHours
+		UpdatedAt time.Time
+	}
+
+	// HoursOverride replaces the business hours of one date, such as a
+	// holiday or a maintenance day. Overrides without hours close the
+	// service for the day and ServiceID 0 applies to every service, an
+	// override of the service wins over one for every service.
+	HoursOverride struct {
+		ID        int
+		ServiceID int
+		Date      string
+		Hours     []OpeningHours
+		Reason    string
+		Source    string
+		CreatedAt time.Time
+	}
+)
+
+// IsClosed reports whether the override closes the service all day
+func (o *HoursOverride) IsClosed() bool {
+	return len(o.Hours) == 0
+}
+
+// FormatMinutes writes minutes after midnight as 15:04
+func FormatMinutes(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// String writes the hours as 09:00-18:00
+func (h OpeningHours) String() string {
+	return FormatMinutes(h.Open) + "-" + FormatMinutes(h.Close)
+}
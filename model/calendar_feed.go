@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// CalendarFeed lets calendar apps subscribe to the bookings of a user
+// without logging in. Only the hash of the token is kept, the plain token is
+// shown once on creation.
+type CalendarFeed struct {
+	UserID    int
+	TokenHash string
+	CreatedAt time.Time
+}
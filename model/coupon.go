@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// coupon types
+const (
+	CouponPercent = "percent"
+	CouponFixed   = "fixed"
+)
+
+type (
+	// Coupon is a promo code taking Percent or Amount off the price, zero
+	// limits and an empty validity bound mean no limit and ServiceIDs empty
+	// means every service
+	Coupon struct {
+		ID             int
+		Code           string
+		Type           string
+		Percent        float64
+		Amount         Money
+		ValidFrom      time.Time
+		ValidUntil     time.Time
+		MaxRedemptions int
+		MaxPerUser     int
+		ServiceIDs     []int
+		Disabled       bool
+		// Redeemed counts the redemptions that were not released
+		Redeemed  int
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	// CouponRedemption is one use of a coupon, released redemptions no
+	// longer count against the limits
+	CouponRedemption struct {
+		ID         int
+		CouponID   int
+		UserID     int
+		BookingID  int
+		RedeemedAt time.Time
+		ReleasedAt time.Time
+	}
+)
+
+// IsValidAt reports whether the coupon can be used at t
+func (c *Coupon) IsValidAt(t time.Time) bool {
+	if c.Disabled {
+		return false
+	}
+	if !c.ValidFrom.IsZero() && t.Before(c.ValidFrom) {
+		return false
+	}
+	return c.ValidUntil.IsZero() || t.Before(c.ValidUntil)
+}
+
+// AppliesTo reports whether the coupon can be used for the service, fixed
+// coupons only apply to prices in their currency
+func (c *Coupon) AppliesTo(serviceID int, price Money) bool {
+	if c.Type == CouponFixed && c.Amount.Currency != price.Currency {
+		return false
+	}
+	if len(c.ServiceIDs) == 0 {
+		return true
+	}
+	for _, id := range c.ServiceIDs {
+		if id == serviceID {
+			return true
+		}
+	}
+	return false
+}
+
+// Discount is the amount taken off price, never more than the price
+func (c *Coupon) Discount(price Money) Money {
+	discount := c.Amount
+	if c.Type == CouponPercent {
+		discount = price.Percent(c.Percent)
+	}
+	if discount.Cmp(price) > 0 {
+		return price
+	}
+	return discount
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// kinds of invoice documents
+const (
+	InvoiceKindInvoice    = "invoice"
+	InvoiceKindCreditNote = "credit_note"
+)
+
+type (
+	// InvoiceLine is one VAT inclusive amount of an invoice, Net plus Tax
+	// is Total
+	InvoiceLine struct {
+		Description string
+		Net         Money
+		Tax         Money
+		Total       Money
+	}
+
+	// Invoice is a tax invoice or a credit note of a booking. Number is
+	// given by the repository, credit notes refer to the invoice they
+	// correct with RefersTo.
+	Invoice struct {
+		ID        int
+		Number    string
+		Kind      string
+		BookingID int
+		UserID    int
+		ServiceID int
+		TaxRate   float64
+		Lines     []InvoiceLine
+		Net       Money
+		Tax       Money
+		Total     Money
+		RefersTo  string
+		IssuedAt  time.Time
+	}
+)
+
+// NewInvoiceLine splits a VAT inclusive total into net and tax, the tax is
+// rounded to the minor unit
+func NewInvoiceLine(description string, total Money, taxRate float64) InvoiceLine {
+	tax := Money{Amount: int64(math.Round(float64(total.Amount) * taxRate / (100 + taxRate))), Currency: total.Currency}
+	return InvoiceLine{
+		Description: description,
+		Net:         total.Sub(tax),
+		Tax:         tax,
+		Total:       total,
+	}
+}
+
+// AddLine adds the line to the invoice totals
+func (i *Invoice) AddLine(line InvoiceLine) {
+	i.Lines = append(i.Lines, line)
+	i.Net = i.Net.Add(line.Net)
+	i.Tax = i.Tax.Add(line.Tax)
+	i.Total = i.Total.Add(line.Total)
+}
@@ -0,0 +1,174 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// currencyExponents are the ISO 4217 currencies we accept and the number of
+// digits of their minor unit
+var currencyExponents = map[string]int{
+	"AUD": 2, "BHD": 3, "CAD": 2, "CHF": 2, "CNY": 2, "DKK": 2, "EUR": 2,
+	"GBP": 2, "HKD": 2, "IDR": 2, "INR": 2, "JPY": 0, "KHR": 2, "KRW": 0,
+	"KWD": 3, "LAK": 2, "MMK": 2, "MYR": 2, "NOK": 2, "NZD": 2, "PHP": 2,
+	"SEK": 2, "SGD": 2, "THB": 2, "TWD": 2, "USD": 2, "VND": 0,
+}
+
+// IsCurrency reports whether code is a supported ISO 4217 currency code
+func IsCurrency(code string) bool {
+	_, exists := currencyExponents[code]
+	return exists
+}
+
+// Money is an amount in the minor unit of its currency, 1050 THB is 10.50
+// baht. Amounts of different currencies are never added together.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// NewMoney makes an amount of minor units
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney parses a decimal amount such as "10.50" in the currency, more
+// decimals than the currency has are rejected instead of rounded
+func ParseMoney(value, currency string) (Money, error) {
+	exponent, exists := currencyExponents[currency]
+	if !exists {
+		return Money{}, fmt.Errorf("unknown currency %q", currency)
+	}
+
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	whole, fraction, _ := strings.Cut(strings.TrimPrefix(value, "-"), ".")
+	if whole == "" || len(fraction) > exponent || strings.HasPrefix(whole, "+") {
+		return Money{}, fmt.Errorf("invalid amount %q for %s", value, currency)
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil || amount < 0 {
+		return Money{}, fmt.Errorf("invalid amount %q for %s", value, currency)
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Decimal formats the amount without the currency, e.g. "10.50"
+func (m Money) Decimal() string {
+	exponent := currencyExponents[m.Currency]
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	if exponent == 0 {
+		return sign + strconv.FormatInt(amount, 10)
+	}
+	unit := int64(math.Pow10(exponent))
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/unit, exponent, amount%unit)
+}
+
+// String formats the amount with its currency, e.g. "10.50 THB"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// IsZero reports whether the amount is zero, it lets omitzero leave out
+// empty amounts
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Add panics when the currencies differ, callers check them first
+func (m Money) Add(other Money) Money {
+	m.mustMatch(other)
+	return Money{Amount: m.Amount + other.Amount, Currency: m.currency(other)}
+}
+
+// Sub panics when the currencies differ, callers check them first
+func (m Money) Sub(other Money) Money {
+	m.mustMatch(other)
+	return Money{Amount: m.Amount - other.Amount, Currency: m.currency(other)}
+}
+
+// Mul multiplies the amount by n
+func (m Money) Mul(n int64) Money {
+	return Money{Amount: m.Amount * n, Currency: m.Currency}
+}
+
+// Percent is percent of the amount rounded half away from zero to the
+// minor unit
+func (m Money) Percent(percent float64) Money {
+	return Money{Amount: int64(math.Round(float64(m.Amount) * percent / 100)), Currency: m.Currency}
+}
+
+// SameCurrency reports whether both amounts can be added, a zero amount
+// without a currency matches every currency
+func (m Money) SameCurrency(other Money) bool {
+	return m.Currency == other.Currency || (m.Currency == "" && m.Amount == 0) || (other.Currency == "" && other.Amount == 0)
+}
+
+// Cmp compares amounts of the same currency, -1 when m is smaller
+func (m Money) Cmp(other Money) int {
+	m.mustMatch(other)
+	switch {
+	case m.Amount < other.Amount:
+		return -1
+	case m.Amount > other.Amount:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) mustMatch(other Money) {
+	if !m.SameCurrency(other) {
+		panic(fmt.Sprintf("money: %s and %s have different currencies", m, other))
+	}
+}
+
+func (m Money) currency(other Money) string {
+	if m.Currency != "" {
+		return m.Currency
+	}
+	return other.Currency
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON writes the amount as a decimal string so clients do not lose
+// precision, e.g. {"amount":"10.50","currency":"THB"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+// UnmarshalJSON only accepts amounts written as strings
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Amount   json.RawMessage `json:"amount"`
+		Currency string          `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("money must be an object with amount and currency")
+	}
+	var amount string
+	if err := json.Unmarshal(raw.Amount, &amount); err != nil {
+		return fmt.Errorf("money amount must be a string such as \"10.50\"")
+	}
+	parsed, err := ParseMoney(amount, strings.ToUpper(raw.Currency))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// payment statuses, a payment is authorized, then captured or voided, and
+// captured payments can be refunded
+const (
+	PaymentAuthorized     = "authorized"
+	PaymentCapturePending = "capture_pending"
+	PaymentCaptured       = "captured"
+	PaymentVoided         = "voided"
+	PaymentRefunded       = "refunded"
+	PaymentDeclined       = "declined"
+	PaymentFailed         = "failed"
+)
+
+// payment kinds, high value bookings are paid as a deposit and a balance and
+// other bookings in full
+const (
+	PaymentKindFull    = "full"
+	PaymentKindDeposit = "deposit"
+	PaymentKindBalance = "balance"
+)
+
+// ledger entry kinds
+const (
+	LedgerCharge = "charge"
+	LedgerRefund = "refund"
+)
+
+// Payment is the money taken for a booking through a payment provider,
+// AuthorizationLatency is how long the credit check of the provider took
+type Payment struct {
+	ID                   int
+	BookingID            int
+	Kind                 string
+	Provider             string
+	Reference            string
+	Amount               Money
+	Refunded             Money
+	Status               string
+	Message              string
+	AuthorizationLatency time.Duration
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+// IsOpen reports whether money is held or taken by the payment
+func (p *Payment) IsOpen() bool {
+	return p.Status == PaymentAuthorized || p.Status == PaymentCapturePending || p.Status == PaymentCaptured
+}
+
+// LedgerEntry is money that moved for a booking, a captured payment is a
+// charge and every refund of it is a refund
+type LedgerEntry struct {
+	ID          int
+	BookingID   int
+	PaymentID   int
+	PaymentKind string
+	Kind        string
+	Reference   string
+	Amount      Money
+	CreatedAt   time.Time
+}
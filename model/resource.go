@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// resource types
+const (
+	ResourceStaff     = "staff"
+	ResourceRoom      = "room"
+	ResourceEquipment = "equipment"
+)
+
+type (
+	// Resource fulfils bookings, such as a technician, a room or equipment.
+	// Disabled resources keep their bookings but are not assigned new ones.
+	Resource struct {
+		ID        int
+		Name      string
+		Type      string
+		Skills    []string
+		Disabled  bool
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+
+	// ResourceRequirement is the number of resources of a type, with every
+	// one of the skills, each booking of a service needs
+	ResourceRequirement struct {
+		Type     string
+		Skills   []string
+		Quantity int
+	}
+)
+
+// IsResourceType reports whether t is a known resource type
+func IsResourceType(t string) bool {
+	return t == ResourceStaff || t == ResourceRoom || t == ResourceEquipment
+}
+
+// Meets reports whether the resource can fill the requirement
+func (r *Resource) Meets(requirement ResourceRequirement) bool {
+	if r.Disabled || r.Type != requirement.Type {
+		return false
+	}
+	for _, skill := range requirement.Skills {
+		if !r.HasSkill(skill) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasSkill
+func (r *Resource) HasSkill(skill string) bool {
+	for _, s := range r.Skills {
+		if s == skill {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type (
+	// Service is something that can be booked, Capacity is the number of
+	// bookings that can overlap in the same slot
+	Service struct {
+		ID        int
+		Name      string
+		BasePrice Money
+		Capacity  int
+		Duration  time.Duration
+	}
+)
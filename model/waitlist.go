@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// waitlist statuses, a waiting entry is offered a freed slot and the offer
+// is accepted or expires
+const (
+	WaitlistWaiting  = "waiting"
+	WaitlistOffered  = "offered"
+	WaitlistAccepted = "accepted"
+	WaitlistExpired  = "expired"
+	WaitlistLeft     = "left"
+)
+
+// WaitlistEntry is a user waiting for a slot of a full service, the slot
+// lasts the duration of the service
+type WaitlistEntry struct {
+	ID             int
+	UserID         int
+	ServiceID      int
+	StartAt        time.Time
+	EndAt          time.Time
+	Status         string
+	OfferExpiresAt time.Time
+	BookingID      int
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// IsOpen reports whether the entry is still waiting or has an offer
+func (w *WaitlistEntry) IsOpen() bool {
+	return w.Status == WaitlistWaiting || w.Status == WaitlistOffered
+}
+
+// HoldsSlot reports whether the entry has an offer that holds a place in its
+// slot at now
+func (w *WaitlistEntry) HoldsSlot(now time.Time) bool {
+	return w.Status == WaitlistOffered && now.Before(w.OfferExpiresAt)
+}
+
+// Overlaps reports whether the slot of the entry overlaps [start, end)
+func (w *WaitlistEntry) Overlaps(start, end time.Time) bool {
+	return w.StartAt.Before(end) && start.Before(w.EndAt)
+}
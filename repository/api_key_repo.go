@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+type (
+	ApiKeyRepository interface {
+		Create(key models.ApiKey) *models.ApiKey
+		GetByHash(hash string) (*models.ApiKey, bool)
+		GetAll(tenant string) []*models.ApiKey
+		Revoke(tenant string, id int) error
+		RecordUsage(id int, now time.Time) (*models.ApiKey, bool)
+	}
+
+	MockApiKeyRepository struct {
+		keys   map[int]models.ApiKey
+		hashes map[string]int
+		mu     sync.RWMutex
+	}
+)
+
+func NewMockApiKeyRepository() ApiKeyRepository {
+	return &MockApiKeyRepository{
+		keys:   make(map[int]models.ApiKey),
+		hashes: make(map[string]int),
+	}
+}
+
+// Create
+func (m *MockApiKeyRepository) Create(key models.ApiKey) *models.ApiKey {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key.ID = len(m.keys) + 1
+	key.CreatedAt = now()
+	m.keys[key.ID] = key
+	m.hashes[key.Hash] = key.ID
+	return &key
+}
+
+// GetByHash
+func (m *MockApiKeyRepository) GetByHash(hash string) (*models.ApiKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	id, exists := m.hashes[hash]
+	if !exists {
+		return nil, false
+	}
+	key := m.keys[id]
+	return &key, true
+}
+
+// GetAll returns the keys of the tenant
+func (m *MockApiKeyRepository) GetAll(tenant string) []*models.ApiKey {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := []*models.ApiKey{}
+	for _, k := range m.keys {
+		if k.Tenant != tenant {
+			continue
+		}
+		keyCopy := k
+		keys = append(keys, &keyCopy)
+	}
+	return keys
+}
+
+// Revoke a key of the tenant
+func (m *MockApiKeyRepository) Revoke(tenant string, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key, exists := m.keys[id]
+	if !exists || key.Tenant != tenant {
+		return fmt.Errorf("api key not found")
+	}
+	if key.RevokedAt.IsZero() {
+		key.RevokedAt = now()
+		m.keys[id] = key
+	}
+	return nil
+}
+
+// RecordUsage counts a request against the daily quota of the key, it
+// returns false without counting when the quota is used up
+func (m *MockApiKeyRepository) RecordUsage(id int, now time.Time) (*models.ApiKey, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key, exists := m.keys[id]
+	if !exists {
+		return nil, false
+	}
+	day := now.UTC().Format("2006-01-02")
+	if key.UsageDay != day {
+		key.UsageDay = day
+		key.UsageCount = 0
+	}
+	if key.QuotaPerDay > 0 && key.UsageCount >= key.QuotaPerDay {
+		return &key, false
+	}
+	key.UsageCount++
+	key.LastUsedAt = now
+	m.keys[id] = key
+	return &key, true
+}
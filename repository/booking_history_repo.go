@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"sync"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+type (
+	BookingHistoryRepository interface {
+		Add(entry models.BookingHistory) *models.BookingHistory
+		GetByBookingID(bookingID int) []*models.BookingHistory
+		GetAfter(id int) []*models.BookingHistory
+	}
+
+	MockBookingHistoryRepository struct {
+		entries []models.BookingHistory
+		mu      sync.RWMutex
+	}
+)
+
+func NewMockBookingHistoryRepository() BookingHistoryRepository {
+	return &MockBookingHistoryRepository{}
+}
+
+// Add appends an entry, entries are never changed afterwards
+func (m *MockBookingHistoryRepository) Add(entry models.BookingHistory) *models.BookingHistory {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry.ID = len(m.entries) + 1
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = now()
+	}
+	m.entries = append(m.entries, entry)
+	return &entry
+}
+
+// GetByBookingID returns the history of a booking, oldest first
+func (m *MockBookingHistoryRepository) GetByBookingID(bookingID int) []*models.BookingHistory {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := []*models.BookingHistory{}
+	for _, e := range m.entries {
+		if e.BookingID == bookingID {
+			entryCopy := e
+			entries = append(entries, &entryCopy)
+		}
+	}
+	return entries
+}
+
+// GetAfter returns the entries of every booking added after the entry with
+// the id, oldest first
+func (m *MockBookingHistoryRepository) GetAfter(id int) []*models.BookingHistory {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := []*models.BookingHistory{}
+	for _, e := range m.entries {
+		if e.ID > id {
+			entryCopy := e
+			entries = append(entries, &entryCopy)
+		}
+	}
+	return entries
+}
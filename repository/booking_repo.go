@@ -6,15 +6,20 @@ import (
 	"time"
 
 	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
 )
 
 type (
 	BookingRepository interface{
-		Create(req dto.BookingRequest) *dto.BookingResponse
+		Create(booking dto.BookingResponse) *dto.BookingResponse
 		GetByID(id int) (*dto.BookingResponse, bool)
 		GetAll() []*dto.BookingResponse
-		GetHighValueBookings(threshold float64) []*dto.BookingResponse
+		GetByUserID(userID int) []*dto.BookingResponse
+		GetByServiceID(serviceID int) []*dto.BookingResponse
+		UpdateBooking(booking *dto.BookingResponse) error
+		GetHighValueBookings(thresholds map[string]models.Money) []*dto.BookingResponse
 		UpdateBookingStatus(id int, status string) error
+		Delete(id int) error
 	}
 
 	MockBookingRepository struct {
@@ -26,16 +31,15 @@ type (
 
 func NewMockBookingRepository() BookingRepository {
 	booking := make(map[int]dto.BookingResponse)
-	loc, _ := time.LoadLocation("Asia/Bangkok")
 	for i := 1; i <= 10; i++ {
 		booking[i] = dto.BookingResponse{
 			ID: i,
 			UserID: i,
 			ServiceID: i,
-			Price: float64(i * 1000),
+			Price: models.NewMoney(int64(i*1000)*100, "THB"),
 			Status: "pending",
-			CreatedAt: time.Now().Add(-time.Duration(i) * time.Minute).In(loc).Format(time.RFC3339),
-			UpdatedAt: time.Now().In(loc).Format(time.RFC3339),
+			CreatedAt: now().Add(-time.Duration(i) * time.Minute),
+			UpdatedAt: now(),
 		}
 	}
 	return &MockBookingRepository{
@@ -44,22 +48,23 @@ func NewMockBookingRepository() BookingRepository {
 	}
 }
 
-// Create
-func (m *MockBookingRepository) Create(req dto.BookingRequest) *dto.BookingResponse {
+// Create stores a new booking, the ID and timestamps are set here
+func (m *MockBookingRepository) Create(booking dto.BookingResponse) *dto.BookingResponse {
     m.mu.Lock()
     defer m.mu.Unlock()
-    id := len(m.bookings) + 1
-    booking := &dto.BookingResponse{
-        ID:        id,
-        UserID:    req.UserID,
-        ServiceID: req.ServiceID,
-        Price:     req.Price,
-        Status:    "pending",
-        CreatedAt: time.Now().Format(time.RFC3339),
-        UpdatedAt: time.Now().Format(time.RFC3339),
+    booking.ID = len(m.bookings) + 1
+    if booking.Status == "" {
+        booking.Status = "pending"
     }
-    m.bookings[id] = *booking
-    return booking
+    // migrated bookings keep their times
+    if booking.CreatedAt.IsZero() {
+        booking.CreatedAt = now()
+    }
+    if booking.UpdatedAt.IsZero() {
+        booking.UpdatedAt = booking.CreatedAt
+    }
+    m.bookings[booking.ID] = booking
+    return &booking
 }
 
 // GetByID
@@ -82,6 +87,58 @@ func (m *MockBookingRepository) GetAll() []*dto.BookingResponse {
 	return bookings
 }
 
+// GetByUserID
+func (m *MockBookingRepository) GetByUserID(userID int) []*dto.BookingResponse {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	bookings := []*dto.BookingResponse{}
+	for _, b := range m.bookings {
+		if b.UserID == userID {
+			bookingCopy := b
+			bookings = append(bookings, &bookingCopy)
+		}
+	}
+	return bookings
+}
+
+// GetByServiceID
+func (m *MockBookingRepository) GetByServiceID(serviceID int) []*dto.BookingResponse {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	bookings := []*dto.BookingResponse{}
+	for _, b := range m.bookings {
+		if b.ServiceID == serviceID {
+			bookingCopy := b
+			bookings = append(bookings, &bookingCopy)
+		}
+	}
+	return bookings
+}
+
+// UpdateBooking replaces the stored booking
+func (m *MockBookingRepository) UpdateBooking(booking *dto.BookingResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.bookings[booking.ID]; !exists {
+		return fmt.Errorf("booking not found")
+	}
+	booking.UpdatedAt = now()
+	m.bookings[booking.ID] = *booking
+	return nil
+}
+
+// Delete removes a booking, it is only used to roll back imports before
+// they are committed
+func (m *MockBookingRepository) Delete(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.bookings[id]; !exists {
+		return fmt.Errorf("booking not found")
+	}
+	delete(m.bookings, id)
+	return nil
+}
+
 // Update status booking
 func (m *MockBookingRepository) Update(id int, status string) bool {
 	m.mu.Lock()
@@ -91,19 +148,21 @@ func (m *MockBookingRepository) Update(id int, status string) bool {
 		return false
 	}
 	booking.Status = status
-	booking.UpdatedAt = time.Now().Format(time.RFC3339)
+	booking.UpdatedAt = now()
 	m.bookings[id] = booking
 	return true
 }
 
-// GetHighValueBookings get high value bookings
-func (m *MockBookingRepository) GetHighValueBookings(threshold float64) []*dto.BookingResponse {
+// GetHighValueBookings get bookings above the threshold of their currency
+func (m *MockBookingRepository) GetHighValueBookings(thresholds map[string]models.Money) []*dto.BookingResponse {
     m.mu.RLock()
     defer m.mu.RUnlock()
     var highValueBookings []*dto.BookingResponse
     for _, booking := range m.bookings {
-        if booking.Price > threshold {
-            highValueBookings = append(highValueBookings, &booking)
+        threshold, exists := thresholds[booking.Price.Currency]
+        if exists && booking.Price.Cmp(threshold) > 0 {
+            bookingCopy := booking
+            highValueBookings = append(highValueBookings, &bookingCopy)
         }
     }
     return highValueBookings
@@ -118,9 +177,8 @@ func (m *MockBookingRepository) UpdateBookingStatus(id int, status string) error
         return fmt.Errorf("booking not found")
     }
     booking.Status = status
-    if status == "canceled" {
-        // เก็บข้อมูลไว้ใน Repository แต่เปลี่ยนสถานะ
-        m.bookings[id] = booking
-    }
+    booking.UpdatedAt = now()
+    // เก็บข้อมูลไว้ใน Repository แต่เปลี่ยนสถานะ
+    m.bookings[id] = booking
     return nil
 }
\ No newline at end of file
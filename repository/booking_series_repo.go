@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+var ErrBookingSeriesNotFound = errors.New("booking series not found")
+
+type (
+	BookingSeriesRepository interface {
+		Create(series models.BookingSeries) *models.BookingSeries
+		Update(series *models.BookingSeries) error
+		GetByID(id int) (*models.BookingSeries, bool)
+	}
+
+	MockBookingSeriesRepository struct {
+		series map[int]models.BookingSeries
+		mu     sync.RWMutex
+	}
+)
+
+func NewMockBookingSeriesRepository() BookingSeriesRepository {
+	return &MockBookingSeriesRepository{series: make(map[int]models.BookingSeries)}
+}
+
+// Create
+func (m *MockBookingSeriesRepository) Create(series models.BookingSeries) *models.BookingSeries {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	series.ID = len(m.series) + 1
+	series.CreatedAt = now()
+	series.UpdatedAt = series.CreatedAt
+	m.series[series.ID] = series
+	return &series
+}
+
+// Update
+func (m *MockBookingSeriesRepository) Update(series *models.BookingSeries) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.series[series.ID]; !exists {
+		return ErrBookingSeriesNotFound
+	}
+	series.UpdatedAt = now()
+	m.series[series.ID] = *series
+	return nil
+}
+
+// GetByID
+func (m *MockBookingSeriesRepository) GetByID(id int) (*models.BookingSeries, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	series, exists := m.series[id]
+	return &series, exists
+}
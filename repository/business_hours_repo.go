@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+type (
+	BusinessHoursRepository interface {
+		GetHours(serviceID int) (*models.BusinessHours, bool)
+		SaveHours(hours models.BusinessHours) *models.BusinessHours
+		DeleteHours(serviceID int) bool
+		SaveOverride(override models.HoursOverride) *models.HoursOverride
+		GetOverride(serviceID int, date string) (*models.HoursOverride, bool)
+		GetOverrides(serviceID int) []*models.HoursOverride
+		DeleteOverride(id int) bool
+	}
+
+	MockBusinessHoursRepository struct {
+		hours     map[int]models.BusinessHours
+		overrides map[int]models.HoursOverride
+		nextID    int
+		mu        sync.RWMutex
+	}
+)
+
+func NewMockBusinessHoursRepository() BusinessHoursRepository {
+	return &MockBusinessHoursRepository{
+		hours:     make(map[int]models.BusinessHours),
+		overrides: make(map[int]models.HoursOverride),
+	}
+}
+
+// GetHours
+func (m *MockBusinessHoursRepository) GetHours(serviceID int) (*models.BusinessHours, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	hours, exists := m.hours[serviceID]
+	if !exists {
+		return nil, false
+	}
+	return &hours, true
+}
+
+// SaveHours replaces the weekly hours of the service
+func (m *MockBusinessHoursRepository) SaveHours(hours models.BusinessHours) *models.BusinessHours {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	hours.UpdatedAt = now()
+	m.hours[hours.ServiceID] = hours
+	return &hours
+}
+
+// DeleteHours opens the service all the time again
+func (m *MockBusinessHoursRepository) DeleteHours(serviceID int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.hours[serviceID]; !exists {
+		return false
+	}
+	delete(m.hours, serviceID)
+	return true
+}
+
+// SaveOverride replaces the override of the same service and date, a new
+// one gets an ID
+func (m *MockBusinessHoursRepository) SaveOverride(override models.HoursOverride) *models.HoursOverride {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, existing := range m.overrides {
+		if existing.ServiceID == override.ServiceID && existing.Date == override.Date {
+			delete(m.overrides, id)
+		}
+	}
+	m.nextID++
+	override.ID = m.nextID
+	override.CreatedAt = now()
+	m.overrides[override.ID] = override
+	return &override
+}
+
+// GetOverride returns the override of the service on the date
+func (m *MockBusinessHoursRepository) GetOverride(serviceID int, date string) (*models.HoursOverride, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, override := range m.overrides {
+		if override.ServiceID == serviceID && override.Date == date {
+			overrideCopy := override
+			return &overrideCopy, true
+		}
+	}
+	return nil, false
+}
+
+// GetOverrides returns the overrides of the service by date, the ones for
+// every service are included
+func (m *MockBusinessHoursRepository) GetOverrides(serviceID int) []*models.HoursOverride {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	overrides := []*models.HoursOverride{}
+	for _, override := range m.overrides {
+		if override.ServiceID == serviceID || override.ServiceID == 0 {
+			overrideCopy := override
+			overrides = append(overrides, &overrideCopy)
+		}
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		if overrides[i].Date != overrides[j].Date {
+			return overrides[i].Date < overrides[j].Date
+		}
+		return overrides[i].ServiceID < overrides[j].ServiceID
+	})
+	return overrides
+}
+
+// DeleteOverride
+func (m *MockBusinessHoursRepository) DeleteOverride(id int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.overrides[id]; !exists {
+		return false
+	}
+	delete(m.overrides, id)
+	return true
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"sync"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+type (
+	CalendarFeedRepository interface {
+		Save(feed models.CalendarFeed) *models.CalendarFeed
+		GetByTokenHash(hash string) (*models.CalendarFeed, bool)
+	}
+
+	MockCalendarFeedRepository struct {
+		// feeds are keyed by user, a user has one feed
+		feeds map[int]models.CalendarFeed
+		mu    sync.RWMutex
+	}
+)
+
+func NewMockCalendarFeedRepository() CalendarFeedRepository {
+	return &MockCalendarFeedRepository{feeds: make(map[int]models.CalendarFeed)}
+}
+
+// Save replaces the feed of the user, the old token stops working
+func (m *MockCalendarFeedRepository) Save(feed models.CalendarFeed) *models.CalendarFeed {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	feed.CreatedAt = now()
+	m.feeds[feed.UserID] = feed
+	return &feed
+}
+
+// GetByTokenHash
+func (m *MockCalendarFeedRepository) GetByTokenHash(hash string) (*models.CalendarFeed, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, feed := range m.feeds {
+		if feed.TokenHash == hash {
+			feedCopy := feed
+			return &feedCopy, true
+		}
+	}
+	return nil, false
+}
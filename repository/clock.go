@@ -0,0 +1,9 @@
+package repository
+
+import "time"
+
+// now is the time the repositories stamp records with, times are persisted
+// in UTC to the second
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+// errors returned by the coupon repository
+var (
+	ErrCouponNotFound  = errors.New("coupon not found")
+	ErrCouponCodeTaken = errors.New("coupon code already exists")
+	ErrCouponNotValid  = errors.New("coupon is not valid")
+	ErrCouponUsedUp    = errors.New("coupon has no redemptions left")
+)
+
+type (
+	CouponRepository interface {
+		Create(coupon models.Coupon) (*models.Coupon, error)
+		GetByID(id int) (*models.Coupon, bool)
+		GetByCode(code string) (*models.Coupon, bool)
+		GetAll() []*models.Coupon
+		Update(coupon *models.Coupon) error
+		Delete(id int) error
+		Redeem(code string, userID, serviceID int, price models.Money, now time.Time) (*models.CouponRedemption, error)
+		AttachBooking(redemptionID, bookingID int) error
+		Release(bookingID int, now time.Time) bool
+	}
+
+	MockCouponRepository struct {
+		coupons     map[int]models.Coupon
+		codes       map[string]int
+		redemptions map[int]models.CouponRedemption
+		nextID      int
+		mu          sync.RWMutex
+	}
+)
+
+func NewMockCouponRepository() CouponRepository {
+	return &MockCouponRepository{
+		coupons:     make(map[int]models.Coupon),
+		codes:       make(map[string]int),
+		redemptions: make(map[int]models.CouponRedemption),
+	}
+}
+
+// Create
+func (m *MockCouponRepository) Create(coupon models.Coupon) (*models.Coupon, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.codes[coupon.Code]; exists {
+		return nil, ErrCouponCodeTaken
+	}
+	m.nextID++
+	coupon.ID = m.nextID
+	coupon.Redeemed = 0
+	coupon.CreatedAt = now()
+	coupon.UpdatedAt = coupon.CreatedAt
+	m.coupons[coupon.ID] = coupon
+	m.codes[coupon.Code] = coupon.ID
+	return &coupon, nil
+}
+
+// GetByID
+func (m *MockCouponRepository) GetByID(id int) (*models.Coupon, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	coupon, exists := m.coupons[id]
+	if !exists {
+		return nil, false
+	}
+	return &coupon, true
+}
+
+// GetByCode
+func (m *MockCouponRepository) GetByCode(code string) (*models.Coupon, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	id, exists := m.codes[code]
+	if !exists {
+		return nil, false
+	}
+	coupon := m.coupons[id]
+	return &coupon, true
+}
+
+// GetAll
+func (m *MockCouponRepository) GetAll() []*models.Coupon {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	coupons := []*models.Coupon{}
+	for _, c := range m.coupons {
+		couponCopy := c
+		coupons = append(coupons, &couponCopy)
+	}
+	return coupons
+}
+
+// Update replaces the settings of a coupon, the redemption count is kept
+func (m *MockCouponRepository) Update(coupon *models.Coupon) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, exists := m.coupons[coupon.ID]
+	if !exists {
+		return ErrCouponNotFound
+	}
+	if id, taken := m.codes[coupon.Code]; taken && id != coupon.ID {
+		return ErrCouponCodeTaken
+	}
+	delete(m.codes, current.Code)
+	coupon.Redeemed = current.Redeemed
+	coupon.CreatedAt = current.CreatedAt
+	coupon.UpdatedAt = now()
+	m.coupons[coupon.ID] = *coupon
+	m.codes[coupon.Code] = coupon.ID
+	return nil
+}
+
+// Delete removes the coupon, bookings keep the discount they were given
+func (m *MockCouponRepository) Delete(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	coupon, exists := m.coupons[id]
+	if !exists {
+		return ErrCouponNotFound
+	}
+	delete(m.coupons, id)
+	delete(m.codes, coupon.Code)
+	return nil
+}
+
+// Redeem checks the coupon and records a redemption in one step, so two
+// bookings cannot both take the last redemption
+func (m *MockCouponRepository) Redeem(code string, userID, serviceID int, price models.Money, now time.Time) (*models.CouponRedemption, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id, exists := m.codes[code]
+	if !exists {
+		return nil, ErrCouponNotFound
+	}
+	coupon := m.coupons[id]
+	if !coupon.IsValidAt(now) || !coupon.AppliesTo(serviceID, price) {
+		return nil, ErrCouponNotValid
+	}
+	if coupon.MaxRedemptions > 0 && coupon.Redeemed >= coupon.MaxRedemptions {
+		return nil, ErrCouponUsedUp
+	}
+	if coupon.MaxPerUser > 0 {
+		used := 0
+		for _, r := range m.redemptions {
+			if r.CouponID == id && r.UserID == userID && r.ReleasedAt.IsZero() {
+				used++
+			}
+		}
+		if used >= coupon.MaxPerUser {
+			return nil, ErrCouponUsedUp
+		}
+	}
+
+	redemption := models.CouponRedemption{
+		ID:         len(m.redemptions) + 1,
+		CouponID:   id,
+		UserID:     userID,
+		RedeemedAt: now,
+	}
+	m.redemptions[redemption.ID] = redemption
+	coupon.Redeemed++
+	m.coupons[id] = coupon
+	return &redemption, nil
+}
+
+// AttachBooking links a redemption to the booking it was made for
+func (m *MockCouponRepository) AttachBooking(redemptionID, bookingID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	redemption, exists := m.redemptions[redemptionID]
+	if !exists {
+		return ErrCouponNotFound
+	}
+	redemption.BookingID = bookingID
+	m.redemptions[redemptionID] = redemption
+	return nil
+}
+
+// Release gives back the redemption of a booking, it returns false when the
+// booking has no redemption left to release
+func (m *MockCouponRepository) Release(bookingID int, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, redemption := range m.redemptions {
+		if redemption.BookingID != bookingID || !redemption.ReleasedAt.IsZero() {
+			continue
+		}
+		redemption.ReleasedAt = now
+		m.redemptions[id] = redemption
+		if coupon, exists := m.coupons[redemption.CouponID]; exists && coupon.Redeemed > 0 {
+			coupon.Redeemed--
+			m.coupons[coupon.ID] = coupon
+		}
+		return true
+	}
+	return false
+}
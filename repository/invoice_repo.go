@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+// invoiceNumberPrefixes start the number of each kind of document
+var invoiceNumberPrefixes = map[string]string{
+	models.InvoiceKindInvoice:    "INV",
+	models.InvoiceKindCreditNote: "CN",
+}
+
+type (
+	InvoiceRepository interface {
+		Issue(invoice models.Invoice) *models.Invoice
+		GetByBookingID(bookingID int) []*models.Invoice
+	}
+
+	MockInvoiceRepository struct {
+		invoices []models.Invoice
+		// sequences is the last number issued per kind of document
+		sequences map[string]int
+		mu        sync.RWMutex
+	}
+)
+
+func NewMockInvoiceRepository() InvoiceRepository {
+	return &MockInvoiceRepository{sequences: make(map[string]int)}
+}
+
+// Issue numbers and stores the invoice in one step, numbers are sequential
+// per kind and never skipped because issued documents are never removed
+func (m *MockInvoiceRepository) Issue(invoice models.Invoice) *models.Invoice {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sequences[invoice.Kind]++
+	invoice.ID = len(m.invoices) + 1
+	invoice.Number = fmt.Sprintf("%s-%06d", invoiceNumberPrefixes[invoice.Kind], m.sequences[invoice.Kind])
+	if invoice.IssuedAt.IsZero() {
+		invoice.IssuedAt = now()
+	}
+	m.invoices = append(m.invoices, invoice)
+	return &invoice
+}
+
+// GetByBookingID returns the documents of a booking, oldest first
+func (m *MockInvoiceRepository) GetByBookingID(bookingID int) []*models.Invoice {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	invoices := []*models.Invoice{}
+	for _, i := range m.invoices {
+		if i.BookingID == bookingID {
+			invoiceCopy := i
+			invoices = append(invoices, &invoiceCopy)
+		}
+	}
+	return invoices
+}
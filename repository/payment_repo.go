@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+var ErrPaymentNotFound = errors.New("payment not found")
+
+type (
+	PaymentRepository interface {
+		Create(payment models.Payment) *models.Payment
+		Update(payment *models.Payment) error
+		GetByReference(reference string) (*models.Payment, bool)
+		GetByBookingID(bookingID int) []*models.Payment
+		GetAll() []*models.Payment
+		AddLedgerEntry(entry models.LedgerEntry) *models.LedgerEntry
+		GetLedger(bookingID int) []*models.LedgerEntry
+	}
+
+	MockPaymentRepository struct {
+		payments map[int]models.Payment
+		ledger   []models.LedgerEntry
+		mu       sync.RWMutex
+	}
+)
+
+func NewMockPaymentRepository() PaymentRepository {
+	return &MockPaymentRepository{payments: make(map[int]models.Payment)}
+}
+
+// Create
+func (m *MockPaymentRepository) Create(payment models.Payment) *models.Payment {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	payment.ID = len(m.payments) + 1
+	payment.CreatedAt = now()
+	payment.UpdatedAt = payment.CreatedAt
+	m.payments[payment.ID] = payment
+	return &payment
+}
+
+// Update
+func (m *MockPaymentRepository) Update(payment *models.Payment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.payments[payment.ID]; !exists {
+		return ErrPaymentNotFound
+	}
+	payment.UpdatedAt = now()
+	m.payments[payment.ID] = *payment
+	return nil
+}
+
+// GetByReference finds a payment by the reference of its provider
+func (m *MockPaymentRepository) GetByReference(reference string) (*models.Payment, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, p := range m.payments {
+		if p.Reference == reference {
+			return &p, true
+		}
+	}
+	return nil, false
+}
+
+// GetByBookingID returns the payments of a booking, oldest first
+func (m *MockPaymentRepository) GetByBookingID(bookingID int) []*models.Payment {
+	payments := []*models.Payment{}
+	for _, p := range m.GetAll() {
+		if p.BookingID == bookingID {
+			payments = append(payments, p)
+		}
+	}
+	return payments
+}
+
+// GetAll returns every payment, oldest first
+func (m *MockPaymentRepository) GetAll() []*models.Payment {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	payments := []*models.Payment{}
+	for id := 1; id <= len(m.payments); id++ {
+		paymentCopy := m.payments[id]
+		payments = append(payments, &paymentCopy)
+	}
+	return payments
+}
+
+// AddLedgerEntry appends to the ledger, entries are never changed
+func (m *MockPaymentRepository) AddLedgerEntry(entry models.LedgerEntry) *models.LedgerEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry.ID = len(m.ledger) + 1
+	entry.CreatedAt = now()
+	m.ledger = append(m.ledger, entry)
+	return &entry
+}
+
+// GetLedger returns the ledger entries of a booking, oldest first
+func (m *MockPaymentRepository) GetLedger(bookingID int) []*models.LedgerEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := []*models.LedgerEntry{}
+	for _, e := range m.ledger {
+		if e.BookingID == bookingID {
+			entryCopy := e
+			entries = append(entries, &entryCopy)
+		}
+	}
+	return entries
+}
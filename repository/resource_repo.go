@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+type (
+	ResourceRepository interface {
+		Create(resource models.Resource) *models.Resource
+		GetByID(id int) (*models.Resource, bool)
+		GetAll() []*models.Resource
+		Update(resource *models.Resource) bool
+		Delete(id int) bool
+		GetRequirements(serviceID int) []models.ResourceRequirement
+		SetRequirements(serviceID int, requirements []models.ResourceRequirement)
+	}
+
+	MockResourceRepository struct {
+		resources map[int]models.Resource
+		// requirements are keyed by service
+		requirements map[int][]models.ResourceRequirement
+		nextID       int
+		mu           sync.RWMutex
+	}
+)
+
+func NewMockResourceRepository() ResourceRepository {
+	return &MockResourceRepository{
+		resources:    make(map[int]models.Resource),
+		requirements: make(map[int][]models.ResourceRequirement),
+	}
+}
+
+// Create
+func (m *MockResourceRepository) Create(resource models.Resource) *models.Resource {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	resource.ID = m.nextID
+	resource.CreatedAt = now()
+	resource.UpdatedAt = resource.CreatedAt
+	m.resources[resource.ID] = resource
+	return &resource
+}
+
+// GetByID
+func (m *MockResourceRepository) GetByID(id int) (*models.Resource, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	resource, exists := m.resources[id]
+	if !exists {
+		return nil, false
+	}
+	return &resource, true
+}
+
+// GetAll returns the resources by ID
+func (m *MockResourceRepository) GetAll() []*models.Resource {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	resources := []*models.Resource{}
+	for _, resource := range m.resources {
+		resourceCopy := resource
+		resources = append(resources, &resourceCopy)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].ID < resources[j].ID
+	})
+	return resources
+}
+
+// Update
+func (m *MockResourceRepository) Update(resource *models.Resource) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, exists := m.resources[resource.ID]
+	if !exists {
+		return false
+	}
+	resource.CreatedAt = existing.CreatedAt
+	resource.UpdatedAt = now()
+	m.resources[resource.ID] = *resource
+	return true
+}
+
+// Delete
+func (m *MockResourceRepository) Delete(id int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.resources[id]; !exists {
+		return false
+	}
+	delete(m.resources, id)
+	return true
+}
+
+// GetRequirements returns the resources each booking of the service needs
+func (m *MockResourceRepository) GetRequirements(serviceID int) []models.ResourceRequirement {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]models.ResourceRequirement{}, m.requirements[serviceID]...)
+}
+
+// SetRequirements replaces the requirements of the service, none removes
+// them
+func (m *MockResourceRepository) SetRequirements(serviceID int, requirements []models.ResourceRequirement) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(requirements) == 0 {
+		delete(m.requirements, serviceID)
+		return
+	}
+	m.requirements[serviceID] = requirements
+}
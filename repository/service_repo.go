@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+type (
+	ServiceRepository interface {
+		GetByID(id int) (*models.Service, bool)
+		GetAll() []*models.Service
+	}
+
+	MockServiceRepository struct {
+		services map[int]models.Service
+		mu       sync.RWMutex
+	}
+)
+
+func NewMockServiceRepository() ServiceRepository {
+	services := make(map[int]models.Service)
+	for i := 1; i <= 10; i++ {
+		services[i] = models.Service{
+			ID:        i,
+			Name:      fmt.Sprintf("Service %d", i),
+			BasePrice: models.NewMoney(int64(i*1000)*100, "THB"),
+			Capacity:  5,
+			Duration:  time.Hour,
+		}
+	}
+	return &MockServiceRepository{
+		services: services,
+	}
+}
+
+// GetByID
+func (m *MockServiceRepository) GetByID(id int) (*models.Service, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	service, exists := m.services[id]
+	if !exists {
+		return nil, false
+	}
+	return &service, true
+}
+
+// GetAll
+func (m *MockServiceRepository) GetAll() []*models.Service {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	services := []*models.Service{}
+	for _, s := range m.services {
+		serviceCopy := s
+		services = append(services, &serviceCopy)
+	}
+	return services
+}
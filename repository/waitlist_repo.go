@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+var ErrWaitlistEntryNotFound = errors.New("waitlist entry not found")
+
+type (
+	WaitlistRepository interface {
+		Create(entry models.WaitlistEntry) *models.WaitlistEntry
+		Update(entry *models.WaitlistEntry) error
+		GetByID(id int) (*models.WaitlistEntry, bool)
+		GetByServiceID(serviceID int) []*models.WaitlistEntry
+		GetByUserID(userID int) []*models.WaitlistEntry
+		GetAll() []*models.WaitlistEntry
+	}
+
+	MockWaitlistRepository struct {
+		entries map[int]models.WaitlistEntry
+		mu      sync.RWMutex
+	}
+)
+
+func NewMockWaitlistRepository() WaitlistRepository {
+	return &MockWaitlistRepository{entries: make(map[int]models.WaitlistEntry)}
+}
+
+// Create
+func (m *MockWaitlistRepository) Create(entry models.WaitlistEntry) *models.WaitlistEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry.ID = len(m.entries) + 1
+	entry.CreatedAt = now()
+	entry.UpdatedAt = entry.CreatedAt
+	m.entries[entry.ID] = entry
+	return &entry
+}
+
+// Update
+func (m *MockWaitlistRepository) Update(entry *models.WaitlistEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.entries[entry.ID]; !exists {
+		return ErrWaitlistEntryNotFound
+	}
+	entry.UpdatedAt = now()
+	m.entries[entry.ID] = *entry
+	return nil
+}
+
+// GetByID
+func (m *MockWaitlistRepository) GetByID(id int) (*models.WaitlistEntry, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entry, exists := m.entries[id]
+	return &entry, exists
+}
+
+// GetByServiceID returns the entries of a service, oldest first
+func (m *MockWaitlistRepository) GetByServiceID(serviceID int) []*models.WaitlistEntry {
+	entries := []*models.WaitlistEntry{}
+	for _, e := range m.GetAll() {
+		if e.ServiceID == serviceID {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// GetByUserID returns the entries of a user, oldest first
+func (m *MockWaitlistRepository) GetByUserID(userID int) []*models.WaitlistEntry {
+	entries := []*models.WaitlistEntry{}
+	for _, e := range m.GetAll() {
+		if e.UserID == userID {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// GetAll returns every entry, oldest first
+func (m *MockWaitlistRepository) GetAll() []*models.WaitlistEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := []*models.WaitlistEntry{}
+	for id := 1; id <= len(m.entries); id++ {
+		entryCopy := m.entries[id]
+		entries = append(entries, &entryCopy)
+	}
+	return entries
+}
@@ -1,25 +1,181 @@
 package router
 
 import (
+	"github.com/Eursukkul/fiber-booking-system/config"
 	"github.com/Eursukkul/fiber-booking-system/handler"
 	"github.com/Eursukkul/fiber-booking-system/middleware"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupRoutes(app *fiber.App, bookingHandler *handler.BookingHandler, logger *middleware.LoggerMiddleware) {
-	api := app.Group("/api")
+// SetupRoutes registers the booking endpoints, every one of them needs a
+// token since bookings belong to users. JwtAuth resolves the tenant of the
+// token.
+func SetupRoutes(app *fiber.App, cfg *config.Config, bookingHandler *handler.BookingHandler, logger *middleware.LoggerMiddleware, auth *middleware.AuthMiddleware, limiter *middleware.RateLimitMiddleware) {
+	api := app.Group("/api", middleware.Timezone())
+	read := limiter.Limit("api:read", cfg.RateLimitRead)
+	write := limiter.Limit("api:write", cfg.RateLimitWrite)
 
-	api.Post("/bookings", logger.Logger, bookingHandler.CreateBooking)
-	api.Get("/bookings/:id", logger.Logger, bookingHandler.GetBookingByID)
-	api.Get("/bookings", logger.Logger, bookingHandler.GetAllBookings)
-	api.Delete("/bookings/:id", logger.Logger, bookingHandler.CancelBooking)
+	api.Post("/bookings", auth.JwtAuth(), write, logger.Logger, bookingHandler.CreateBooking)
+	api.Get("/bookings/export", auth.JwtAuth(), read, logger.Logger, bookingHandler.ExportBookings)
+	api.Post("/bookings/import", auth.JwtAuth(), auth.RequireRole(utils.RoleStaff, utils.RoleAdmin), write, logger.Logger, bookingHandler.ImportBookings)
+	api.Post("/bookings/batch", auth.JwtAuth(), write, logger.Logger, bookingHandler.ExecuteBatch)
+	api.Get("/bookings/:id", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetBookingByID)
+	api.Get("/bookings", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetAllBookings)
+	api.Delete("/bookings/:id", auth.JwtAuth(), write, logger.Logger, bookingHandler.CancelBooking)
+	api.Post("/bookings/:id/reschedule", auth.JwtAuth(), write, logger.Logger, bookingHandler.RescheduleBooking)
+	api.Patch("/bookings/:id", auth.JwtAuth(), write, logger.Logger, bookingHandler.PatchBooking)
+	api.Get("/bookings/:id/history", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetBookingHistory)
+	api.Get("/bookings/:id/cancellation-quote", auth.JwtAuth(), read, logger.Logger, bookingHandler.QuoteCancellation)
+	api.Get("/bookings/:id/invoice", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetInvoice)
+	api.Get("/bookings/:id/payments", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetPayments)
+	api.Get("/bookings/:id/ledger", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetPaymentLedger)
+	api.Post("/bookings/:id/balance", auth.JwtAuth(), write, logger.Logger, bookingHandler.PayBalance)
+	api.Post("/waitlist", auth.JwtAuth(), write, logger.Logger, bookingHandler.JoinWaitlist)
+	api.Get("/waitlist", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetWaitlist)
+	api.Delete("/waitlist/:id", auth.JwtAuth(), write, logger.Logger, bookingHandler.LeaveWaitlist)
+	api.Post("/waitlist/:id/accept", auth.JwtAuth(), write, logger.Logger, bookingHandler.AcceptWaitlistOffer)
+	api.Post("/booking-series", auth.JwtAuth(), write, logger.Logger, bookingHandler.CreateBookingSeries)
+	api.Get("/booking-series/:id", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetBookingSeries)
+	api.Patch("/booking-series/:id", auth.JwtAuth(), write, logger.Logger, bookingHandler.UpdateBookingSeries)
+	api.Delete("/booking-series/:id", auth.JwtAuth(), write, logger.Logger, bookingHandler.CancelBookingSeries)
+	api.Get("/bookings/:id/ics", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetBookingCalendar)
+	api.Post("/calendar/feed", auth.JwtAuth(), write, logger.Logger, bookingHandler.CreateCalendarFeed)
+	api.Get("/reports/bookings", auth.JwtAuth(), auth.RequireRole(utils.RoleStaff, utils.RoleAdmin), read, logger.Logger, bookingHandler.BookingReport)
+	api.Get("/reports/revenue", auth.JwtAuth(), auth.RequireRole(utils.RoleStaff, utils.RoleAdmin), read, logger.Logger, bookingHandler.RevenueReport)
 }
 // if use middleware auth
-func SetupRoutes_middleware(app *fiber.App, bookingHandler *handler.BookingHandler, logger *middleware.LoggerMiddleware, auth *middleware.AuthMiddleware) {
-	api := app.Group("/v1")
+func SetupRoutes_middleware(app *fiber.App, cfg *config.Config, bookingHandler *handler.BookingHandler, logger *middleware.LoggerMiddleware, auth *middleware.AuthMiddleware, limiter *middleware.RateLimitMiddleware) {
+	api := app.Group("/v1", middleware.Timezone())
+	read := limiter.Limit("v1:read", cfg.RateLimitRead)
+	write := limiter.Limit("v1:write", cfg.RateLimitWrite)
  	
-	api.Post("/bookings", auth.JwtAuth(), logger.Logger, bookingHandler.CreateBooking)
-	api.Get("/bookings/:id", auth.JwtAuth(), logger.Logger, bookingHandler.GetBookingByID)
-	api.Get("/bookings", auth.JwtAuth(), logger.Logger, bookingHandler.GetAllBookings)
-	api.Delete("/bookings/:id", auth.JwtAuth(), logger.Logger, bookingHandler.CancelBooking)
-}
\ No newline at end of file
+	api.Post("/bookings", auth.JwtAuth(), write, logger.Logger, bookingHandler.CreateBooking)
+	api.Get("/bookings/export", auth.JwtAuth(), read, logger.Logger, bookingHandler.ExportBookings)
+	api.Post("/bookings/import", auth.JwtAuth(), auth.RequireRole(utils.RoleStaff, utils.RoleAdmin), write, logger.Logger, bookingHandler.ImportBookings)
+	api.Post("/bookings/batch", auth.JwtAuth(), write, logger.Logger, bookingHandler.ExecuteBatch)
+	api.Get("/bookings/:id", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetBookingByID)
+	api.Get("/bookings", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetAllBookings)
+	api.Delete("/bookings/:id", auth.JwtAuth(), write, logger.Logger, bookingHandler.CancelBooking)
+	api.Post("/bookings/:id/reschedule", auth.JwtAuth(), write, logger.Logger, bookingHandler.RescheduleBooking)
+	api.Patch("/bookings/:id", auth.JwtAuth(), write, logger.Logger, bookingHandler.PatchBooking)
+	api.Get("/bookings/:id/history", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetBookingHistory)
+	api.Get("/bookings/:id/cancellation-quote", auth.JwtAuth(), read, logger.Logger, bookingHandler.QuoteCancellation)
+	api.Get("/bookings/:id/invoice", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetInvoice)
+	api.Get("/bookings/:id/payments", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetPayments)
+	api.Get("/bookings/:id/ledger", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetPaymentLedger)
+	api.Post("/bookings/:id/balance", auth.JwtAuth(), write, logger.Logger, bookingHandler.PayBalance)
+	api.Post("/waitlist", auth.JwtAuth(), write, logger.Logger, bookingHandler.JoinWaitlist)
+	api.Get("/waitlist", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetWaitlist)
+	api.Delete("/waitlist/:id", auth.JwtAuth(), write, logger.Logger, bookingHandler.LeaveWaitlist)
+	api.Post("/waitlist/:id/accept", auth.JwtAuth(), write, logger.Logger, bookingHandler.AcceptWaitlistOffer)
+	api.Post("/booking-series", auth.JwtAuth(), write, logger.Logger, bookingHandler.CreateBookingSeries)
+	api.Get("/booking-series/:id", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetBookingSeries)
+	api.Patch("/booking-series/:id", auth.JwtAuth(), write, logger.Logger, bookingHandler.UpdateBookingSeries)
+	api.Delete("/booking-series/:id", auth.JwtAuth(), write, logger.Logger, bookingHandler.CancelBookingSeries)
+	api.Get("/bookings/:id/ics", auth.JwtAuth(), read, logger.Logger, bookingHandler.GetBookingCalendar)
+	api.Post("/calendar/feed", auth.JwtAuth(), write, logger.Logger, bookingHandler.CreateCalendarFeed)
+	api.Put("/bookings/:id/status", auth.JwtAuth(), auth.RequireRole(utils.RoleStaff, utils.RoleAdmin), write, logger.Logger, bookingHandler.UpdateBookingStatus)
+	api.Get("/reports/bookings", auth.JwtAuth(), auth.RequireRole(utils.RoleStaff, utils.RoleAdmin), read, logger.Logger, bookingHandler.BookingReport)
+	api.Get("/reports/revenue", auth.JwtAuth(), auth.RequireRole(utils.RoleStaff, utils.RoleAdmin), read, logger.Logger, bookingHandler.RevenueReport)
+}
+
+// SetupJWKSRoutes publishes the public signing keys
+func SetupJWKSRoutes(app *fiber.App, jwksHandler *handler.JWKSHandler) {
+	app.Get("/.well-known/jwks.json", jwksHandler.GetJWKS)
+}
+
+// SetupApiKeyRoutes exposes bookings to partner systems authenticated by API key
+func SetupApiKeyRoutes(app *fiber.App, cfg *config.Config, bookingHandler *handler.BookingHandler, logger *middleware.LoggerMiddleware, auth *middleware.AuthMiddleware, limiter *middleware.RateLimitMiddleware) {
+	api := app.Group("/partner", auth.ApiKeyAuth(), middleware.Timezone())
+	read := limiter.Limit("partner:read", cfg.RateLimitRead)
+	write := limiter.Limit("partner:write", cfg.RateLimitWrite)
+
+	api.Post("/bookings", auth.RequireScope(models.ScopeBookingsWrite), write, logger.Logger, bookingHandler.CreateBooking)
+	api.Get("/bookings/export", auth.RequireScope(models.ScopeBookingsRead), read, logger.Logger, bookingHandler.ExportBookings)
+	api.Post("/bookings/import", auth.RequireScope(models.ScopeBookingsWrite), auth.RequireRole(utils.RoleStaff, utils.RoleAdmin), write, logger.Logger, bookingHandler.ImportBookings)
+	api.Post("/bookings/batch", auth.RequireScope(models.ScopeBookingsWrite), write, logger.Logger, bookingHandler.ExecuteBatch)
+	api.Get("/bookings/:id", auth.RequireScope(models.ScopeBookingsRead), read, logger.Logger, bookingHandler.GetBookingByID)
+	api.Get("/bookings", auth.RequireScope(models.ScopeBookingsRead), read, logger.Logger, bookingHandler.GetAllBookings)
+	api.Delete("/bookings/:id", auth.RequireScope(models.ScopeBookingsWrite), write, logger.Logger, bookingHandler.CancelBooking)
+	api.Post("/bookings/:id/reschedule", auth.RequireScope(models.ScopeBookingsWrite), write, logger.Logger, bookingHandler.RescheduleBooking)
+	api.Patch("/bookings/:id", auth.RequireScope(models.ScopeBookingsWrite), write, logger.Logger, bookingHandler.PatchBooking)
+	api.Get("/bookings/:id/history", auth.RequireScope(models.ScopeBookingsRead), read, logger.Logger, bookingHandler.GetBookingHistory)
+	api.Get("/bookings/:id/cancellation-quote", auth.RequireScope(models.ScopeBookingsRead), read, logger.Logger, bookingHandler.QuoteCancellation)
+	api.Get("/bookings/:id/invoice", auth.RequireScope(models.ScopeBookingsRead), read, logger.Logger, bookingHandler.GetInvoice)
+	api.Get("/bookings/:id/payments", auth.RequireScope(models.ScopeBookingsRead), read, logger.Logger, bookingHandler.GetPayments)
+	api.Get("/bookings/:id/ledger", auth.RequireScope(models.ScopeBookingsRead), read, logger.Logger, bookingHandler.GetPaymentLedger)
+	api.Post("/bookings/:id/balance", auth.RequireScope(models.ScopeBookingsWrite), write, logger.Logger, bookingHandler.PayBalance)
+	api.Post("/waitlist", auth.RequireScope(models.ScopeBookingsWrite), write, logger.Logger, bookingHandler.JoinWaitlist)
+	api.Get("/waitlist", auth.RequireScope(models.ScopeBookingsRead), read, logger.Logger, bookingHandler.GetWaitlist)
+	api.Delete("/waitlist/:id", auth.RequireScope(models.ScopeBookingsWrite), write, logger.Logger, bookingHandler.LeaveWaitlist)
+	api.Post("/waitlist/:id/accept", auth.RequireScope(models.ScopeBookingsWrite), write, logger.Logger, bookingHandler.AcceptWaitlistOffer)
+	api.Post("/booking-series", auth.RequireScope(models.ScopeBookingsWrite), write, logger.Logger, bookingHandler.CreateBookingSeries)
+	api.Get("/booking-series/:id", auth.RequireScope(models.ScopeBookingsRead), read, logger.Logger, bookingHandler.GetBookingSeries)
+	api.Patch("/booking-series/:id", auth.RequireScope(models.ScopeBookingsWrite), write, logger.Logger, bookingHandler.UpdateBookingSeries)
+	api.Delete("/booking-series/:id", auth.RequireScope(models.ScopeBookingsWrite), write, logger.Logger, bookingHandler.CancelBookingSeries)
+	api.Get("/bookings/:id/ics", auth.RequireScope(models.ScopeBookingsRead), read, logger.Logger, bookingHandler.GetBookingCalendar)
+	api.Post("/calendar/feed", auth.RequireScope(models.ScopeBookingsWrite), write, logger.Logger, bookingHandler.CreateCalendarFeed)
+}
+
+// SetupPricingRoutes lets clients quote a booking before creating it
+func SetupPricingRoutes(app *fiber.App, cfg *config.Config, pricingHandler *handler.PricingHandler, logger *middleware.LoggerMiddleware, auth *middleware.AuthMiddleware, limiter *middleware.RateLimitMiddleware) {
+	app.Post("/api/pricing/quote", middleware.Tenant(cfg), limiter.Limit("api:read", cfg.RateLimitRead), logger.Logger, pricingHandler.Quote)
+	app.Post("/v1/pricing/quote", auth.JwtAuth(), limiter.Limit("v1:read", cfg.RateLimitRead), logger.Logger, pricingHandler.Quote)
+}
+
+// SetupBusinessHoursRoutes shows clients when services can be booked
+func SetupBusinessHoursRoutes(app *fiber.App, cfg *config.Config, businessHoursHandler *handler.BusinessHoursHandler, logger *middleware.LoggerMiddleware, auth *middleware.AuthMiddleware, limiter *middleware.RateLimitMiddleware) {
+	app.Get("/api/services/:id/schedule", middleware.Tenant(cfg), limiter.Limit("api:read", cfg.RateLimitRead), logger.Logger, businessHoursHandler.GetSchedule)
+	app.Get("/v1/services/:id/schedule", auth.JwtAuth(), limiter.Limit("v1:read", cfg.RateLimitRead), logger.Logger, businessHoursHandler.GetSchedule)
+}
+
+// SetupResourceRoutes lets staff see which bookings a resource fulfils
+func SetupResourceRoutes(app *fiber.App, cfg *config.Config, resourceHandler *handler.ResourceHandler, logger *middleware.LoggerMiddleware, auth *middleware.AuthMiddleware, limiter *middleware.RateLimitMiddleware) {
+	app.Get("/v1/resources/:id/schedule", auth.JwtAuth(), auth.RequireRole(utils.RoleStaff, utils.RoleAdmin), middleware.Timezone(), limiter.Limit("v1:read", cfg.RateLimitRead), logger.Logger, resourceHandler.GetResourceSchedule)
+}
+
+// SetupPaymentRoutes receives the callbacks of the payment provider, they are
+// authenticated by their signature and find the payment in any tenant
+func SetupPaymentRoutes(app *fiber.App, cfg *config.Config, bookingHandler *handler.BookingHandler, logger *middleware.LoggerMiddleware) {
+	app.Post("/api/payments/callback", middleware.Tenant(cfg), logger.Logger, bookingHandler.PaymentCallback)
+}
+
+// SetupCalendarRoutes serves the calendar subscription feeds, they are
+// authenticated by the token in the URL since calendar apps cannot log in,
+// the token finds the feed in any tenant
+func SetupCalendarRoutes(app *fiber.App, cfg *config.Config, bookingHandler *handler.BookingHandler, logger *middleware.LoggerMiddleware, limiter *middleware.RateLimitMiddleware) {
+	app.Get("/api/calendar/:token.ics", middleware.Tenant(cfg), limiter.Limit("calendar:read", cfg.RateLimitRead), logger.Logger, bookingHandler.GetCalendarFeed)
+}
+
+// SetupAdminRoutes registers the admin only endpoints
+func SetupAdminRoutes(app *fiber.App, apiKeyHandler *handler.ApiKeyHandler, couponHandler *handler.CouponHandler, businessHoursHandler *handler.BusinessHoursHandler, resourceHandler *handler.ResourceHandler, logger *middleware.LoggerMiddleware, auth *middleware.AuthMiddleware) {
+	admin := app.Group("/v1/admin", auth.JwtAuth(), auth.RequireRole(utils.RoleAdmin))
+
+	admin.Post("/api-keys", logger.Logger, apiKeyHandler.CreateApiKey)
+	admin.Get("/api-keys", logger.Logger, apiKeyHandler.GetAllApiKeys)
+	admin.Delete("/api-keys/:id", logger.Logger, apiKeyHandler.RevokeApiKey)
+
+	admin.Post("/coupons", logger.Logger, couponHandler.CreateCoupon)
+	admin.Get("/coupons", logger.Logger, couponHandler.GetAllCoupons)
+	admin.Get("/coupons/:id", logger.Logger, couponHandler.GetCoupon)
+	admin.Put("/coupons/:id", logger.Logger, couponHandler.UpdateCoupon)
+	admin.Delete("/coupons/:id", logger.Logger, couponHandler.DeleteCoupon)
+
+	admin.Get("/services/:id/hours", logger.Logger, businessHoursHandler.GetBusinessHours)
+	admin.Put("/services/:id/hours", logger.Logger, businessHoursHandler.SetBusinessHours)
+	admin.Delete("/services/:id/hours", logger.Logger, businessHoursHandler.DeleteBusinessHours)
+	admin.Get("/hours-overrides", logger.Logger, businessHoursHandler.GetHoursOverrides)
+	admin.Post("/hours-overrides", logger.Logger, businessHoursHandler.CreateHoursOverride)
+	admin.Delete("/hours-overrides/:id", logger.Logger, businessHoursHandler.DeleteHoursOverride)
+	admin.Post("/holidays/import", logger.Logger, businessHoursHandler.ImportHolidays)
+
+	admin.Post("/resources", logger.Logger, resourceHandler.CreateResource)
+	admin.Get("/resources", logger.Logger, resourceHandler.GetAllResources)
+	admin.Get("/resources/:id", logger.Logger, resourceHandler.GetResource)
+	admin.Put("/resources/:id", logger.Logger, resourceHandler.UpdateResource)
+	admin.Delete("/resources/:id", logger.Logger, resourceHandler.DeleteResource)
+	admin.Get("/services/:id/requirements", logger.Logger, resourceHandler.GetServiceRequirements)
+	admin.Put("/services/:id/requirements", logger.Logger, resourceHandler.SetServiceRequirements)
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/handler"
+	"github.com/Eursukkul/fiber-booking-system/middleware"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/repository"
+	"github.com/Eursukkul/fiber-booking-system/router"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupApiKeyApp() (*fiber.App, usecase.ApiKeyUsecase) {
+	apiKeys := usecase.NewApiKeyUsecase(repository.NewMockApiKeyRepository())
+	auth := middleware.NewAuthMiddleware(&config.Config{}, nil, apiKeys)
+	bookingHandler := handler.NewBookingHandler(newTestBookingUsecase())
+
+	app := fiber.New()
+	limiter := middleware.NewRateLimitMiddleware(middleware.NewInMemoryRateLimitStore())
+	router.SetupApiKeyRoutes(app, &config.Config{}, bookingHandler, middleware.NewLoggerMiddleware(), auth, limiter)
+	return app, apiKeys
+}
+
+func doApiKeyRequest(t *testing.T, app *fiber.App, method, path, key string) int {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-Api-Key", key)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode
+}
+
+func TestApiKey_StoredHashed(t *testing.T) {
+	repo := repository.NewMockApiKeyRepository()
+	apiKeys := usecase.NewApiKeyUsecase(repo)
+
+	created, err := apiKeys.CreateApiKey("", dto.ApiKeyRequest{Name: "partner", Scopes: []string{models.ScopeBookingsRead}})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+
+	stored, exists := repo.GetByHash(utils.HashApiKey(created.Key))
+	require.True(t, exists)
+	assert.NotEqual(t, created.Key, stored.Hash)
+}
+
+func TestApiKey_InvalidScope(t *testing.T) {
+	apiKeys := usecase.NewApiKeyUsecase(repository.NewMockApiKeyRepository())
+
+	_, err := apiKeys.CreateApiKey("", dto.ApiKeyRequest{Name: "partner", Scopes: []string{"bookings:delete"}})
+	assert.ErrorIs(t, err, usecase.ErrInvalidApiKeyRequest)
+
+	for _, req := range []dto.ApiKeyRequest{
+		{Name: "partner", Role: utils.RoleAdmin, Scopes: []string{models.ScopeBookingsRead}},
+		{Name: "partner", Role: utils.RoleCustomer, Scopes: []string{models.ScopeBookingsRead}},
+		{Name: "partner", UserID: 3, Scopes: []string{models.ScopeBookingsRead}},
+	} {
+		_, err = apiKeys.CreateApiKey("", req)
+		assert.ErrorIs(t, err, usecase.ErrInvalidApiKeyRequest, req)
+	}
+}
+
+func TestApiKeyAuth_Scopes(t *testing.T) {
+	app, apiKeys := setupApiKeyApp()
+	created, err := apiKeys.CreateApiKey("", dto.ApiKeyRequest{Name: "reader", Scopes: []string{models.ScopeBookingsRead}})
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusUnauthorized, doApiKeyRequest(t, app, "GET", "/partner/bookings/1", "bk_unknown"))
+	assert.Equal(t, fiber.StatusOK, doApiKeyRequest(t, app, "GET", "/partner/bookings/1", created.Key))
+	assert.Equal(t, fiber.StatusForbidden, doApiKeyRequest(t, app, "DELETE", "/partner/bookings/1", created.Key))
+
+	keys := apiKeys.GetAllApiKeys("")
+	require.Len(t, keys, 1)
+	assert.NotEmpty(t, keys[0].LastUsedAt)
+}
+
+func TestApiKeyAuth_RevokedAndQuota(t *testing.T) {
+	app, apiKeys := setupApiKeyApp()
+	limited, err := apiKeys.CreateApiKey("", dto.ApiKeyRequest{Name: "limited", Scopes: []string{models.ScopeBookingsRead}, QuotaPerDay: 2})
+	require.NoError(t, err)
+	revoked, err := apiKeys.CreateApiKey("", dto.ApiKeyRequest{Name: "revoked", Scopes: []string{models.ScopeBookingsRead}})
+	require.NoError(t, err)
+	require.NoError(t, apiKeys.RevokeApiKey("", revoked.ID))
+
+	assert.Equal(t, fiber.StatusUnauthorized, doApiKeyRequest(t, app, "GET", "/partner/bookings", revoked.Key))
+
+	assert.Equal(t, fiber.StatusOK, doApiKeyRequest(t, app, "GET", "/partner/bookings", limited.Key))
+	assert.Equal(t, fiber.StatusOK, doApiKeyRequest(t, app, "GET", "/partner/bookings", limited.Key))
+	assert.Equal(t, fiber.StatusTooManyRequests, doApiKeyRequest(t, app, "GET", "/partner/bookings", limited.Key))
+}
+
+func TestApiKeyAuth_ActsWithItsRole(t *testing.T) {
+	app, apiKeys := setupApiKeyApp()
+	customer, err := apiKeys.CreateApiKey("", dto.ApiKeyRequest{Name: "app", Role: utils.RoleCustomer, UserID: 3, Scopes: []string{models.ScopeBookingsRead, models.ScopeBookingsWrite}})
+	require.NoError(t, err)
+	assert.Equal(t, utils.RoleCustomer, customer.Role)
+	staff, err := apiKeys.CreateApiKey("", dto.ApiKeyRequest{Name: "partner", Scopes: []string{models.ScopeBookingsRead, models.ScopeBookingsWrite}})
+	require.NoError(t, err)
+	assert.Equal(t, utils.RoleStaff, staff.Role)
+
+	assert.Equal(t, fiber.StatusOK, doApiKeyRequest(t, app, "GET", "/partner/bookings/3", customer.Key))
+	assert.Equal(t, fiber.StatusForbidden, doApiKeyRequest(t, app, "GET", "/partner/bookings/1", customer.Key))
+	assert.Equal(t, fiber.StatusForbidden, doApiKeyRequest(t, app, "DELETE", "/partner/bookings/1", customer.Key))
+	assert.Equal(t, fiber.StatusForbidden, doApiKeyRequest(t, app, "POST", "/partner/bookings/import", customer.Key))
+	assert.Equal(t, fiber.StatusOK, doApiKeyRequest(t, app, "GET", "/partner/bookings/1", staff.Key))
+}
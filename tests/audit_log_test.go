@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpirePendingBookings_CancelsAndRecordsHistory(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{PendingTTL: time.Nanosecond})
+	booking, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1})
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+
+	expired := u.ExpirePendingBookings()
+	assert.Contains(t, expired, booking.ID)
+	assert.IsIncreasing(t, expired)
+
+	canceled, err := u.GetBookingByID(utils.SystemClaims, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusCanceled, canceled.Status)
+
+	history, err := u.GetBookingHistory(utils.SystemClaims, booking.ID)
+	require.NoError(t, err)
+	last := history[len(history)-1]
+	assert.Equal(t, models.HistoryExpired, last.Action)
+	assert.Equal(t, "pending for more than 1ns", last.Reason)
+
+	// a second sweep has nothing left to expire
+	assert.Empty(t, u.ExpirePendingBookings())
+}
+
+func TestExpirePendingBookings_LeavesPaidBookingsAlone(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{PendingTTL: time.Nanosecond})
+
+	// the seeded bookings are paid while they expire
+	var wg sync.WaitGroup
+	for id := 1; id <= 10; id++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			_, _ = u.StartPayment(id)
+		}(id)
+	}
+	expired := u.ExpirePendingBookings()
+	wg.Wait()
+
+	for id := 1; id <= 10; id++ {
+		booking, err := u.GetBookingByID(utils.SystemClaims, id)
+		require.NoError(t, err)
+		ledger, err := u.GetPaymentLedger(utils.SystemClaims, id)
+		require.NoError(t, err)
+		if booking.Status == models.StatusCanceled {
+			assert.Contains(t, expired, id)
+			assert.True(t, ledger.Paid.IsZero(), "expired booking %d kept its payment", id)
+		} else {
+			assert.Equal(t, models.StatusConfirmed, booking.Status)
+			assert.NotContains(t, expired, id)
+		}
+	}
+}
+
+func TestGetAuditLog_ReturnsEntriesAfterCursor(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	first, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1})
+	require.NoError(t, err)
+
+	log := u.GetAuditLog(0)
+	require.NotEmpty(t, log)
+	cursor := log[len(log)-1].ID
+
+	second, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 2})
+	require.NoError(t, err)
+	require.NoError(t, u.UpdateBooking(utils.SystemClaims, first.ID, models.StatusConfirmed))
+
+	entries := u.GetAuditLog(cursor)
+	require.Len(t, entries, 2)
+	assert.Equal(t, second.ID, entries[0].BookingID)
+	assert.Equal(t, models.HistoryCreated, entries[0].Action)
+	assert.Equal(t, first.ID, entries[1].BookingID)
+	assert.Less(t, entries[0].ID, entries[1].ID)
+	assert.Empty(t, u.GetAuditLog(entries[1].ID))
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/handler"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteBatch_AllOrNothingRollsBack(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	staff := &utils.Claims{Id: 1, Role: utils.RoleStaff}
+	notes := "window seat"
+	confirmed := models.StatusConfirmed
+
+	res, err := u.ExecuteBatch(staff, dto.BookingBatchRequest{Operations: []dto.BookingOperation{
+		{Op: dto.BatchCancel, ID: 3},
+		{Op: dto.BatchCreate, Booking: &dto.BookingRequest{UserID: 100, ServiceID: 1, StartAt: slotIn(48 * time.Hour)}},
+		{Op: dto.BatchUpdate, ID: 4, Patch: &dto.BookingPatch{Notes: &notes, Status: &confirmed}},
+		{Op: dto.BatchUpdate, ID: 99, Patch: &dto.BookingPatch{Notes: &notes}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, dto.BatchAllOrNothing, res.Mode)
+	assert.Equal(t, 0, res.Applied)
+	assert.Equal(t, 1, res.Failed)
+	for _, result := range res.Results[:3] {
+		assert.Equal(t, dto.BatchItemRolledBack, result.Status)
+		assert.Nil(t, result.Booking)
+	}
+	assert.Equal(t, dto.BatchItemFailed, res.Results[3].Status)
+	assert.Equal(t, usecase.ErrBookingNotFound.Error(), res.Results[3].Message)
+
+	// every booking is back as it was and nothing was recorded
+	for _, id := range []int{3, 4} {
+		booking, err := u.GetBookingByID(staff, id)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusPending, booking.Status)
+		assert.Empty(t, booking.Notes)
+		history, err := u.GetBookingHistory(staff, id)
+		require.NoError(t, err)
+		assert.Empty(t, history)
+	}
+	booking, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1, StartAt: slotIn(48 * time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, 11, booking.ID)
+
+	_, err = u.ExecuteBatch(staff, dto.BookingBatchRequest{Mode: "some", Operations: []dto.BookingOperation{{Op: dto.BatchCancel, ID: 1}}})
+	assert.ErrorIs(t, err, usecase.ErrInvalidBatch)
+	_, err = u.ExecuteBatch(staff, dto.BookingBatchRequest{})
+	assert.ErrorIs(t, err, usecase.ErrInvalidBatch)
+}
+
+func TestExecuteBatch_ChecksTheCaller(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	notes := "window seat"
+
+	_, err := u.ExecuteBatch(nil, dto.BookingBatchRequest{Operations: []dto.BookingOperation{{Op: dto.BatchCancel, ID: 3}}})
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+
+	// customers only change their own bookings
+	res, err := u.ExecuteBatch(&utils.Claims{Id: 3}, dto.BookingBatchRequest{Mode: dto.BatchPartial, Operations: []dto.BookingOperation{
+		{Op: dto.BatchCancel, ID: 4},
+		{Op: dto.BatchUpdate, ID: 5, Patch: &dto.BookingPatch{Notes: &notes}},
+		{Op: dto.BatchUpdate, ID: 3, Patch: &dto.BookingPatch{Notes: &notes}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Applied)
+	assert.Equal(t, usecase.ErrForbidden.Error(), res.Results[0].Message)
+	assert.Equal(t, usecase.ErrForbidden.Error(), res.Results[1].Message)
+	assert.Equal(t, dto.BatchItemApplied, res.Results[2].Status)
+}
+
+func TestExecuteBatch_PartialSeesEarlierOperations(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	start := slotIn(72 * time.Hour)
+	// service 1 takes 5 bookings a slot
+	for i := 0; i < 5; i++ {
+		_, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100 + i, ServiceID: 1, StartAt: start})
+		require.NoError(t, err)
+	}
+
+	res, err := u.ExecuteBatch(utils.SystemClaims, dto.BookingBatchRequest{Mode: dto.BatchPartial, Operations: []dto.BookingOperation{
+		{Op: dto.BatchCreate, Booking: &dto.BookingRequest{UserID: 200, ServiceID: 1, StartAt: start}},
+		{Op: dto.BatchCancel, ID: 11},
+		{Op: dto.BatchCreate, Booking: &dto.BookingRequest{UserID: 200, ServiceID: 1, StartAt: start}},
+		{Op: dto.BatchCancel, ID: 11},
+		{Op: "archive", ID: 12},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Applied)
+	assert.Equal(t, 3, res.Failed)
+	assert.Equal(t, usecase.ErrSlotFull.Error(), res.Results[0].Message)
+	require.Equal(t, dto.BatchItemApplied, res.Results[1].Status)
+	assert.Equal(t, models.StatusCanceled, res.Results[1].Booking.Status)
+	require.Equal(t, dto.BatchItemApplied, res.Results[2].Status)
+	assert.Equal(t, 16, res.Results[2].Booking.ID)
+	assert.Contains(t, res.Results[3].Message, usecase.ErrInvalidTransition.Error())
+	assert.Contains(t, res.Results[4].Message, usecase.ErrInvalidBatch.Error())
+
+	history, err := u.GetBookingHistory(utils.SystemClaims, 11)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, models.HistoryCanceled, history[1].Action)
+	history, err = u.GetBookingHistory(utils.SystemClaims, 16)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
+
+func TestExecuteBatch_Route(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	app := fiber.New()
+	app.Use(authenticated(&utils.Claims{Id: 1, Role: utils.RoleStaff}))
+	app.Post("/api/bookings/batch", handler.NewBookingHandler(u).ExecuteBatch)
+
+	batch := func(body string) (int, dto.BookingBatchResponse) {
+		req := httptest.NewRequest("POST", "/api/bookings/batch", strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		var res dto.BookingBatchResponse
+		_ = json.NewDecoder(resp.Body).Decode(&res)
+		return resp.StatusCode, res
+	}
+
+	operations := `[{"op":"update","id":2,"patch":{"notes":"late arrival"}},{"op":"cancel","id":99}]`
+	status, res := batch(`{"operations":` + operations + `}`)
+	assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	assert.Equal(t, dto.BatchItemRolledBack, res.Results[0].Status)
+
+	status, res = batch(`{"mode":"partial","operations":` + operations + `}`)
+	assert.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, dto.BatchItemApplied, res.Results[0].Status)
+	assert.Equal(t, "late arrival", res.Results[0].Booking.Notes)
+	assert.Equal(t, dto.BatchItemFailed, res.Results[1].Status)
+
+	status, _ = batch(`{"operations":[{"op":"update","id":2,"patch":{"id":5}}]}`)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+
+	status, _ = batch(`{"mode":"some","operations":` + operations + `}`)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
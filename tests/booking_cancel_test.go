@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCancellationPolicy = config.CancellationPolicy{
+	FreeBefore: 24 * time.Hour,
+	Tiers: []config.CancellationTier{
+		{Before: 12 * time.Hour, FeePercent: 25},
+		{Before: 2 * time.Hour, FeePercent: 50},
+	},
+}
+
+func TestCancellationPolicy_FeePercent(t *testing.T) {
+	assert.Equal(t, 0.0, testCancellationPolicy.FeePercent(48*time.Hour))
+	assert.Equal(t, 25.0, testCancellationPolicy.FeePercent(20*time.Hour))
+	assert.Equal(t, 50.0, testCancellationPolicy.FeePercent(3*time.Hour))
+	assert.Equal(t, 100.0, testCancellationPolicy.FeePercent(time.Hour))
+	assert.Equal(t, 100.0, config.CancellationPolicy{NonRefundable: true}.FeePercent(48*time.Hour))
+}
+
+func TestCancelBooking_RefundsConfirmedBooking(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{
+		CancellationPolicy: testCancellationPolicy,
+		ServiceCancellationPolicies: map[int]config.CancellationPolicy{
+			2: {NonRefundable: true},
+		},
+	})
+	owner := &utils.Claims{Id: 100}
+	staff := &utils.Claims{Id: 1, Role: utils.RoleStaff}
+
+	booking, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 1, Price: thb("1000"), StartAt: slotIn(20 * time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, u.UpdateBooking(staff, booking.ID, "confirmed"))
+
+	quote, err := u.QuoteCancellation(owner, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, thb("1000"), quote.Paid)
+	assert.Equal(t, 25.0, quote.FeePercent)
+	assert.Equal(t, thb("750"), quote.Refund)
+
+	require.NoError(t, u.CancelBooking(owner, booking.ID))
+	canceled, err := u.GetBookingByID(owner, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "canceled", canceled.Status)
+	assert.Equal(t, thb("250"), canceled.CancellationFee)
+	assert.Equal(t, thb("750"), canceled.RefundAmount)
+
+	assert.ErrorIs(t, u.CancelBooking(owner, booking.ID), usecase.ErrInvalidTransition)
+	_, err = u.QuoteCancellation(owner, booking.ID)
+	assert.ErrorIs(t, err, usecase.ErrInvalidTransition)
+
+	// the service has its own non-refundable policy
+	kept, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 2, Price: thb("2000"), StartAt: slotIn(72 * time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, u.UpdateBooking(staff, kept.ID, "confirmed"))
+	quote, err = u.QuoteCancellation(owner, kept.ID)
+	require.NoError(t, err)
+	assert.True(t, quote.NonRefundable)
+	assert.Equal(t, thb("0"), quote.Refund)
+}
+
+func TestCancelBooking_PendingIsFree(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{CancellationPolicy: config.CancellationPolicy{NonRefundable: true}})
+	owner := &utils.Claims{Id: 100}
+
+	booking, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 1, Price: thb("1000"), StartAt: slotIn(time.Hour)})
+	require.NoError(t, err)
+
+	quote, err := u.QuoteCancellation(owner, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, thb("0"), quote.Paid)
+	assert.Equal(t, thb("0"), quote.Fee)
+
+	_, err = u.QuoteCancellation(&utils.Claims{Id: 101}, booking.ID)
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+}
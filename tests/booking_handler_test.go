@@ -11,12 +11,16 @@ import (
 	"github.com/Eursukkul/fiber-booking-system/dto"
 	"github.com/Eursukkul/fiber-booking-system/handler"
 	"github.com/Eursukkul/fiber-booking-system/mocks"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func setupTestApp(handler *handler.BookingHandler) *fiber.App {
 	app := fiber.New()
+	app.Use(authenticated(&utils.Claims{Id: 1, Role: utils.RoleStaff}))
 	app.Post("/api/bookings", handler.CreateBooking)
 	app.Get("/api/bookings/:id", handler.GetBookingByID)
 	app.Get("/api/bookings", handler.GetAllBookings)
@@ -26,26 +30,28 @@ func setupTestApp(handler *handler.BookingHandler) *fiber.App {
 
 func TestCreateBooking_Success(t *testing.T) {
 	mockUsecase := new(mocks.MockBookingUsecase)
+	mockUsecase.On("DisplayLocation", mock.Anything, mock.Anything).Return(time.UTC).Maybe()
 	bookingHandler := handler.NewBookingHandler(mockUsecase)
 	app := setupTestApp(bookingHandler)
 
 	reqBody := dto.BookingRequest{
 		UserID:    1,
 		ServiceID: 2,
-		Price:     1000,
+		Price:     thb("1000"),
 	}
 
 	expectedResp := &dto.BookingResponse{
 		ID:        1,
 		UserID:    1,
 		ServiceID: 2,
-		Price:     1000,
+		Price:     thb("1000"),
 		Status:    "pending",
-		CreatedAt: time.Now().Format(time.RFC3339),
-		UpdatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 	}
 
-	mockUsecase.On("CreateBooking", reqBody).Return(expectedResp, nil)
+	mockUsecase.On("CreateBooking", mock.Anything, reqBody).Return(expectedResp, nil)
+	mockUsecase.On("StartPayment", expectedResp.ID).Return(nil, nil).Maybe()
 
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest("POST", "/api/bookings", bytes.NewReader(body))
@@ -66,6 +72,7 @@ func TestCreateBooking_Success(t *testing.T) {
 
 func TestGetBookingByID_Success(t *testing.T) {
 	mockUsecase := new(mocks.MockBookingUsecase)
+	mockUsecase.On("DisplayLocation", mock.Anything, mock.Anything).Return(time.UTC).Maybe()
 	bookingHandler := handler.NewBookingHandler(mockUsecase)
 	app := setupTestApp(bookingHandler)
 
@@ -73,13 +80,13 @@ func TestGetBookingByID_Success(t *testing.T) {
 		ID:        1,
 		UserID:    1,
 		ServiceID: 2,
-		Price:     1000,
+		Price:     thb("1000"),
 		Status:    "pending",
-		CreatedAt: time.Now().Format(time.RFC3339),
-		UpdatedAt: time.Now().Format(time.RFC3339),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 	}
 
-	mockUsecase.On("GetBookingByID", 1).Return(expectedResp, nil)
+	mockUsecase.On("GetBookingByID", mock.Anything, 1).Return(expectedResp, nil)
 
 	req := httptest.NewRequest("GET", "/api/bookings/1", nil)
 	resp, err := app.Test(req)
@@ -96,10 +103,11 @@ func TestGetBookingByID_Success(t *testing.T) {
 
 func TestGetBookingByID_NotFound(t *testing.T) {
 	mockUsecase := new(mocks.MockBookingUsecase)
+	mockUsecase.On("DisplayLocation", mock.Anything, mock.Anything).Return(time.UTC).Maybe()
 	bookingHandler := handler.NewBookingHandler(mockUsecase)
 	app := setupTestApp(bookingHandler)
 
-	mockUsecase.On("GetBookingByID", 999).Return(nil, errors.New("booking not found"))
+	mockUsecase.On("GetBookingByID", mock.Anything, 999).Return(nil, errors.New("booking not found"))
 
 	req := httptest.NewRequest("GET", "/api/bookings/999", nil)
 	resp, err := app.Test(req)
@@ -112,6 +120,7 @@ func TestGetBookingByID_NotFound(t *testing.T) {
 
 func TestGetAllBookings_Success(t *testing.T) {
 	mockUsecase := new(mocks.MockBookingUsecase)
+	mockUsecase.On("DisplayLocation", mock.Anything, mock.Anything).Return(time.UTC).Maybe()
 	bookingHandler := handler.NewBookingHandler(mockUsecase)
 	app := setupTestApp(bookingHandler)
 
@@ -120,23 +129,23 @@ func TestGetAllBookings_Success(t *testing.T) {
 			ID:        1,
 			UserID:    1,
 			ServiceID: 2,
-			Price:     1000,
+			Price:     thb("1000"),
 			Status:    "pending",
-			CreatedAt: time.Now().Format(time.RFC3339),
-			UpdatedAt: time.Now().Format(time.RFC3339),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
 		},
 		{
 			ID:        2,
 			UserID:    2,
 			ServiceID: 3,
-			Price:     2000,
+			Price:     thb("2000"),
 			Status:    "confirmed",
-			CreatedAt: time.Now().Format(time.RFC3339),
-			UpdatedAt: time.Now().Format(time.RFC3339),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
 		},
 	}
 
-	mockUsecase.On("GetAllBookings", "price", "false").Return(expectedResp, nil)
+	mockUsecase.On("GetAllBookings", mock.Anything, "price", "false").Return(expectedResp, nil)
 
 	req := httptest.NewRequest("GET", "/api/bookings?sort=price&high-value=false", nil)
 	resp, err := app.Test(req)
@@ -153,6 +162,7 @@ func TestGetAllBookings_Success(t *testing.T) {
 
 func TestCancelBooking_Success(t *testing.T) {
 	mockUsecase := new(mocks.MockBookingUsecase)
+	mockUsecase.On("DisplayLocation", mock.Anything, mock.Anything).Return(time.UTC).Maybe()
 	bookingHandler := handler.NewBookingHandler(mockUsecase)
 	app := setupTestApp(bookingHandler)
 
@@ -161,8 +171,8 @@ func TestCancelBooking_Success(t *testing.T) {
 		Status: "pending",
 	}
 
-	mockUsecase.On("GetBookingByID", 1).Return(mockBooking, nil)
-	mockUsecase.On("CancelBooking", 1).Return(nil)
+	mockUsecase.On("GetBookingByID", mock.Anything, 1).Return(mockBooking, nil)
+	mockUsecase.On("CancelBooking", mock.Anything, 1).Return(nil)
 
 	req := httptest.NewRequest("DELETE", "/api/bookings/1", nil)
 	resp, err := app.Test(req)
@@ -173,23 +183,19 @@ func TestCancelBooking_Success(t *testing.T) {
 	mockUsecase.AssertExpectations(t)
 }
 
-func TestCancelBooking_AlreadyConfirmed(t *testing.T) {
+func TestCancelBooking_NotCancelable(t *testing.T) {
 	mockUsecase := new(mocks.MockBookingUsecase)
+	mockUsecase.On("DisplayLocation", mock.Anything, mock.Anything).Return(time.UTC).Maybe()
 	bookingHandler := handler.NewBookingHandler(mockUsecase)
 	app := setupTestApp(bookingHandler)
 
-	mockBooking := &dto.BookingResponse{
-		ID:     1,
-		Status: "confirmed",
-	}
-
-	mockUsecase.On("GetBookingByID", 1).Return(mockBooking, nil)
+	mockUsecase.On("CancelBooking", mock.Anything, 1).Return(usecase.ErrInvalidTransition)
 
 	req := httptest.NewRequest("DELETE", "/api/bookings/1", nil)
 	resp, err := app.Test(req)
 
 	assert.NoError(t, err)
-	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
 
 	mockUsecase.AssertExpectations(t)
 }
@@ -0,0 +1,245 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/handler"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportBookings_AtomicOrBestEffort(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	rows := []dto.BookingImportRow{
+		{Line: 2, BookingRequest: dto.BookingRequest{UserID: 100, ServiceID: 1, StartAt: slotIn(48 * time.Hour)}, Status: models.StatusConfirmed},
+		{Line: 3, BookingRequest: dto.BookingRequest{UserID: 101, ServiceID: 99}},
+		{Line: 4, Error: "user_id must be a number"},
+		{Line: 5, BookingRequest: dto.BookingRequest{UserID: 102, ServiceID: 2}, Status: "done"},
+	}
+
+	_, err := u.ImportBookings(&utils.Claims{Id: 100}, rows, dto.BookingImportOptions{})
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+	_, err = u.ImportBookings(nil, rows, dto.BookingImportOptions{})
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+
+	// an atomic import with a failed row imports nothing
+	res, err := u.ImportBookings(utils.SystemClaims, rows, dto.BookingImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, dto.ImportAtomic, res.Mode)
+	assert.Equal(t, 0, res.Imported)
+	assert.Equal(t, 3, res.Failed)
+	assert.Equal(t, dto.ImportRowValid, res.Rows[0].Status)
+	assert.Equal(t, 3, res.Rows[1].Line)
+	assert.Equal(t, usecase.ErrServiceNotFound.Error(), res.Rows[1].Message)
+	assert.Contains(t, res.Rows[2].Message, "user_id must be a number")
+	assert.Equal(t, usecase.ErrInvalidStatus.Error(), res.Rows[3].Message)
+	bookings, err := u.GetAllBookings(utils.SystemClaims, "", "")
+	require.NoError(t, err)
+	assert.Len(t, bookings, 10)
+
+	res, err = u.ImportBookings(utils.SystemClaims, rows, dto.BookingImportOptions{Mode: dto.ImportBestEffort})
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Imported)
+	require.Equal(t, dto.ImportRowImported, res.Rows[0].Status)
+	imported := res.Rows[0].Booking
+	assert.Equal(t, 11, imported.ID)
+	assert.Equal(t, models.StatusConfirmed, imported.Status)
+
+	history, err := u.GetBookingHistory(utils.SystemClaims, imported.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, models.HistoryCreated, history[0].Action)
+	assert.Equal(t, "imported", history[1].Reason)
+	// imported bookings are invoiced like confirmed ones
+	invoice, err := u.GetInvoice(utils.SystemClaims, imported.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, invoice.Invoices)
+
+	_, err = u.ImportBookings(utils.SystemClaims, rows, dto.BookingImportOptions{Mode: "some"})
+	assert.ErrorIs(t, err, usecase.ErrInvalidImport)
+}
+
+func TestImportBookings_RowsSeeTheRowsBeforeThem(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	start := slotIn(72 * time.Hour)
+	rows := []dto.BookingImportRow{}
+	// service 1 takes 5 bookings a slot
+	for i := 0; i < 6; i++ {
+		rows = append(rows, dto.BookingImportRow{Line: i + 2, BookingRequest: dto.BookingRequest{UserID: 100 + i, ServiceID: 1, StartAt: start}})
+	}
+
+	res, err := u.ImportBookings(utils.SystemClaims, rows, dto.BookingImportOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Failed)
+	assert.Equal(t, usecase.ErrSlotFull.Error(), res.Rows[5].Message)
+
+	// the dry run saved nothing
+	booking, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1, StartAt: start})
+	require.NoError(t, err)
+	assert.Equal(t, 11, booking.ID)
+	history, err := u.GetBookingHistory(utils.SystemClaims, booking.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
+
+func TestImportBookings_Migration(t *testing.T) {
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	u := newBookingUsecaseWithConfig(cfg)
+	lastYear := time.Now().AddDate(-1, 0, 0).Truncate(time.Hour).UTC()
+
+	rows := []dto.BookingImportRow{
+		// a booking that took place last year
+		{Line: 2, BookingRequest: dto.BookingRequest{UserID: 500, ServiceID: 1, StartAt: lastYear.Format(time.RFC3339), Price: thb("800")},
+			Status: models.StatusConfirmed, CreatedAt: lastYear.AddDate(0, 0, -3).Format(time.RFC3339)},
+		{Line: 3, BookingRequest: dto.BookingRequest{UserID: 500, ServiceID: 1, StartAt: lastYear.AddDate(0, 0, 7).Format(time.RFC3339)},
+			Status: models.StatusCanceled},
+	}
+	// more upcoming bookings of one customer than the booking policies allow
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Hour)
+	for i := 0; i < 20; i++ {
+		rows = append(rows, dto.BookingImportRow{
+			Line:           len(rows) + 2,
+			BookingRequest: dto.BookingRequest{UserID: 500, ServiceID: 1, StartAt: start.Add(time.Duration(i) * time.Hour).Format(time.RFC3339)},
+			Status:         models.StatusPending,
+		})
+	}
+
+	res, err := u.ImportBookings(utils.SystemClaims, rows, dto.BookingImportOptions{Migrate: true})
+	require.NoError(t, err)
+	assert.True(t, res.Migrate)
+	require.Equal(t, 0, res.Failed, res.Rows)
+	assert.Equal(t, 22, res.Imported)
+
+	past := res.Rows[0].Booking
+	assert.Equal(t, models.StatusConfirmed, past.Status)
+	assert.Equal(t, lastYear, past.StartAt)
+	assert.Equal(t, lastYear.AddDate(0, 0, -3), past.CreatedAt)
+	assert.Equal(t, "800.00 THB", past.Price.String())
+	assert.Equal(t, models.StatusCanceled, res.Rows[1].Booking.Status)
+	for _, row := range res.Rows[2:] {
+		assert.Equal(t, models.StatusPending, row.Booking.Status)
+	}
+
+	// the old system invoiced the booking, only its history is recorded
+	_, err = u.GetInvoice(utils.SystemClaims, past.ID)
+	assert.ErrorIs(t, err, usecase.ErrInvoiceNotFound)
+	history, err := u.GetBookingHistory(utils.SystemClaims, past.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "migrated", history[0].Reason)
+
+	// upcoming active bookings still need room in the slot
+	full := []dto.BookingImportRow{}
+	for i := 0; i < 5; i++ {
+		full = append(full, dto.BookingImportRow{Line: i + 2, BookingRequest: dto.BookingRequest{UserID: 600 + i, ServiceID: 1, StartAt: start.Format(time.RFC3339)}})
+	}
+	res, err = u.ImportBookings(utils.SystemClaims, full, dto.BookingImportOptions{Mode: dto.ImportBestEffort, Migrate: true})
+	require.NoError(t, err)
+	assert.Equal(t, 4, res.Imported)
+	assert.Equal(t, usecase.ErrSlotFull.Error(), res.Rows[4].Message)
+
+	// without migrate the same past rows are booked like new bookings
+	res, err = u.ImportBookings(utils.SystemClaims, rows[:1], dto.BookingImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Failed)
+}
+
+func TestExportImportBookings_Routes(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	h := handler.NewBookingHandler(u)
+	app := fiber.New()
+	app.Use(authenticated(&utils.Claims{Id: 1, Role: utils.RoleStaff}))
+	app.Get("/api/bookings/export", h.ExportBookings)
+	app.Post("/api/bookings/import", h.ImportBookings)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/bookings/export?format=csv&sort=price", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get(fiber.HeaderContentType))
+	body, _ := io.ReadAll(resp.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	require.Len(t, lines, 11)
+	assert.True(t, strings.HasPrefix(lines[0], "id,user_id,service_id,status,price,currency,"))
+	assert.True(t, strings.HasPrefix(lines[1], "1,1,1,pending,1000.00,THB,"))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/bookings/export?format=jsonl", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get(fiber.HeaderContentType))
+	body, _ = io.ReadAll(resp.Body)
+	lines = strings.Split(strings.TrimSpace(string(body)), "\n")
+	require.Len(t, lines, 10)
+	for _, line := range lines {
+		var booking dto.BookingResponse
+		require.NoError(t, json.Unmarshal([]byte(line), &booking))
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/bookings/export?format=xml", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	importFile := func(query, contentType, file string) (int, dto.BookingImportResponse) {
+		req := httptest.NewRequest("POST", "/api/bookings/import"+query, strings.NewReader(file))
+		req.Header.Set(fiber.HeaderContentType, contentType)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		var res dto.BookingImportResponse
+		_ = json.NewDecoder(resp.Body).Decode(&res)
+		return resp.StatusCode, res
+	}
+
+	csvFile := "\ufeffuser_id,service_id,price,currency,status,notes\n" +
+		"100,2,1500.00,THB,confirmed,\"migrated, row 2\"\n" +
+		"101,2,15.001,THB,,\n" +
+		"abc,2,,,,\n"
+	status, res := importFile("", "text/csv", csvFile)
+	assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	assert.Equal(t, 0, res.Imported)
+	require.Len(t, res.Rows, 3)
+	assert.Equal(t, 3, res.Rows[1].Line)
+	assert.Contains(t, res.Rows[1].Message, "price")
+	assert.Equal(t, 4, res.Rows[2].Line)
+
+	status, res = importFile("?mode=best_effort", "text/csv", csvFile)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 1, res.Imported)
+	assert.Equal(t, "1500.00 THB", res.Rows[0].Booking.Price.String())
+	assert.Equal(t, "migrated, row 2", res.Rows[0].Booking.Notes)
+
+	jsonlFile := fmt.Sprintf("{\"user_id\":100,\"service_id\":3,\"start_at\":%q}\n\nnot json\n", slotIn(48*time.Hour))
+	status, res = importFile("?format=jsonl&mode=best_effort&dry_run=true", "text/plain", jsonlFile)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.True(t, res.DryRun)
+	assert.Equal(t, dto.ImportRowValid, res.Rows[0].Status)
+	assert.Equal(t, 3, res.Rows[1].Line)
+	assert.Equal(t, dto.ImportRowFailed, res.Rows[1].Status)
+
+	status, _ = importFile("", "application/json", jsonlFile)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+
+	migration := "user_id,service_id,status,price,currency,start_at,created_at\n" +
+		"100,2,confirmed,900.00,THB,2023-05-01T09:00:00Z,2023-04-20T12:00:00Z\n" +
+		"101,2,pending,,,2023-05-02T09:00:00Z,\n"
+	status, res = importFile("?migrate=true", "text/csv", migration)
+	assert.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, 2, res.Imported)
+	assert.Equal(t, "2023-04-20T12:00:00Z", res.Rows[0].Booking.CreatedAt.UTC().Format(time.RFC3339))
+	assert.Equal(t, "900.00 THB", res.Rows[0].Booking.Price.String())
+	// migrated pending bookings are not charged again
+	time.Sleep(10 * time.Millisecond)
+	booking, err := u.GetBookingByID(utils.SystemClaims, res.Rows[1].Booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusPending, booking.Status)
+}
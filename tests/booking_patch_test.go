@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/handler"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookingPatch_Unmarshal(t *testing.T) {
+	var patch dto.BookingPatch
+	require.NoError(t, json.Unmarshal([]byte(`{"notes":null,"price":{"amount":"1500","currency":"thb"}}`), &patch))
+	assert.True(t, patch.ClearNotes)
+	assert.Equal(t, thb("1500"), *patch.Price)
+	assert.Nil(t, patch.Status)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"user_id":2}`), &patch))
+	assert.Error(t, json.Unmarshal([]byte(`{"status":null}`), &patch))
+	assert.Error(t, json.Unmarshal([]byte(`{"price":"free"}`), &patch))
+	assert.Error(t, json.Unmarshal([]byte(`{"price":{"amount":1500,"currency":"THB"}}`), &patch))
+}
+
+func TestPatchBooking_FieldPermissions(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	owner := &utils.Claims{Id: 100}
+	staff := &utils.Claims{Id: 1, Role: utils.RoleStaff}
+
+	booking, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 1, Price: thb("1000"), StartAt: slotIn(48 * time.Hour)})
+	require.NoError(t, err)
+
+	notes := "window seat"
+	patched, err := u.PatchBooking(owner, booking.ID, dto.BookingPatch{Notes: &notes})
+	require.NoError(t, err)
+	assert.Equal(t, notes, patched.Notes)
+
+	price := thb("500")
+	_, err = u.PatchBooking(owner, booking.ID, dto.BookingPatch{Price: &price})
+	assert.ErrorIs(t, err, usecase.ErrFieldForbidden)
+
+	confirmed := "confirmed"
+	_, err = u.PatchBooking(owner, booking.ID, dto.BookingPatch{Status: &confirmed})
+	assert.ErrorIs(t, err, usecase.ErrFieldForbidden)
+
+	_, err = u.PatchBooking(&utils.Claims{Id: 101}, booking.ID, dto.BookingPatch{Notes: &notes})
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+	_, err = u.PatchBooking(nil, booking.ID, dto.BookingPatch{Price: &price})
+	assert.ErrorIs(t, err, usecase.ErrFieldForbidden)
+	_, err = u.PatchBooking(nil, booking.ID, dto.BookingPatch{Notes: &notes})
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+
+	// staff change the service and set the price in one patch
+	service := 2
+	patched, err = u.PatchBooking(staff, booking.ID, dto.BookingPatch{ServiceID: &service, Price: &price, Status: &confirmed})
+	require.NoError(t, err)
+	assert.Equal(t, 2, patched.ServiceID)
+	assert.Equal(t, thb("500"), patched.Price)
+	assert.Equal(t, "confirmed", patched.Status)
+
+	history, err := u.GetBookingHistory(staff, booking.ID)
+	require.NoError(t, err)
+	last := history[len(history)-1]
+	assert.Equal(t, "updated", last.Action)
+	assert.Equal(t, "1000.00 THB", last.Changes["price"].From)
+	assert.Equal(t, "500.00 THB", last.Changes["price"].To)
+}
+
+func TestPatchBooking_StateMachine(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	staff := &utils.Claims{Id: 1, Role: utils.RoleStaff}
+
+	booking, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1, Price: thb("1000")})
+	require.NoError(t, err)
+
+	rejected, pending := "rejected", "pending"
+	_, err = u.PatchBooking(staff, booking.ID, dto.BookingPatch{Status: &rejected})
+	require.NoError(t, err)
+
+	_, err = u.PatchBooking(staff, booking.ID, dto.BookingPatch{Status: &pending})
+	assert.ErrorIs(t, err, usecase.ErrInvalidTransition)
+	assert.ErrorIs(t, u.UpdateBooking(staff, booking.ID, "confirmed"), usecase.ErrInvalidTransition)
+}
+
+func TestPatchBooking_Handler(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	booking, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1, Price: thb("1000"), Notes: "old"})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(authenticated(&utils.Claims{Id: 1, Role: utils.RoleStaff}))
+	app.Patch("/api/bookings/:id", handler.NewBookingHandler(u).PatchBooking)
+
+	patch := func(body string) (int, dto.BookingResponse) {
+		req := httptest.NewRequest("PATCH", "/api/bookings/"+strconv.Itoa(booking.ID), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		var res dto.BookingResponse
+		json.NewDecoder(resp.Body).Decode(&res)
+		return resp.StatusCode, res
+	}
+
+	status, res := patch(`{"notes":null,"status":"canceled"}`)
+	assert.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, booking.ID, res.ID)
+	assert.Empty(t, res.Notes)
+	assert.Equal(t, "canceled", res.Status)
+
+	status, _ = patch(`{"status":"confirmed"}`)
+	assert.Equal(t, fiber.StatusConflict, status)
+
+	status, _ = patch(`{"id":5}`)
+	assert.Equal(t, fiber.StatusBadRequest, status)
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/handler"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPolicyUsecase(policy config.BookingPolicy) usecase.BookingUsecase {
+	return newBookingUsecaseWithConfig(&config.Config{BookingPolicy: policy})
+}
+
+func assertPolicyCode(t *testing.T, err error, code string) {
+	var policyErr *usecase.PolicyError
+	require.True(t, errors.As(err, &policyErr), "expected a policy error, got %v", err)
+	assert.Equal(t, code, string(policyErr.Code))
+}
+
+func TestBookingPolicy_Blocklist(t *testing.T) {
+	u := newPolicyUsecase(config.BookingPolicy{BlockedUsers: []int{42}, BlockedServices: []int{9}})
+
+	_, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 42, ServiceID: 1, Price: thb("100")})
+	assertPolicyCode(t, err, "booking-004")
+
+	_, err = u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 43, ServiceID: 9, Price: thb("100")})
+	assertPolicyCode(t, err, "booking-005")
+}
+
+func TestBookingPolicy_MaxPending(t *testing.T) {
+	u := newPolicyUsecase(config.BookingPolicy{MaxPendingPerUser: 2})
+	req := dto.BookingRequest{UserID: 42, ServiceID: 1, Price: thb("100")}
+
+	_, err := u.CreateBooking(utils.SystemClaims, req)
+	require.NoError(t, err)
+	second, err := u.CreateBooking(utils.SystemClaims, req)
+	require.NoError(t, err)
+
+	_, err = u.CreateBooking(utils.SystemClaims, req)
+	assertPolicyCode(t, err, "booking-001")
+
+	// canceling frees a slot
+	require.NoError(t, u.CancelBooking(utils.SystemClaims, second.ID))
+	_, err = u.CreateBooking(utils.SystemClaims, req)
+	assert.NoError(t, err)
+}
+
+func TestBookingPolicy_Velocity(t *testing.T) {
+	u := newPolicyUsecase(config.BookingPolicy{Velocity: config.RateLimit{Requests: 2, Period: time.Hour}})
+	req := dto.BookingRequest{UserID: 42, ServiceID: 1, Price: thb("100")}
+
+	for i := 0; i < 2; i++ {
+		_, err := u.CreateBooking(utils.SystemClaims, req)
+		require.NoError(t, err)
+	}
+	_, err := u.CreateBooking(utils.SystemClaims, req)
+	assertPolicyCode(t, err, "booking-003")
+}
+
+func TestBookingPolicy_DailySpend(t *testing.T) {
+	u := newPolicyUsecase(config.BookingPolicy{MaxDailySpend: thb("5000")})
+
+	_, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 42, ServiceID: 1, Price: thb("3000")})
+	require.NoError(t, err)
+
+	_, err = u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 42, ServiceID: 1, Price: thb("3000")})
+	assertPolicyCode(t, err, "booking-002")
+}
+
+func TestCreateBooking_PolicyErrorResponse(t *testing.T) {
+	u := newPolicyUsecase(config.BookingPolicy{MaxPendingPerUser: 1})
+	app := setupTestApp(handler.NewBookingHandler(u))
+
+	post := func() (int, dto.ErrorResponse) {
+		body, _ := json.Marshal(dto.BookingRequest{UserID: 42, ServiceID: 1, Price: thb("100")})
+		req := httptest.NewRequest("POST", "/api/bookings", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		var errRes dto.ErrorResponse
+		json.NewDecoder(resp.Body).Decode(&errRes)
+		return resp.StatusCode, errRes
+	}
+
+	status, _ := post()
+	assert.Equal(t, fiber.StatusCreated, status)
+
+	status, errRes := post()
+	assert.Equal(t, fiber.StatusConflict, status)
+	assert.Equal(t, "booking-001", errRes.Code)
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/middleware"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBookingUsecaseWithConfig(cfg *config.Config) usecase.BookingUsecase {
+	return newBookingUsecaseWithProvider(cfg, utils.NewFakePaymentProvider())
+}
+
+func newBookingUsecaseWithProvider(cfg *config.Config, provider utils.PaymentProvider) usecase.BookingUsecase {
+	return usecase.NewTenant(cfg, provider).Bookings
+}
+
+func newTestBookingUsecase() usecase.BookingUsecase {
+	return newBookingUsecaseWithConfig(&config.Config{})
+}
+
+// authenticated stands in for JwtAuth, the requests are made with claims
+func authenticated(claims *utils.Claims) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("claims", &utils.AuthMapClaims{Claims: claims})
+		return c.Next()
+	}
+}
+
+func TestCreateBooking_CustomerUsesTokenUserID(t *testing.T) {
+	u := newTestBookingUsecase()
+	customer := &utils.Claims{Id: 7, Role: utils.RoleCustomer}
+
+	booking, err := u.CreateBooking(customer, dto.BookingRequest{UserID: 1, ServiceID: 2, Price: thb("1000")})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, booking.UserID)
+}
+
+func TestGetBookingByID_CustomerCannotReadOthers(t *testing.T) {
+	u := newTestBookingUsecase()
+
+	_, err := u.GetBookingByID(&utils.Claims{Id: 2}, 1)
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+
+	booking, err := u.GetBookingByID(&utils.Claims{Id: 1}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, booking.UserID)
+
+	_, err = u.GetBookingByID(&utils.Claims{Id: 2, Role: utils.RoleStaff}, 1)
+	assert.NoError(t, err)
+}
+
+func TestBookings_AnonymousCallersAreForbidden(t *testing.T) {
+	u := newTestBookingUsecase()
+
+	_, err := u.GetBookingByID(nil, 1)
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+	_, err = u.GetAllBookings(nil, "id", "")
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+	_, err = u.CreateBooking(nil, dto.BookingRequest{UserID: 1, ServiceID: 2, Price: thb("1000")})
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+	assert.ErrorIs(t, u.CancelBooking(nil, 1), usecase.ErrForbidden)
+	_, err = u.GetBookingHistory(nil, 1)
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+
+	_, err = u.GetBookingByID(utils.SystemClaims, 1)
+	assert.NoError(t, err)
+}
+
+func TestGetAllBookings_CustomerSeesOwnOnly(t *testing.T) {
+	u := newTestBookingUsecase()
+
+	bookings, err := u.GetAllBookings(&utils.Claims{Id: 3}, "id", "")
+	assert.NoError(t, err)
+	assert.Len(t, bookings, 1)
+	assert.Equal(t, 3, bookings[0].UserID)
+
+	bookings, err = u.GetAllBookings(&utils.Claims{Id: 3, Role: utils.RoleStaff}, "id", "")
+	assert.NoError(t, err)
+	assert.Len(t, bookings, 10)
+}
+
+func TestCancelBooking_CustomerCannotCancelOthers(t *testing.T) {
+	u := newTestBookingUsecase()
+
+	err := u.CancelBooking(&utils.Claims{Id: 2}, 1)
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+
+	err = u.CancelBooking(&utils.Claims{Id: 1}, 1)
+	assert.NoError(t, err)
+}
+
+func TestUpdateBooking_OnlyStaffOverridesStatus(t *testing.T) {
+	u := newTestBookingUsecase()
+
+	err := u.UpdateBooking(&utils.Claims{Id: 1}, 1, "confirmed")
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+
+	err = u.UpdateBooking(&utils.Claims{Id: 9, Role: utils.RoleStaff}, 1, "unknown")
+	assert.ErrorIs(t, err, usecase.ErrInvalidStatus)
+
+	err = u.UpdateBooking(&utils.Claims{Id: 9, Role: utils.RoleStaff}, 1, "confirmed")
+	assert.NoError(t, err)
+
+	booking, _ := u.GetBookingByID(utils.SystemClaims, 1)
+	assert.Equal(t, "confirmed", booking.Status)
+}
+
+func TestRequireRole(t *testing.T) {
+	auth := middleware.NewAuthMiddleware(&config.Config{}, nil, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if role := c.Get("X-Test-Role"); role != "" {
+			c.Locals("claims", &utils.AuthMapClaims{Claims: &utils.Claims{Id: 1, Role: role}})
+		}
+		return c.Next()
+	})
+	app.Get("/staff", auth.RequireRole(utils.RoleStaff, utils.RoleAdmin), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	cases := map[string]int{
+		"":                 fiber.StatusUnauthorized,
+		utils.RoleCustomer: fiber.StatusForbidden,
+		utils.RoleStaff:    fiber.StatusOK,
+		utils.RoleAdmin:    fiber.StatusOK,
+	}
+	for role, expected := range cases {
+		req := httptest.NewRequest("GET", "/staff", nil)
+		req.Header.Set("X-Test-Role", role)
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, resp.StatusCode, role)
+	}
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func slotIn(d time.Duration) string {
+	return time.Now().Add(d).Truncate(time.Hour).Format(time.RFC3339)
+}
+
+func TestRescheduleBooking_MovesToFreeSlot(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	owner := &utils.Claims{Id: 100}
+
+	booking, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 1, Price: thb("1000"), StartAt: slotIn(48 * time.Hour)})
+	require.NoError(t, err)
+	assert.NotEmpty(t, booking.EndAt)
+
+	// fill the target slot, service 1 takes 5 bookings per slot
+	full := slotIn(72 * time.Hour)
+	for i := 0; i < 5; i++ {
+		_, err := u.CreateBooking(&utils.Claims{Id: 200 + i}, dto.BookingRequest{ServiceID: 1, Price: thb("1000"), StartAt: full})
+		require.NoError(t, err)
+	}
+	_, err = u.CreateBooking(&utils.Claims{Id: 300}, dto.BookingRequest{ServiceID: 1, Price: thb("1000"), StartAt: full})
+	assert.ErrorIs(t, err, usecase.ErrSlotFull)
+
+	_, err = u.RescheduleBooking(owner, booking.ID, dto.RescheduleRequest{StartAt: full})
+	assert.ErrorIs(t, err, usecase.ErrSlotFull)
+
+	free := slotIn(96 * time.Hour)
+	moved, err := u.RescheduleBooking(owner, booking.ID, dto.RescheduleRequest{StartAt: free, Reason: "holiday"})
+	require.NoError(t, err)
+	assert.Equal(t, free, moved.StartAt.Format(time.RFC3339))
+	assert.Equal(t, 1, moved.RescheduleCount)
+
+	history, err := u.GetBookingHistory(owner, booking.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "rescheduled", history[1].Action)
+	assert.Equal(t, "holiday", history[1].Reason)
+	assert.Equal(t, free, history[1].Changes["start_at"].To)
+}
+
+func TestRescheduleBooking_ChangeServiceRepricesAndChargesFee(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{
+		ReschedulePolicy: config.ReschedulePolicy{Fee: thb("150"), FreeBefore: 24 * time.Hour, MinNotice: 2 * time.Hour},
+	})
+	owner := &utils.Claims{Id: 100}
+
+	booking, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 1, Price: thb("1000"), StartAt: slotIn(10 * time.Hour)})
+	require.NoError(t, err)
+
+	moved, err := u.RescheduleBooking(owner, booking.ID, dto.RescheduleRequest{ServiceID: 3})
+	require.NoError(t, err)
+	assert.Equal(t, 3, moved.ServiceID)
+	assert.Equal(t, thb("3000"), moved.Price)
+	assert.Equal(t, thb("150"), moved.RescheduleFees)
+}
+
+func TestRescheduleBooking_Rules(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{
+		ReschedulePolicy: config.ReschedulePolicy{MinNotice: 2 * time.Hour, MaxReschedules: 1},
+	})
+	owner := &utils.Claims{Id: 100}
+
+	soon, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 1, Price: thb("1000"), StartAt: slotIn(2 * time.Hour)})
+	require.NoError(t, err)
+	_, err = u.RescheduleBooking(owner, soon.ID, dto.RescheduleRequest{StartAt: slotIn(48 * time.Hour)})
+	assert.ErrorIs(t, err, usecase.ErrRescheduleNotAllowed)
+
+	later, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 1, Price: thb("1000"), StartAt: slotIn(48 * time.Hour)})
+	require.NoError(t, err)
+	_, err = u.RescheduleBooking(owner, later.ID, dto.RescheduleRequest{StartAt: slotIn(72 * time.Hour)})
+	require.NoError(t, err)
+	_, err = u.RescheduleBooking(owner, later.ID, dto.RescheduleRequest{StartAt: slotIn(96 * time.Hour)})
+	assert.ErrorIs(t, err, usecase.ErrRescheduleNotAllowed)
+
+	_, err = u.RescheduleBooking(&utils.Claims{Id: 101}, later.ID, dto.RescheduleRequest{StartAt: slotIn(96 * time.Hour)})
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+
+	require.NoError(t, u.CancelBooking(owner, soon.ID))
+	_, err = u.RescheduleBooking(owner, soon.ID, dto.RescheduleRequest{StartAt: slotIn(96 * time.Hour)})
+	assert.ErrorIs(t, err, usecase.ErrRescheduleNotAllowed)
+}
@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nextMonday is 10:00 UTC on a Monday at least a week away
+func nextMonday() time.Time {
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 7)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day.Add(10 * time.Hour)
+}
+
+func TestRRule_Occurrences(t *testing.T) {
+	monday := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	rule, err := utils.ParseRRule("RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4")
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		monday, monday.AddDate(0, 0, 2), monday.AddDate(0, 0, 7), monday.AddDate(0, 0, 9),
+	}, rule.Occurrences(monday, 100))
+
+	rule, err = utils.ParseRRule("FREQ=DAILY;INTERVAL=2;UNTIL=20240105")
+	require.NoError(t, err)
+	assert.Len(t, rule.Occurrences(monday, 100), 3)
+
+	// months without a 31st are skipped
+	rule, err = utils.ParseRRule("FREQ=MONTHLY;COUNT=3")
+	require.NoError(t, err)
+	jan31 := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, []time.Time{jan31, jan31.AddDate(0, 2, 0), jan31.AddDate(0, 4, 0)}, rule.Occurrences(jan31, 100))
+
+	for _, invalid := range []string{"FREQ=YEARLY", "COUNT=3", "FREQ=DAILY;COUNT=2;UNTIL=20240105", "FREQ=DAILY;BYDAY=MO", "FREQ=WEEKLY;BYSETPOS=1"} {
+		_, err := utils.ParseRRule(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestBookingSeries_ReportsConflictsPerOccurrence(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	owner := &utils.Claims{Id: 200}
+	start := nextMonday()
+
+	// the second week is full
+	fillSlot(t, u, 1, start.AddDate(0, 0, 7).Format(time.RFC3339))
+
+	series, err := u.CreateBookingSeries(owner, dto.BookingSeriesRequest{
+		ServiceID:  1,
+		StartAt:    start.Format(time.RFC3339),
+		RRule:      "FREQ=WEEKLY;COUNT=4",
+		Exceptions: []string{start.AddDate(0, 0, 21).Format(time.RFC3339)},
+	})
+	require.NoError(t, err)
+	require.Len(t, series.Bookings, 2)
+	assert.Equal(t, series.ID, series.Bookings[0].SeriesID)
+	require.Len(t, series.Conflicts, 1)
+	assert.Equal(t, start.AddDate(0, 0, 7), series.Conflicts[0].StartAt)
+
+	_, err = u.CreateBookingSeries(owner, dto.BookingSeriesRequest{ServiceID: 1, StartAt: start.Format(time.RFC3339), RRule: "FREQ=WEEKLY"})
+	assert.ErrorIs(t, err, usecase.ErrInvalidSeries)
+	_, err = u.GetBookingSeries(&utils.Claims{Id: 201}, series.ID)
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+}
+
+func TestBookingSeries_ExpandsInTheServiceTimezone(t *testing.T) {
+	bangkok, err := time.LoadLocation("Asia/Bangkok")
+	require.NoError(t, err)
+	u := newBookingUsecaseWithConfig(&config.Config{Timezones: config.TimezoneSettings{Default: bangkok}})
+	// 06:00 on Tuesday in Bangkok is still Monday in UTC
+	start := nextMonday().Add(13 * time.Hour)
+
+	series, err := u.CreateBookingSeries(&utils.Claims{Id: 200}, dto.BookingSeriesRequest{
+		ServiceID: 1,
+		StartAt:   start.Format(time.RFC3339),
+		RRule:     "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=2",
+	})
+	require.NoError(t, err)
+	require.Len(t, series.Bookings, 2)
+	assert.Equal(t, start, series.Bookings[0].StartAt.UTC())
+	assert.Equal(t, start.AddDate(0, 0, 2), series.Bookings[1].StartAt.UTC())
+	assert.Equal(t, time.Thursday, series.Bookings[1].StartAt.In(bangkok).Weekday())
+}
+
+func TestBookingSeries_IsOneBookingForThePolicy(t *testing.T) {
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	u := newBookingUsecaseWithConfig(cfg)
+	customer := &utils.Claims{Id: 300, Role: utils.RoleCustomer}
+	start := nextMonday()
+
+	// more occurrences than the pending, velocity and daily spend limits allow
+	series, err := u.CreateBookingSeries(customer, dto.BookingSeriesRequest{
+		ServiceID: 1,
+		StartAt:   start.Format(time.RFC3339),
+		RRule:     "FREQ=WEEKLY;COUNT=10",
+	})
+	require.NoError(t, err)
+	assert.Len(t, series.Bookings, 10)
+	assert.Empty(t, series.Conflicts)
+
+	// the series still counts towards the limits of the next booking
+	_, err = u.CreateBooking(customer, dto.BookingRequest{ServiceID: 1, StartAt: start.Add(2 * time.Hour).Format(time.RFC3339)})
+	require.NoError(t, err)
+
+	cfg.BookingPolicy.BlockedUsers = []int{301}
+	_, err = u.CreateBookingSeries(&utils.Claims{Id: 301}, dto.BookingSeriesRequest{
+		ServiceID: 1,
+		StartAt:   start.Format(time.RFC3339),
+		RRule:     "FREQ=WEEKLY;COUNT=2",
+	})
+	var policyErr *usecase.PolicyError
+	require.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, usecase.BlockedUserErr, policyErr.Code)
+}
+
+func TestBookingSeries_ThisAndFollowing(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	owner := &utils.Claims{Id: 200}
+	start := nextMonday()
+
+	series, err := u.CreateBookingSeries(owner, dto.BookingSeriesRequest{
+		ServiceID: 1,
+		StartAt:   start.Format(time.RFC3339),
+		RRule:     "FREQ=WEEKLY;COUNT=3",
+	})
+	require.NoError(t, err)
+	require.Len(t, series.Bookings, 3)
+
+	// move the second and third occurrence an hour later
+	second := start.AddDate(0, 0, 7)
+	notes := "moved"
+	updated, err := u.UpdateBookingSeries(owner, series.ID, dto.BookingSeriesUpdate{
+		From:    second.Format(time.RFC3339),
+		StartAt: second.Add(time.Hour).Format(time.RFC3339),
+		Notes:   &notes,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, updated.Conflicts)
+	assert.Equal(t, start, updated.Bookings[0].StartAt)
+	assert.Empty(t, updated.Bookings[0].Notes)
+	assert.Equal(t, second.Add(time.Hour), updated.Bookings[1].StartAt)
+	assert.Equal(t, start.AddDate(0, 0, 14).Add(time.Hour), updated.Bookings[2].StartAt)
+	assert.Equal(t, "moved", updated.Bookings[2].Notes)
+
+	canceled, err := u.CancelBookingSeries(owner, series.ID, second.Format(time.RFC3339))
+	require.NoError(t, err)
+	assert.Equal(t, models.SeriesActive, canceled.Status)
+	assert.Equal(t, models.StatusPending, canceled.Bookings[0].Status)
+	assert.Equal(t, models.StatusCanceled, canceled.Bookings[1].Status)
+	assert.Equal(t, models.StatusCanceled, canceled.Bookings[2].Status)
+
+	canceled, err = u.CancelBookingSeries(owner, series.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, models.SeriesCanceled, canceled.Status)
+	assert.Equal(t, models.StatusCanceled, canceled.Bookings[0].Status)
+}
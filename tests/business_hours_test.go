@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBusinessHoursUsecases(t *testing.T) (usecase.BookingUsecase, usecase.BusinessHoursUsecase, *time.Location) {
+	bangkok, err := time.LoadLocation("Asia/Bangkok")
+	require.NoError(t, err)
+	tenant := usecase.NewTenant(&config.Config{Timezones: config.TimezoneSettings{Default: bangkok}}, utils.NewFakePaymentProvider())
+	return tenant.Bookings, tenant.BusinessHours, bangkok
+}
+
+// mondayIn is the Monday at least a week from now in location
+func mondayIn(location *time.Location) time.Time {
+	now := time.Now().In(location)
+	day := time.Date(now.Year(), now.Month(), now.Day()+7, 0, 0, 0, 0, location)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day
+}
+
+func at(day time.Time, hour, minute int) string {
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location()).Format(time.RFC3339)
+}
+
+func TestBusinessHours_RejectsSlotsOutsideOpenHours(t *testing.T) {
+	bookings, hours, bangkok := newBusinessHoursUsecases(t)
+	monday := mondayIn(bangkok)
+	weekday := []dto.OpeningHours{{Open: "09:00", Close: "12:00"}, {Open: "13:00", Close: "18:00"}}
+	_, err := hours.SetBusinessHours(1, dto.BusinessHoursRequest{Weekly: map[string][]dto.OpeningHours{
+		"monday": weekday, "tuesday": weekday, "wednesday": weekday, "thursday": weekday, "friday": weekday,
+	}})
+	require.NoError(t, err)
+
+	book := func(startAt, duration string) (*dto.BookingResponse, error) {
+		return bookings.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1, StartAt: startAt, Duration: duration})
+	}
+	booking, err := book(at(monday, 9, 0), "")
+	require.NoError(t, err)
+	_, err = book(at(monday, 17, 0), "")
+	assert.NoError(t, err)
+
+	_, err = book(at(monday, 12, 0), "")
+	assert.ErrorIs(t, err, usecase.ErrServiceClosed)
+	assert.Contains(t, err.Error(), "open 09:00-12:00, 13:00-18:00 on Monday "+monday.Format("2006-01-02"))
+	_, err = book(at(monday, 11, 0), "2h")
+	assert.ErrorIs(t, err, usecase.ErrServiceClosed)
+	_, err = book(at(monday, 17, 30), "")
+	assert.ErrorIs(t, err, usecase.ErrServiceClosed)
+	_, err = book(at(monday.AddDate(0, 0, -1), 10, 0), "")
+	assert.ErrorIs(t, err, usecase.ErrServiceClosed)
+	assert.Contains(t, err.Error(), "closed on Sunday")
+	// services without business hours are open all the time
+	_, err = bookings.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 2, StartAt: at(monday.AddDate(0, 0, -1), 3, 0)})
+	assert.NoError(t, err)
+
+	_, err = bookings.RescheduleBooking(utils.SystemClaims, booking.ID, dto.RescheduleRequest{StartAt: at(monday, 20, 0)})
+	assert.ErrorIs(t, err, usecase.ErrServiceClosed)
+	_, err = bookings.JoinWaitlist(&utils.Claims{Id: 200}, dto.WaitlistRequest{ServiceID: 1, StartAt: at(monday, 12, 0)})
+	assert.ErrorIs(t, err, usecase.ErrServiceClosed)
+
+	// a holiday for every service, and special hours for one date
+	tuesday := monday.AddDate(0, 0, 1)
+	_, err = hours.CreateHoursOverride(dto.HoursOverrideRequest{Date: monday.Format("2006-01-02"), Reason: "Chulalongkorn Day"})
+	require.NoError(t, err)
+	_, err = hours.CreateHoursOverride(dto.HoursOverrideRequest{ServiceID: 1, Date: tuesday.Format("2006-01-02"), Hours: []dto.OpeningHours{{Open: "10:00", Close: "14:00"}}})
+	require.NoError(t, err)
+
+	_, err = book(at(monday, 10, 0), "")
+	assert.ErrorIs(t, err, usecase.ErrServiceClosed)
+	assert.Contains(t, err.Error(), "for Chulalongkorn Day")
+	_, err = bookings.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 2, StartAt: at(monday, 10, 0)})
+	assert.ErrorIs(t, err, usecase.ErrServiceClosed)
+	_, err = book(at(tuesday, 12, 0), "")
+	assert.NoError(t, err)
+	_, err = book(at(tuesday, 9, 0), "")
+	assert.ErrorIs(t, err, usecase.ErrServiceClosed)
+
+	schedule, err := hours.GetSchedule(1, monday.AddDate(0, 0, -1).Format("2006-01-02"), tuesday.AddDate(0, 0, 1).Format("2006-01-02"))
+	require.NoError(t, err)
+	require.Len(t, schedule.Days, 4)
+	assert.True(t, schedule.Days[0].Closed)
+	assert.Equal(t, dto.ScheduleDay{Date: monday.Format("2006-01-02"), Closed: true, Hours: []dto.OpeningHours{}, Reason: "Chulalongkorn Day"}, schedule.Days[1])
+	assert.Equal(t, []dto.OpeningHours{{Open: "10:00", Close: "14:00"}}, schedule.Days[2].Hours)
+	assert.Equal(t, weekday, schedule.Days[3].Hours)
+
+	_, err = hours.SetBusinessHours(1, dto.BusinessHoursRequest{Weekly: map[string][]dto.OpeningHours{"monday": {{Open: "09:00", Close: "12:00"}, {Open: "11:00", Close: "13:00"}}}})
+	assert.ErrorIs(t, err, usecase.ErrInvalidBusinessHours)
+	_, err = hours.SetBusinessHours(1, dto.BusinessHoursRequest{Weekly: map[string][]dto.OpeningHours{"someday": weekday}})
+	assert.ErrorIs(t, err, usecase.ErrInvalidBusinessHours)
+}
+
+func TestBusinessHours_ImportsHolidaysFromICS(t *testing.T) {
+	bookings, hours, bangkok := newBusinessHoursUsecases(t)
+	monday := mondayIn(bangkok)
+	date := func(day time.Time) string {
+		return day.Format("20060102")
+	}
+	calendar := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nUID:songkran@example.com\r\nDTSTART;VALUE=DATE:" + date(monday) + "\r\nDTEND;VALUE=DATE:" + date(monday.AddDate(0, 0, 2)) + "\r\n" +
+		"SUMMARY:Songkran\\, the Thai New\r\n  Year\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:meeting@example.com\r\nDTSTART;TZID=Asia/Bangkok:" + date(monday) + "T100000\r\nSUMMARY:Meeting\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:yearly@example.com\r\nDTSTART;VALUE=DATE:" + date(monday.AddDate(0, 0, 4)) + "\r\nRRULE:FREQ=YEARLY\r\nSUMMARY:Yearly\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:old@example.com\r\nDTSTART;VALUE=DATE:20200101\r\nSUMMARY:Old\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	res, err := hours.ImportHolidays(0, calendar)
+	require.NoError(t, err)
+	require.Len(t, res.Imported, 2)
+	assert.Equal(t, monday.Format("2006-01-02"), res.Imported[0].Date)
+	assert.Equal(t, "Songkran, the Thai New Year", res.Imported[0].Reason)
+	assert.Equal(t, "ics", res.Imported[0].Source)
+	assert.Len(t, res.Skipped, 3)
+
+	// importing the file again replaces the closures
+	_, err = hours.ImportHolidays(0, calendar)
+	require.NoError(t, err)
+	assert.Len(t, hours.GetHoursOverrides(0), 2)
+
+	_, err = bookings.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 3, StartAt: at(monday.AddDate(0, 0, 1), 10, 0)})
+	assert.ErrorIs(t, err, usecase.ErrServiceClosed)
+	assert.Contains(t, err.Error(), "Songkran")
+	_, err = bookings.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 3, StartAt: at(monday.AddDate(0, 0, 2), 10, 0)})
+	assert.NoError(t, err)
+
+	_, err = hours.ImportHolidays(0, "not a calendar")
+	assert.ErrorIs(t, err, usecase.ErrInvalidHolidayCalendar)
+}
+
+func TestParseICalendar_ReadsWhatBuildICalendarWrites(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	start := time.Date(2030, time.July, 1, 10, 0, 0, 0, newYork)
+	event := utils.ICalEvent{
+		UID:         "booking-1@example.com",
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Summary:     "Fiber install, floor 2; rack B",
+		Description: "ติดตั้งไฟเบอร์ " + "\n" + "second line",
+		Status:      utils.ICalConfirmed,
+	}
+
+	events, err := utils.ParseICalendar(utils.BuildICalendar("Bookings", newYork, []utils.ICalEvent{event}))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.True(t, start.Equal(events[0].Start))
+	assert.True(t, start.Add(time.Hour).Equal(events[0].End))
+	assert.Equal(t, event.Summary, events[0].Summary)
+	assert.Equal(t, event.Description, events[0].Description)
+	assert.Equal(t, utils.ICalConfirmed, events[0].Status)
+	assert.False(t, events[0].AllDay)
+}
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/handler"
+	"github.com/Eursukkul/fiber-booking-system/mocks"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCalendarUsecase(t *testing.T) usecase.BookingUsecase {
+	bangkok, err := time.LoadLocation("Asia/Bangkok")
+	require.NoError(t, err)
+	return newBookingUsecaseWithConfig(&config.Config{
+		Calendar: config.CalendarSettings{Name: "Bookings", Domain: "example.com", Location: bangkok},
+	})
+}
+
+func TestCalendar_BookingFollowsRescheduleAndCancel(t *testing.T) {
+	u := newCalendarUsecase(t)
+	owner := &utils.Claims{Id: 100}
+	start := time.Date(2030, 3, 4, 3, 0, 0, 0, time.UTC)
+
+	booking, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 1, StartAt: start.Format(time.RFC3339)})
+	require.NoError(t, err)
+
+	ics, err := u.GetBookingCalendar(owner, booking.ID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n"))
+	assert.Contains(t, ics, "TZID:Asia/Bangkok\r\n")
+	assert.Contains(t, ics, "TZOFFSETTO:+0700\r\n")
+	assert.Contains(t, ics, fmt.Sprintf("UID:booking-%d@example.com\r\n", booking.ID))
+	assert.Contains(t, ics, "DTSTART;TZID=Asia/Bangkok:20300304T100000\r\n")
+	assert.Contains(t, ics, "SEQUENCE:0\r\n")
+	assert.Contains(t, ics, "STATUS:TENTATIVE\r\n")
+
+	_, err = u.RescheduleBooking(owner, booking.ID, dto.RescheduleRequest{StartAt: start.Add(2 * time.Hour).Format(time.RFC3339)})
+	require.NoError(t, err)
+	ics, err = u.GetBookingCalendar(owner, booking.ID)
+	require.NoError(t, err)
+	assert.Contains(t, ics, "DTSTART;TZID=Asia/Bangkok:20300304T120000\r\n")
+	assert.Contains(t, ics, "SEQUENCE:1\r\n")
+
+	require.NoError(t, u.CancelBooking(owner, booking.ID))
+	ics, err = u.GetBookingCalendar(owner, booking.ID)
+	require.NoError(t, err)
+	assert.Contains(t, ics, "SEQUENCE:2\r\n")
+	assert.Contains(t, ics, "STATUS:CANCELLED\r\n")
+
+	_, err = u.GetBookingCalendar(&utils.Claims{Id: 101}, booking.ID)
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+
+	unscheduled, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 1})
+	require.NoError(t, err)
+	_, err = u.GetBookingCalendar(owner, unscheduled.ID)
+	assert.ErrorIs(t, err, usecase.ErrNoSlot)
+}
+
+func TestCalendar_FeedIsTokenized(t *testing.T) {
+	u := newCalendarUsecase(t)
+	owner := &utils.Claims{Id: 100}
+
+	later, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 1, StartAt: slotIn(72 * time.Hour)})
+	require.NoError(t, err)
+	sooner, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 1, StartAt: slotIn(48 * time.Hour)})
+	require.NoError(t, err)
+	_, err = u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 101, ServiceID: 1, StartAt: slotIn(48 * time.Hour)})
+	require.NoError(t, err)
+
+	// customers cannot create the feed of someone else
+	feed, err := u.CreateCalendarFeed(owner, dto.CalendarFeedRequest{UserID: 101})
+	require.NoError(t, err)
+	assert.Equal(t, 100, feed.UserID)
+	assert.Equal(t, "/api/calendar/"+feed.Token+".ics", feed.URL)
+
+	ics, err := u.GetCalendarFeed(feed.Token)
+	require.NoError(t, err)
+	assert.Contains(t, ics, "X-WR-CALNAME:Bookings\r\n")
+	assert.Equal(t, 2, strings.Count(ics, "BEGIN:VEVENT"))
+	// events are ordered by start
+	assert.Less(t, strings.Index(ics, fmt.Sprintf("UID:booking-%d@", sooner.ID)), strings.Index(ics, fmt.Sprintf("UID:booking-%d@", later.ID)))
+
+	// a new feed revokes the old token
+	renewed, err := u.CreateCalendarFeed(owner, dto.CalendarFeedRequest{})
+	require.NoError(t, err)
+	_, err = u.GetCalendarFeed(feed.Token)
+	assert.ErrorIs(t, err, usecase.ErrCalendarFeedNotFound)
+	_, err = u.GetCalendarFeed(renewed.Token)
+	assert.NoError(t, err)
+
+	_, err = u.CreateCalendarFeed(utils.SystemClaims, dto.CalendarFeedRequest{})
+	assert.ErrorIs(t, err, usecase.ErrInvalidCalendarFeed)
+
+	// anonymous callers cannot revoke the feed of the owner
+	_, err = u.CreateCalendarFeed(nil, dto.CalendarFeedRequest{UserID: 100})
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+	_, err = u.CreateCalendarFeed(&utils.Claims{Id: 101}, dto.CalendarFeedRequest{UserID: 100})
+	require.NoError(t, err)
+	_, err = u.GetCalendarFeed(renewed.Token)
+	assert.NoError(t, err)
+}
+
+func TestBuildICalendar_DaylightSavingAndFolding(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	start := time.Date(2030, 7, 1, 14, 0, 0, 0, time.UTC)
+
+	ics := utils.BuildICalendar("", newYork, []utils.ICalEvent{{
+		UID:         "booking-1@example.com",
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Summary:     "Fiber install, floor 2; rack B",
+		Description: strings.Repeat("ติดตั้งไฟเบอร์ ", 10),
+		Status:      utils.ICalConfirmed,
+	}})
+
+	assert.Contains(t, ics, "DTSTART;TZID=America/New_York:20300701T100000\r\n")
+	assert.Contains(t, ics, "BEGIN:DAYLIGHT\r\nDTSTART:20300310T020000\r\nTZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\n")
+	assert.Contains(t, ics, "BEGIN:STANDARD\r\nDTSTART:20301103T020000\r\nTZOFFSETFROM:-0400\r\nTZOFFSETTO:-0500\r\n")
+	assert.Contains(t, ics, `SUMMARY:Fiber install\, floor 2\; rack B`)
+	for _, line := range strings.Split(ics, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Contains(t, strings.ReplaceAll(ics, "\r\n ", ""), "DESCRIPTION:"+strings.Repeat("ติดตั้งไฟเบอร์ ", 10))
+}
+
+func TestGetCalendarFeed_Route(t *testing.T) {
+	mockUsecase := new(mocks.MockBookingUsecase)
+	app := fiber.New()
+	app.Get("/api/calendar/:token.ics", handler.NewBookingHandler(mockUsecase).GetCalendarFeed)
+
+	mockUsecase.On("GetCalendarFeed", "cal_abc").Return("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n", nil)
+	mockUsecase.On("GetCalendarFeed", "cal_old").Return("", usecase.ErrCalendarFeedNotFound)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/calendar/cal_abc.ics", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/calendar; charset=utf-8", resp.Header.Get(fiber.HeaderContentType))
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n", string(body))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/calendar/cal_old.ics", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
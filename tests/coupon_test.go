@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/repository"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCouponBookingUsecase() (usecase.BookingUsecase, usecase.CouponUsecase) {
+	tenant := usecase.NewTenant(&config.Config{}, utils.NewFakePaymentProvider())
+	return tenant.Bookings, tenant.Coupons
+}
+
+func TestCouponUsecase_Validation(t *testing.T) {
+	_, coupons := newCouponBookingUsecase()
+
+	created, err := coupons.CreateCoupon(dto.CouponRequest{Code: " summer10 ", Type: "percent", Percent: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "SUMMER10", created.Code)
+
+	_, err = coupons.CreateCoupon(dto.CouponRequest{Code: "SUMMER10", Type: "fixed", Amount: thb("100")})
+	assert.ErrorIs(t, err, usecase.ErrCouponCodeTaken)
+
+	_, err = coupons.CreateCoupon(dto.CouponRequest{Code: "HALF", Type: "percent", Percent: 150})
+	assert.ErrorIs(t, err, usecase.ErrInvalidCouponRequest)
+
+	_, err = coupons.CreateCoupon(dto.CouponRequest{Code: "LATE", Type: "fixed", Amount: thb("100"), ValidFrom: "2030-02-01T00:00:00Z", ValidUntil: "2030-01-01T00:00:00Z"})
+	assert.ErrorIs(t, err, usecase.ErrInvalidCouponRequest)
+
+	assert.ErrorIs(t, coupons.DeleteCoupon(99), usecase.ErrCouponNotFound)
+}
+
+func TestCreateBooking_CouponLimitsAndRelease(t *testing.T) {
+	bookings, coupons := newCouponBookingUsecase()
+	coupon, err := coupons.CreateCoupon(dto.CouponRequest{
+		Code:           "SUMMER10",
+		Type:           "percent",
+		Percent:        10,
+		MaxRedemptions: 2,
+		MaxPerUser:     1,
+		ServiceIDs:     []int{1},
+	})
+	require.NoError(t, err)
+
+	first, err := bookings.CreateBooking(&utils.Claims{Id: 100}, dto.BookingRequest{ServiceID: 1, CouponCode: "summer10"})
+	require.NoError(t, err)
+	assert.Equal(t, thb("900"), first.Price)
+	assert.Equal(t, thb("100"), first.Discount)
+	assert.Equal(t, "SUMMER10", first.CouponCode)
+
+	_, err = bookings.CreateBooking(&utils.Claims{Id: 100}, dto.BookingRequest{ServiceID: 1, CouponCode: "SUMMER10"})
+	assert.ErrorIs(t, err, usecase.ErrCouponUsedUp)
+
+	_, err = bookings.CreateBooking(&utils.Claims{Id: 101}, dto.BookingRequest{ServiceID: 2, CouponCode: "SUMMER10"})
+	assert.ErrorIs(t, err, usecase.ErrCouponInvalid)
+
+	_, err = bookings.CreateBooking(&utils.Claims{Id: 101}, dto.BookingRequest{ServiceID: 1, CouponCode: "SUMMER10"})
+	require.NoError(t, err)
+	_, err = bookings.CreateBooking(&utils.Claims{Id: 102}, dto.BookingRequest{ServiceID: 1, CouponCode: "SUMMER10"})
+	assert.ErrorIs(t, err, usecase.ErrCouponUsedUp)
+
+	// canceling gives the redemption back
+	require.NoError(t, bookings.CancelBooking(&utils.Claims{Id: 100}, first.ID))
+	_, err = bookings.CreateBooking(&utils.Claims{Id: 102}, dto.BookingRequest{ServiceID: 1, CouponCode: "SUMMER10"})
+	require.NoError(t, err)
+
+	stored, err := coupons.GetCoupon(coupon.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.Redeemed)
+}
+
+func TestCreateBooking_ExpiredCoupon(t *testing.T) {
+	bookings, coupons := newCouponBookingUsecase()
+	_, err := coupons.CreateCoupon(dto.CouponRequest{
+		Code:       "OLD",
+		Type:       "fixed",
+		Amount:     thb("300"),
+		ValidUntil: time.Now().Add(-time.Hour).Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+
+	_, err = bookings.CreateBooking(&utils.Claims{Id: 100}, dto.BookingRequest{ServiceID: 1, CouponCode: "OLD"})
+	assert.ErrorIs(t, err, usecase.ErrCouponInvalid)
+	_, err = bookings.CreateBooking(&utils.Claims{Id: 100}, dto.BookingRequest{ServiceID: 1, CouponCode: "NOPE"})
+	assert.ErrorIs(t, err, usecase.ErrCouponInvalid)
+}
+
+func TestCouponRepository_RedeemIsAtomic(t *testing.T) {
+	repo := repository.NewMockCouponRepository()
+	_, err := repo.Create(models.Coupon{Code: "TEN", Type: models.CouponFixed, Amount: thb("10"), MaxRedemptions: 10})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	redeemed := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(user int) {
+			defer wg.Done()
+			if _, err := repo.Redeem("TEN", user, 1, thb("1000"), time.Now()); err == nil {
+				mu.Lock()
+				redeemed++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, redeemed)
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDepositUsecase() usecase.BookingUsecase {
+	return newBookingUsecaseWithConfig(&config.Config{
+		HighValueThresholds: map[string]models.Money{"THB": thb("5000")},
+		CancellationPolicy:  config.CancellationPolicy{FreeBefore: 24 * time.Hour},
+		Deposit: config.DepositPolicy{
+			Percent:          30,
+			BalanceDueBefore: 72 * time.Hour,
+			BalanceDueWithin: 7 * 24 * time.Hour,
+		},
+	})
+}
+
+func TestDeposit_HighValueBookingPaysDepositThenBalance(t *testing.T) {
+	u := newDepositUsecase()
+	owner := &utils.Claims{Id: 100}
+	start := slotIn(10 * 24 * time.Hour)
+
+	// service 6 costs 6000 THB, above the threshold
+	booking, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 6, StartAt: start})
+	require.NoError(t, err)
+	assert.Equal(t, thb("1800"), booking.Deposit)
+	startAt, _ := time.Parse(time.RFC3339, start)
+	assert.Equal(t, startAt.Add(-72*time.Hour), booking.BalanceDueAt)
+
+	deposit, err := u.StartPayment(booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PaymentKindDeposit, deposit.Kind)
+	assert.Equal(t, thb("1800"), deposit.Amount)
+
+	paid, err := u.GetBookingByID(owner, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusDepositPaid, paid.Status)
+
+	ledger, err := u.GetPaymentLedger(owner, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, thb("1800"), ledger.Paid)
+	assert.Equal(t, thb("4200"), ledger.BalanceDue)
+
+	// the deposit is invoiced once captured, the balance once paid
+	invoice, err := u.GetInvoice(owner, booking.ID)
+	require.NoError(t, err)
+	require.Len(t, invoice.Invoices, 1)
+	assert.Equal(t, thb("1800"), invoice.Invoices[0].Total)
+	assert.Contains(t, invoice.Invoices[0].Lines[0].Description, "Deposit")
+
+	balance, err := u.PayBalance(owner, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PaymentKindBalance, balance.Kind)
+	assert.Equal(t, thb("4200"), balance.Amount)
+
+	confirmed, err := u.GetBookingByID(owner, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusConfirmed, confirmed.Status)
+	invoice, err = u.GetInvoice(owner, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, thb("6000"), invoice.Balance)
+
+	// canceling early refunds the balance and the deposit
+	require.NoError(t, u.CancelBooking(owner, booking.ID))
+	ledger, err = u.GetPaymentLedger(owner, booking.ID)
+	require.NoError(t, err)
+	require.Len(t, ledger.Entries, 4)
+	assert.Equal(t, models.LedgerRefund, ledger.Entries[2].Kind)
+	assert.Equal(t, models.PaymentKindBalance, ledger.Entries[2].PaymentKind)
+	assert.Equal(t, thb("4200"), ledger.Entries[2].Amount)
+	assert.Equal(t, thb("1800"), ledger.Entries[3].Amount)
+	assert.Equal(t, thb("6000"), ledger.Refunded)
+	assert.True(t, ledger.Paid.IsZero())
+}
+
+func TestDeposit_CancelingAfterDepositRefundsByPolicy(t *testing.T) {
+	u := newDepositUsecase()
+	owner := &utils.Claims{Id: 100}
+
+	// bookings without a slot cancel as if they start now, so the deposit is kept
+	booking, err := u.CreateBooking(owner, dto.BookingRequest{ServiceID: 6})
+	require.NoError(t, err)
+	assert.NotEmpty(t, booking.BalanceDueAt)
+	_, err = u.StartPayment(booking.ID)
+	require.NoError(t, err)
+
+	_, err = u.PayBalance(owner, 1)
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+
+	quote, err := u.QuoteCancellation(owner, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, thb("1800"), quote.Paid)
+	assert.Equal(t, thb("1800"), quote.Fee)
+
+	require.NoError(t, u.CancelBooking(owner, booking.ID))
+	_, err = u.PayBalance(owner, booking.ID)
+	assert.ErrorIs(t, err, usecase.ErrInvalidTransition)
+
+	// the kept deposit stays invoiced as the fee
+	invoice, err := u.GetInvoice(owner, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, thb("1800"), invoice.Balance)
+	assert.Empty(t, invoice.CreditNotes)
+}
+
+func TestDeposit_LowValueBookingIsPaidInFull(t *testing.T) {
+	u := newDepositUsecase()
+
+	booking, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1})
+	require.NoError(t, err)
+	assert.True(t, booking.Deposit.IsZero())
+	assert.Empty(t, booking.BalanceDueAt)
+
+	payment, err := u.StartPayment(booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PaymentKindFull, payment.Kind)
+
+	confirmed, err := u.GetBookingByID(utils.SystemClaims, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusConfirmed, confirmed.Status)
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/handler"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newInvoiceUsecase() usecase.BookingUsecase {
+	bangkok, _ := time.LoadLocation("Asia/Bangkok")
+	return newBookingUsecaseWithConfig(&config.Config{
+		VATRate:         7,
+		ServiceVATRates: map[int]float64{2: 0},
+		Invoice:         config.InvoiceSettings{IssuerName: "SPD", Location: bangkok},
+	})
+}
+
+func TestNewInvoiceLine_SplitsIncludedVAT(t *testing.T) {
+	line := models.NewInvoiceLine("booking", thb("1000"), 7)
+	assert.Equal(t, thb("65.42"), line.Tax)
+	assert.Equal(t, thb("934.58"), line.Net)
+	assert.Equal(t, thb("1000"), line.Total)
+}
+
+func TestInvoice_IssuedOnConfirmAndCreditedOnRefund(t *testing.T) {
+	u := newInvoiceUsecase()
+	staff := &utils.Claims{Id: 1, Role: utils.RoleStaff}
+
+	booking, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1})
+	require.NoError(t, err)
+	_, err = u.GetInvoice(utils.SystemClaims, booking.ID)
+	assert.ErrorIs(t, err, usecase.ErrInvoiceNotFound)
+
+	require.NoError(t, u.UpdateBooking(staff, booking.ID, models.StatusConfirmed))
+	invoice, err := u.GetInvoice(utils.SystemClaims, booking.ID)
+	require.NoError(t, err)
+	require.Len(t, invoice.Invoices, 1)
+	assert.Equal(t, "INV-000001", invoice.Invoices[0].Number)
+	assert.Equal(t, thb("65.42"), invoice.Invoices[0].Tax)
+	assert.Equal(t, thb("1000"), invoice.Balance)
+	assert.Contains(t, invoice.Invoices[0].IssuedAt, "+07:00")
+
+	// numbers continue across bookings, service 2 has no VAT
+	other, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 101, ServiceID: 2})
+	require.NoError(t, err)
+	require.NoError(t, u.UpdateBooking(staff, other.ID, models.StatusConfirmed))
+	otherInvoice, err := u.GetInvoice(utils.SystemClaims, other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "INV-000002", otherInvoice.Invoices[0].Number)
+	assert.True(t, otherInvoice.Invoices[0].Tax.IsZero())
+
+	require.NoError(t, u.CancelBooking(&utils.Claims{Id: 100}, booking.ID))
+	invoice, err = u.GetInvoice(utils.SystemClaims, booking.ID)
+	require.NoError(t, err)
+	require.Len(t, invoice.CreditNotes, 1)
+	assert.Equal(t, "CN-000001", invoice.CreditNotes[0].Number)
+	assert.Equal(t, "INV-000001", invoice.CreditNotes[0].RefersTo)
+	assert.Equal(t, thb("1000"), invoice.CreditNotes[0].Total)
+	assert.True(t, invoice.Balance.IsZero())
+
+	_, err = u.GetInvoice(&utils.Claims{Id: 999}, booking.ID)
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+}
+
+func TestGetInvoice_HandlerRendersHTML(t *testing.T) {
+	u := newInvoiceUsecase()
+	booking, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1})
+	require.NoError(t, err)
+	require.NoError(t, u.UpdateBookingStatus(booking.ID, models.StatusConfirmed))
+
+	app := fiber.New()
+	app.Use(authenticated(&utils.Claims{Id: 1, Role: utils.RoleStaff}))
+	app.Get("/api/bookings/:id/invoice", handler.NewBookingHandler(u).GetInvoice)
+	path := "/api/bookings/" + strconv.Itoa(booking.ID) + "/invoice"
+
+	resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), fiber.MIMEApplicationJSON)
+
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("Accept", "text/html")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, resp.Header.Get("Content-Type"), fiber.MIMETextHTML)
+	assert.Contains(t, string(body), "Tax Invoice INV-000001")
+	assert.Contains(t, string(body), "65.42 THB")
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/bookings/1/invoice", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/handler"
+	"github.com/Eursukkul/fiber-booking-system/router"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClaims(id int, role string) *utils.AuthMapClaims {
+	return &utils.AuthMapClaims{
+		Claims: &utils.Claims{Id: id, Role: role},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+// writeKeyFile writes a new PEM private key to dir/name.pem
+func writeKeyFile(t *testing.T, dir, name, alg string) crypto.Signer {
+	var (
+		signer crypto.Signer
+		err    error
+	)
+	if alg == "ES256" {
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	} else {
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name+".pem"), data, 0o600))
+	return signer
+}
+
+// signWith signs the claims the way an issuer sharing the key files does
+func signWith(t *testing.T, kid string, signer crypto.Signer, claims jwt.Claims) string {
+	method := jwt.SigningMethod(jwt.SigningMethodRS256)
+	if _, ok := signer.(*ecdsa.PrivateKey); ok {
+		method = jwt.SigningMethodES256
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(signer)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestKeySet_SignAndParse(t *testing.T) {
+	for _, alg := range []string{"RS256", "ES256"} {
+		dir := t.TempDir()
+		writeKeyFile(t, dir, "current", alg)
+		keys, err := utils.LoadKeySet(alg, []string{filepath.Join(dir, "current.pem")})
+		require.NoError(t, err)
+
+		token, err := keys.Sign(newTestClaims(5, utils.RoleStaff))
+		require.NoError(t, err)
+
+		claims, err := utils.ParseTokenWithKeySet(keys, token)
+		require.NoError(t, err, alg)
+		assert.Equal(t, 5, claims.Id)
+		assert.Equal(t, utils.RoleStaff, claims.Role)
+	}
+}
+
+func TestKeySet_ReloadPicksUpNewKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyFile(t, dir, "2024-01", "ES256")
+	keys, err := utils.LoadKeySet("ES256", []string{filepath.Join(dir, "*.pem")})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01", keys.Active().Kid)
+
+	oldToken, err := keys.Sign(newTestClaims(1, ""))
+	require.NoError(t, err)
+
+	// the issuer drops a newer key next to the old one
+	newSigner := writeKeyFile(t, dir, "2024-06", "ES256")
+	require.NoError(t, keys.Reload())
+	assert.Equal(t, "2024-06", keys.Active().Kid)
+
+	_, err = utils.ParseTokenWithKeySet(keys, oldToken)
+	assert.NoError(t, err)
+	_, err = utils.ParseTokenWithKeySet(keys, signWith(t, "2024-06", newSigner, newTestClaims(1, "")))
+	assert.NoError(t, err)
+	assert.Len(t, keys.JWKS().Keys, 2)
+}
+
+func TestKeySet_RejectsHMACAndUnknownKid(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyFile(t, dir, "ours", "RS256")
+	other := writeKeyFile(t, dir, "theirs", "RS256")
+	keys, err := utils.LoadKeySet("RS256", []string{filepath.Join(dir, "ours.pem")})
+	require.NoError(t, err)
+
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, newTestClaims(1, "")).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = utils.ParseTokenWithKeySet(keys, hmacToken)
+	assert.Error(t, err)
+
+	_, err = utils.ParseTokenWithKeySet(keys, signWith(t, "theirs", other, newTestClaims(1, "")))
+	assert.Error(t, err)
+	// a foreign key under a known kid fails on the signature
+	_, err = utils.ParseTokenWithKeySet(keys, signWith(t, "ours", other, newTestClaims(1, "")))
+	assert.Error(t, err)
+}
+
+func TestLoadKeySet_FromFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyFile(t, dir, "current", "ES256")
+	writeKeyFile(t, dir, "previous", "ES256")
+
+	keys, err := utils.LoadKeySet("ES256", []string{
+		filepath.Join(dir, "current.pem"),
+		filepath.Join(dir, "previous.pem"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "current", keys.Active().Kid)
+	assert.Equal(t, "ES256", keys.Active().Alg)
+
+	_, err = utils.LoadKeySet("ES256", nil)
+	assert.Error(t, err, "nothing could be verified without key files")
+}
+
+func TestKeySet_NeverDropsConfiguredKeys(t *testing.T) {
+	dir := t.TempDir()
+	signers := map[string]crypto.Signer{
+		"current":  writeKeyFile(t, dir, "current", "ES256"),
+		"previous": writeKeyFile(t, dir, "previous", "ES256"),
+	}
+	keys, err := utils.LoadKeySet("ES256", []string{
+		filepath.Join(dir, "current.pem"),
+		filepath.Join(dir, "previous.pem"),
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, keys.Reload())
+	}
+	// a broken file fails the reload without dropping the loaded keys
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "previous.pem"), []byte("not a key"), 0o600))
+	assert.Error(t, keys.Reload())
+
+	for kid, signer := range signers {
+		_, err := utils.ParseTokenWithKeySet(keys, signWith(t, kid, signer, newTestClaims(3, "")))
+		assert.NoError(t, err, kid)
+	}
+	assert.Len(t, keys.JWKS().Keys, 2)
+}
+
+func TestGetJWKS(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyFile(t, dir, "current", "RS256")
+	keys, err := utils.LoadKeySet("RS256", []string{filepath.Join(dir, "current.pem")})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	router.SetupJWKSRoutes(app, handler.NewJWKSHandler(keys))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var jwks utils.JWKS
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&jwks))
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, keys.Active().Kid, jwks.Keys[0].Kid)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// thb parses a baht amount such as "10.50"
+func thb(amount string) models.Money {
+	money, err := models.ParseMoney(amount, "THB")
+	if err != nil {
+		panic(err)
+	}
+	return money
+}
+
+func TestParseMoney(t *testing.T) {
+	money, err := models.ParseMoney("10.5", "THB")
+	require.NoError(t, err)
+	assert.Equal(t, models.NewMoney(1050, "THB"), money)
+
+	money, err = models.ParseMoney("-1.250", "KWD")
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1250), money.Amount)
+	assert.Equal(t, "-1.250 KWD", money.String())
+
+	_, err = models.ParseMoney("10.505", "THB")
+	assert.Error(t, err)
+	_, err = models.ParseMoney("100.5", "JPY")
+	assert.Error(t, err)
+	_, err = models.ParseMoney("10", "XXX")
+	assert.Error(t, err)
+}
+
+func TestMoney_JSON(t *testing.T) {
+	body, err := json.Marshal(dto.PriceAdjustment{Rule: "coupon", Amount: models.NewMoney(-1050, "THB")})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"rule":"coupon","description":"","amount":{"amount":"-10.50","currency":"THB"}}`, string(body))
+
+	var money models.Money
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":"500","currency":"jpy"}`), &money))
+	assert.Equal(t, models.NewMoney(500, "JPY"), money)
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":10.5,"currency":"THB"}`), &money))
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":"10.50","currency":"BTC"}`), &money))
+
+	// optional amounts are left out when zero
+	body, err = json.Marshal(dto.BookingResponse{Price: thb("100")})
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "discount")
+}
+
+func TestMoney_PercentRoundsHalfAwayFromZero(t *testing.T) {
+	assert.Equal(t, models.NewMoney(13, "THB"), models.NewMoney(25, "THB").Percent(50))
+	assert.Equal(t, models.NewMoney(-13, "THB"), models.NewMoney(-25, "THB").Percent(50))
+	assert.Equal(t, models.NewMoney(33, "THB"), models.NewMoney(100, "THB").Percent(100.0/3))
+	assert.Panics(t, func() { thb("1").Add(models.NewMoney(1, "USD")) })
+}
+
+func TestHighValueThresholdIsPerCurrency(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{
+		HighValueThresholds: map[string]models.Money{"THB": thb("2500")},
+	})
+
+	cheap, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 42, ServiceID: 2})
+	require.NoError(t, err)
+	assert.False(t, cheap.HighValue)
+
+	expensive, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 42, ServiceID: 3})
+	require.NoError(t, err)
+	assert.True(t, expensive.HighValue)
+
+	_, err = u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 42, ServiceID: 3, Price: models.NewMoney(100, "USD")})
+	assert.ErrorIs(t, err, usecase.ErrCurrencyMismatch)
+
+	bookings, err := u.GetAllBookings(utils.SystemClaims, "", "true")
+	require.NoError(t, err)
+	for _, booking := range bookings {
+		assert.Greater(t, booking.Price.Amount, thb("2500").Amount)
+	}
+	assert.NotEmpty(t, bookings)
+}
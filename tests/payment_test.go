@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/handler"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCallbackSecret = "callback-secret"
+
+func newPaymentUsecase(provider utils.PaymentProvider) usecase.BookingUsecase {
+	return newBookingUsecaseWithProvider(&config.Config{
+		Payment:            config.PaymentSettings{CallbackSecret: testCallbackSecret},
+		CancellationPolicy: config.CancellationPolicy{FreeBefore: 24 * time.Hour},
+	}, provider)
+}
+
+func TestStartPayment_CaptureConfirmsBooking(t *testing.T) {
+	u := newPaymentUsecase(utils.NewFakePaymentProvider())
+	booking, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1, StartAt: slotIn(48 * time.Hour)})
+	require.NoError(t, err)
+
+	payment, err := u.StartPayment(booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PaymentCaptured, payment.Status)
+	assert.Equal(t, thb("1000"), payment.Amount)
+
+	confirmed, err := u.GetBookingByID(utils.SystemClaims, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusConfirmed, confirmed.Status)
+
+	// canceling early refunds the whole payment
+	require.NoError(t, u.CancelBooking(&utils.Claims{Id: 100}, booking.ID))
+	payments, err := u.GetPayments(utils.SystemClaims, booking.ID)
+	require.NoError(t, err)
+	require.Len(t, payments, 1)
+	assert.Equal(t, models.PaymentRefunded, payments[0].Status)
+	assert.Equal(t, thb("1000"), payments[0].Refunded)
+}
+
+func TestStartPayment_DeclineRejectsBooking(t *testing.T) {
+	provider := utils.NewFakePaymentProvider()
+	provider.Decline = func(req utils.PaymentRequest) bool { return req.UserID == 666 }
+	u := newPaymentUsecase(provider)
+
+	booking, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 666, ServiceID: 1})
+	require.NoError(t, err)
+	payment, err := u.StartPayment(booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PaymentDeclined, payment.Status)
+
+	rejected, err := u.GetBookingByID(utils.SystemClaims, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusRejected, rejected.Status)
+
+	_, err = u.StartPayment(booking.ID)
+	assert.ErrorIs(t, err, usecase.ErrInvalidTransition)
+}
+
+func TestPaymentCallback_FinishesPendingCapture(t *testing.T) {
+	provider := utils.NewFakePaymentProvider()
+	provider.AsyncCapture = true
+	u := newPaymentUsecase(provider)
+
+	booking, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1})
+	require.NoError(t, err)
+	payment, err := u.StartPayment(booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PaymentCapturePending, payment.Status)
+
+	app := fiber.New()
+	app.Post("/api/payments/callback", handler.NewBookingHandler(u).PaymentCallback)
+	callback := func(signature string, body []byte) int {
+		req := httptest.NewRequest("POST", "/api/payments/callback", bytes.NewReader(body))
+		req.Header.Set("X-Payment-Signature", signature)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	body, _ := json.Marshal(dto.PaymentCallback{Reference: payment.Reference, Status: utils.PaymentSucceeded})
+	assert.Equal(t, fiber.StatusUnauthorized, callback("forged", body))
+	assert.Equal(t, fiber.StatusOK, callback(utils.SignPayment(testCallbackSecret, body), body))
+	// retried callbacks change nothing
+	assert.Equal(t, fiber.StatusOK, callback(utils.SignPayment(testCallbackSecret, body), body))
+
+	unknown, _ := json.Marshal(dto.PaymentCallback{Reference: "nope", Status: utils.PaymentSucceeded})
+	assert.Equal(t, fiber.StatusNotFound, callback(utils.SignPayment(testCallbackSecret, unknown), unknown))
+
+	confirmed, err := u.GetBookingByID(utils.SystemClaims, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusConfirmed, confirmed.Status)
+	invoice, err := u.GetInvoice(utils.SystemClaims, booking.ID)
+	require.NoError(t, err)
+	assert.Len(t, invoice.Invoices, 1)
+}
+
+func TestHTTPPaymentProvider(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		if strings.HasSuffix(r.URL.Path, "/refunds") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(utils.PaymentResult{Reference: "pay_1", Status: utils.PaymentSucceeded})
+	}))
+	defer server.Close()
+
+	provider := utils.NewHTTPPaymentProvider(server.URL+"/", "key", time.Second)
+	result, err := provider.Authorize(utils.PaymentRequest{BookingID: 1, Amount: thb("10")})
+	require.NoError(t, err)
+	assert.Equal(t, "pay_1", result.Reference)
+
+	_, err = provider.Capture("pay_1", thb("10"))
+	require.NoError(t, err)
+	_, err = provider.Refund("pay_1", thb("10"))
+	assert.Error(t, err)
+	assert.Equal(t, []string{"/authorizations", "/authorizations/pay_1/capture", "/authorizations/pay_1/refunds"}, paths)
+}
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/handler"
+	"github.com/Eursukkul/fiber-booking-system/repository"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPricingRules = config.PricingRules{
+	DurationMultipliers: []config.DurationMultiplier{{MinDuration: 2 * time.Hour, Multiplier: 0.9}},
+	PeakStart:           17,
+	PeakEnd:             20,
+	PeakSurcharge:       20,
+	WeekendSurcharge:    10,
+	TierDiscounts:       map[string]float64{"gold": 5},
+	UserTiers:           map[int]string{42: "gold"},
+}
+
+func TestPricing_AppliesRulesInOrder(t *testing.T) {
+	bangkok, err := time.LoadLocation("Asia/Bangkok")
+	require.NoError(t, err)
+	pricing := usecase.NewPricingUsecase(repository.NewMockServiceRepository(), &config.Config{
+		Pricing:   testPricingRules,
+		Timezones: config.TimezoneSettings{Default: bangkok},
+	})
+
+	// saturday evening in the time zone of the service, two slots, gold member
+	staff := &utils.Claims{Id: 1, Role: utils.RoleStaff}
+	quote, err := pricing.Quote(staff, dto.PriceQuoteRequest{UserID: 42, ServiceID: 1, StartAt: "2030-06-01T18:00:00+07:00", Duration: "2h"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, quote.Units)
+	require.Len(t, quote.Adjustments, 4)
+	assert.Equal(t, usecase.RuleDuration, quote.Adjustments[0].Rule)
+	assert.Equal(t, thb("-200"), quote.Adjustments[0].Amount)
+	assert.Equal(t, thb("360"), quote.Adjustments[1].Amount)
+	assert.Equal(t, thb("216"), quote.Adjustments[2].Amount)
+	assert.Equal(t, thb("-118.8"), quote.Adjustments[3].Amount)
+	assert.Equal(t, thb("2257.2"), quote.Total)
+
+	// monday morning has no adjustments, customers only get their own tier
+	quote, err = pricing.Quote(&utils.Claims{Id: 7}, dto.PriceQuoteRequest{UserID: 42, ServiceID: 1, StartAt: "2030-06-03T10:00:00+07:00"})
+	require.NoError(t, err)
+	assert.Empty(t, quote.Adjustments)
+	assert.Equal(t, thb("1000"), quote.Total)
+
+	// anonymous callers cannot pick a tier
+	quote, err = pricing.Quote(nil, dto.PriceQuoteRequest{UserID: 42, ServiceID: 1, StartAt: "2030-06-03T10:00:00+07:00", Duration: "2h"})
+	require.NoError(t, err)
+	assert.Len(t, quote.Adjustments, 1)
+	assert.Equal(t, thb("1800"), quote.Total)
+
+	_, err = pricing.Quote(nil, dto.PriceQuoteRequest{ServiceID: 1, Duration: "90m"})
+	assert.ErrorIs(t, err, usecase.ErrInvalidSlot)
+}
+
+func TestCreateBooking_PriceOnlyOverriddenByStaff(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{Pricing: testPricingRules})
+
+	booking, err := u.CreateBooking(&utils.Claims{Id: 42}, dto.BookingRequest{ServiceID: 2, Price: thb("1")})
+	require.NoError(t, err)
+	assert.Equal(t, thb("1900"), booking.Price)
+	require.NotNil(t, booking.PriceBreakdown)
+	assert.False(t, booking.PriceBreakdown.Override)
+
+	staff := &utils.Claims{Id: 1, Role: utils.RoleStaff}
+	booking, err = u.CreateBooking(staff, dto.BookingRequest{UserID: 43, ServiceID: 2, Price: thb("500")})
+	require.NoError(t, err)
+	assert.Equal(t, thb("500"), booking.Price)
+	assert.True(t, booking.PriceBreakdown.Override)
+}
+
+func TestPricingQuote_Handler(t *testing.T) {
+	pricing := usecase.NewPricingUsecase(repository.NewMockServiceRepository(), &config.Config{})
+	app := fiber.New()
+	app.Post("/api/pricing/quote", handler.NewPricingHandler(pricing).Quote)
+
+	quote := func(req dto.PriceQuoteRequest) int {
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest("POST", "/api/pricing/quote", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(httpReq)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusOK, quote(dto.PriceQuoteRequest{ServiceID: 3, Duration: "3h"}))
+	assert.Equal(t, fiber.StatusBadRequest, quote(dto.PriceQuoteRequest{ServiceID: 99}))
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/middleware"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/repository"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryRateLimitStore_Refill(t *testing.T) {
+	store := middleware.NewInMemoryRateLimitStore()
+	limit := config.RateLimit{Requests: 2, Period: 2 * time.Second}
+	now := time.Now()
+
+	assert.True(t, store.Take("k", limit, now).Allowed)
+	assert.True(t, store.Take("k", limit, now).Allowed)
+
+	denied := store.Take("k", limit, now)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, 0, denied.Remaining)
+	assert.Equal(t, time.Second, denied.RetryAfter)
+
+	// one token is back after a second, other keys have their own bucket
+	assert.True(t, store.Take("k", limit, now.Add(time.Second)).Allowed)
+	assert.True(t, store.Take("other", limit, now).Allowed)
+}
+
+func TestRateLimit_PerUserHeaders(t *testing.T) {
+	limiter := middleware.NewRateLimitMiddleware(middleware.NewInMemoryRateLimitStore())
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		id, _ := strconv.Atoi(c.Get("X-Test-User"))
+		c.Locals("claims", &utils.AuthMapClaims{Claims: &utils.Claims{Id: id}})
+		return c.Next()
+	})
+	app.Post("/bookings", limiter.Limit("write", config.RateLimit{Requests: 1, Period: time.Minute}), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	send := func(user string) *http.Response {
+		req := httptest.NewRequest("POST", "/bookings", nil)
+		req.Header.Set("X-Test-User", user)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	first := send("1")
+	assert.Equal(t, fiber.StatusCreated, first.StatusCode)
+	assert.Equal(t, "1", first.Header.Get("RateLimit-Limit"))
+	assert.Equal(t, "0", first.Header.Get("RateLimit-Remaining"))
+
+	limited := send("1")
+	assert.Equal(t, fiber.StatusTooManyRequests, limited.StatusCode)
+	assert.Equal(t, "60", limited.Header.Get("Retry-After"))
+
+	assert.Equal(t, fiber.StatusCreated, send("2").StatusCode)
+}
+
+func TestRateLimit_PerApiKeyAndTenant(t *testing.T) {
+	cfg := newTenantConfig()
+	apiKeys := usecase.NewApiKeyUsecase(repository.NewMockApiKeyRepository())
+	auth := middleware.NewAuthMiddleware(cfg, nil, apiKeys)
+	limiter := middleware.NewRateLimitMiddleware(middleware.NewInMemoryRateLimitStore())
+	limit := config.RateLimit{Requests: 1, Period: time.Minute}
+
+	app := fiber.New()
+	app.Get("/partner/bookings", auth.ApiKeyAuth(), limiter.Limit("read", limit), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/api/bookings", auth.JwtAuth(), limiter.Limit("read", limit), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	newKey := func(tenant string) string {
+		created, err := apiKeys.CreateApiKey(tenant, dto.ApiKeyRequest{Name: "partner", Scopes: []string{models.ScopeBookingsRead}})
+		require.NoError(t, err)
+		return created.Key
+	}
+	token := func(tenant string) string {
+		claims := newTestClaims(1, "")
+		claims.Tenant = tenant
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.JWTSecret))
+		require.NoError(t, err)
+		return signed
+	}
+	get := func(path, header, value string) int {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(header, value)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	// staff keys carry no user id, every key still has a bucket of its own
+	first, second, acme := newKey("default"), newKey("default"), newKey("acme")
+	assert.Equal(t, fiber.StatusOK, get("/partner/bookings", "X-Api-Key", first))
+	assert.Equal(t, fiber.StatusTooManyRequests, get("/partner/bookings", "X-Api-Key", first))
+	assert.Equal(t, fiber.StatusOK, get("/partner/bookings", "X-Api-Key", second))
+	assert.Equal(t, fiber.StatusOK, get("/partner/bookings", "X-Api-Key", acme))
+
+	// user 1 of one tenant does not use up user 1 of another
+	assert.Equal(t, fiber.StatusOK, get("/api/bookings", "Authorization", "Bearer "+token("default")))
+	assert.Equal(t, fiber.StatusTooManyRequests, get("/api/bookings", "Authorization", "Bearer "+token("default")))
+	assert.Equal(t, fiber.StatusOK, get("/api/bookings", "Authorization", "Bearer "+token("acme")))
+}
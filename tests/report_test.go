@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/handler"
+	"github.com/Eursukkul/fiber-booking-system/middleware"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/repository"
+	"github.com/Eursukkul/fiber-booking-system/router"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReportedBookings has one booking confirmed, one canceled, one rejected,
+// three expired and four pending among the seeded bookings
+func newReportedBookings(t *testing.T) usecase.BookingUsecase {
+	// seeded bookings are made one to ten minutes ago
+	u := newBookingUsecaseWithConfig(&config.Config{PendingTTL: 450 * time.Second})
+	staff := &utils.Claims{Id: 1, Role: utils.RoleStaff}
+
+	require.Equal(t, []int{8, 9, 10}, u.ExpirePendingBookings())
+	_, err := u.StartPayment(1)
+	require.NoError(t, err)
+	require.NoError(t, u.CancelBooking(staff, 2))
+	require.NoError(t, u.UpdateBooking(staff, 3, models.StatusRejected))
+	return u
+}
+
+func TestBookingReport_CountsAndRates(t *testing.T) {
+	u := newReportedBookings(t)
+
+	_, err := u.BookingReport(&utils.Claims{Id: 100}, dto.ReportFilter{}, nil)
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+	_, err = u.RevenueReport(nil, dto.ReportFilter{}, nil)
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+
+	report, err := u.BookingReport(utils.SystemClaims, dto.ReportFilter{GroupBy: dto.ReportByStatus}, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", report.Timezone)
+	total := report.Total
+	assert.Equal(t, 10, total.Bookings)
+	assert.Equal(t, 1, total.Confirmed)
+	assert.Equal(t, 1, total.Canceled)
+	assert.Equal(t, 3, total.Expired)
+	assert.Equal(t, 1, total.Rejected)
+	assert.Equal(t, 4, total.Pending)
+	assert.Equal(t, 0.1, total.ConversionRate)
+	assert.Equal(t, 0.1, total.CancellationRate)
+	assert.Equal(t, 0.3, total.ExpiryRate)
+	assert.Equal(t, 1, total.CreditChecks)
+	keys := []string{}
+	for _, row := range report.Rows {
+		keys = append(keys, row.Key)
+	}
+	assert.Equal(t, []string{"canceled", "confirmed", "pending", "rejected"}, keys)
+	assert.Equal(t, 4, report.Rows[0].Bookings)
+
+	// canceling a confirmed booking keeps it converted
+	require.NoError(t, u.CancelBooking(utils.SystemClaims, 1))
+	report, err = u.BookingReport(utils.SystemClaims, dto.ReportFilter{GroupBy: dto.ReportByService, ServiceID: 1}, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, report.Rows, 1)
+	assert.Equal(t, "1", report.Rows[0].Key)
+	assert.Equal(t, 1, report.Rows[0].Converted)
+	assert.Equal(t, 1, report.Rows[0].Canceled)
+
+	// every day of the range has a row
+	report, err = u.BookingReport(utils.SystemClaims, dto.ReportFilter{}, time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, dto.ReportByDay, report.GroupBy)
+	assert.Len(t, report.Rows, 30)
+	assert.Equal(t, time.Now().UTC().Format(models.DateLayout), report.To)
+}
+
+func TestBookingReport_Ranges(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{})
+
+	report, err := u.BookingReport(utils.SystemClaims, dto.ReportFilter{GroupBy: dto.ReportByWeek, From: "2024-12-30", To: "2025-01-05"}, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, report.Rows, 1)
+	assert.Equal(t, "2025-W01", report.Rows[0].Key)
+	assert.Equal(t, 0, report.Total.Bookings)
+
+	report, err = u.BookingReport(utils.SystemClaims, dto.ReportFilter{GroupBy: dto.ReportByMonth, From: "2024-11-15", To: "2025-01-02"}, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, report.Rows, 3)
+	assert.Equal(t, "2024-11", report.Rows[0].Key)
+	assert.Equal(t, "2025-01", report.Rows[2].Key)
+
+	for _, filter := range []dto.ReportFilter{
+		{GroupBy: "year"},
+		{From: "2025-01-05", To: "2025-01-04"},
+		{From: "2024-01-01", To: "2025-12-31"},
+		{From: "05/01/2025"},
+	} {
+		_, err = u.BookingReport(utils.SystemClaims, filter, time.UTC)
+		assert.ErrorIs(t, err, usecase.ErrInvalidReport, filter)
+	}
+}
+
+func TestRevenueReport_BookedCollectedRefunded(t *testing.T) {
+	u := newReportedBookings(t)
+
+	report, err := u.RevenueReport(utils.SystemClaims, dto.ReportFilter{GroupBy: dto.ReportByService}, time.UTC)
+	require.NoError(t, err)
+	assert.Len(t, report.Rows, 10)
+	require.Len(t, report.Totals, 1)
+	total := report.Totals[0]
+	assert.Equal(t, "THB", total.Currency)
+	assert.Equal(t, 10, total.Bookings)
+	assert.Equal(t, "1000.00 THB", total.Booked.String())
+	assert.Equal(t, "1000.00 THB", total.Collected.String())
+	assert.Equal(t, "1000.00 THB", total.Net.String())
+
+	require.NoError(t, u.CancelBooking(utils.SystemClaims, 1))
+	report, err = u.RevenueReport(utils.SystemClaims, dto.ReportFilter{GroupBy: dto.ReportByService, ServiceID: 1}, time.UTC)
+	require.NoError(t, err)
+	row := report.Rows[0]
+	assert.True(t, row.Booked.IsZero())
+	assert.Equal(t, "1000.00 THB", row.Collected.String())
+	assert.False(t, row.Refunded.IsZero())
+	assert.Equal(t, row.Collected.Sub(row.Refunded), row.Net)
+}
+
+func TestReports_Routes(t *testing.T) {
+	u := newReportedBookings(t)
+	h := handler.NewBookingHandler(u)
+	app := fiber.New()
+	app.Use(authenticated(&utils.Claims{Id: 1, Role: utils.RoleStaff}))
+	app.Get("/api/reports/bookings", h.BookingReport)
+	app.Get("/api/reports/revenue", h.RevenueReport)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/reports/bookings?group_by=status&format=csv", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get(fiber.HeaderContentType))
+	body, _ := io.ReadAll(resp.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	require.Len(t, lines, 6)
+	assert.True(t, strings.HasPrefix(lines[0], "status,bookings,pending,"))
+	assert.True(t, strings.HasPrefix(lines[5], "total,10,4,0,1,1,1,3,1,0.1,0.1,0.3,1,"))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/reports/revenue?group_by=status&format=csv", nil))
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "total,THB,10,1000.00,1000.00,0.00,1000.00\n")
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/reports/revenue?from=someday", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/reports/bookings?format=xml", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestReports_ApiRoutesNeedStaff(t *testing.T) {
+	cfg := &config.Config{JWTSecret: "secret", DefaultTenant: "default"}
+	apiKeys := usecase.NewApiKeyUsecase(repository.NewMockApiKeyRepository())
+	auth := middleware.NewAuthMiddleware(cfg, nil, apiKeys)
+	limiter := middleware.NewRateLimitMiddleware(middleware.NewInMemoryRateLimitStore())
+	app := fiber.New()
+	router.SetupRoutes(app, cfg, handler.NewBookingHandler(newTestBookingUsecase()), middleware.NewLoggerMiddleware(), auth, limiter)
+
+	get := func(path, role string) int {
+		req := httptest.NewRequest("GET", path, nil)
+		if role != "" {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, newTestClaims(1, role)).SignedString([]byte(cfg.JWTSecret))
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	for _, path := range []string{"/api/reports/bookings", "/api/reports/revenue"} {
+		assert.Equal(t, fiber.StatusUnauthorized, get(path, ""), path)
+		assert.Equal(t, fiber.StatusForbidden, get(path, utils.RoleCustomer), path)
+		assert.Equal(t, fiber.StatusOK, get(path, utils.RoleStaff), path)
+	}
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newResourceUsecases() (usecase.BookingUsecase, usecase.ResourceUsecase) {
+	tenant := usecase.NewTenant(&config.Config{}, utils.NewFakePaymentProvider())
+	return tenant.Bookings, tenant.Resources
+}
+
+func resourceIDs(booking *dto.BookingResponse) []int {
+	ids := []int{}
+	for _, resource := range booking.Resources {
+		ids = append(ids, resource.ID)
+	}
+	return ids
+}
+
+func TestResources_AreAssignedAtBookingTime(t *testing.T) {
+	bookings, resources := newResourceUsecases()
+	create := func(req dto.ResourceRequest) int {
+		resource, err := resources.CreateResource(req)
+		require.NoError(t, err)
+		return resource.ID
+	}
+	installer := create(dto.ResourceRequest{Name: "Somchai", Type: "staff", Skills: []string{"Fiber"}})
+	splicer := create(dto.ResourceRequest{Name: "Malee", Type: "staff", Skills: []string{"fiber", "splicing"}})
+	room := create(dto.ResourceRequest{Name: "Lab 1", Type: "room"})
+	create(dto.ResourceRequest{Name: "Lab 2", Type: "room", Disabled: true})
+
+	_, err := resources.SetServiceRequirements(1, dto.ServiceRequirementsRequest{Requirements: []dto.ResourceRequirement{
+		{Type: "staff", Skills: []string{"splicing"}, Quantity: 1},
+		{Type: "room", Quantity: 1},
+	}})
+	require.NoError(t, err)
+	_, err = resources.SetServiceRequirements(2, dto.ServiceRequirementsRequest{Requirements: []dto.ResourceRequirement{
+		{Type: "staff", Skills: []string{"fiber"}, Quantity: 1},
+	}})
+	require.NoError(t, err)
+	_, err = resources.SetServiceRequirements(3, dto.ServiceRequirementsRequest{Requirements: []dto.ResourceRequirement{
+		{Type: "staff", Skills: []string{"fiber"}, Quantity: 1},
+		{Type: "staff", Skills: []string{"splicing"}, Quantity: 1},
+	}})
+	require.NoError(t, err)
+
+	start := slotIn(48 * time.Hour)
+	book := func(serviceID int, startAt string) (*dto.BookingResponse, error) {
+		return bookings.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: serviceID, StartAt: startAt})
+	}
+
+	splicing, err := book(1, start)
+	require.NoError(t, err)
+	assert.Equal(t, []int{splicer, room}, resourceIDs(splicing))
+	// the service has capacity left but no free splicer or room
+	_, err = book(1, start)
+	assert.ErrorIs(t, err, usecase.ErrNoResourceAvailable)
+	assert.Contains(t, err.Error(), "1 staff with splicing")
+
+	install, err := book(2, start)
+	require.NoError(t, err)
+	assert.Equal(t, []int{installer}, resourceIDs(install))
+	_, err = book(2, start)
+	assert.ErrorIs(t, err, usecase.ErrNoResourceAvailable)
+
+	// canceled bookings free their resources
+	require.NoError(t, bookings.CancelBooking(utils.SystemClaims, splicing.ID))
+	_, err = book(1, start)
+	assert.NoError(t, err)
+
+	// the splicer is kept for the requirement only they can fill
+	later := slotIn(96 * time.Hour)
+	both, err := book(3, later)
+	require.NoError(t, err)
+	assert.Equal(t, []int{installer, splicer}, resourceIDs(both))
+
+	// a moved booking keeps its resources when they are free
+	moved, err := bookings.RescheduleBooking(utils.SystemClaims, both.ID, dto.RescheduleRequest{StartAt: slotIn(97 * time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, []int{installer, splicer}, resourceIDs(moved))
+	history, err := bookings.GetBookingHistory(utils.SystemClaims, both.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, history[len(history)-1].Changes, "resources")
+
+	// bookings without a slot get no resources
+	unscheduled, err := book(1, "")
+	require.NoError(t, err)
+	assert.Empty(t, unscheduled.Resources)
+
+	schedule, err := resources.GetResourceSchedule(splicer, "", "", time.UTC)
+	require.NoError(t, err)
+	require.Len(t, schedule.Bookings, 2)
+	assert.Equal(t, moved.ID, schedule.Bookings[1].BookingID)
+	assert.True(t, moved.StartAt.Equal(schedule.Bookings[1].StartAt))
+
+	assert.ErrorIs(t, resources.DeleteResource(splicer), usecase.ErrResourceInUse)
+	_, err = resources.SetServiceRequirements(1, dto.ServiceRequirementsRequest{Requirements: []dto.ResourceRequirement{{Type: "robot", Quantity: 1}}})
+	assert.ErrorIs(t, err, usecase.ErrInvalidResource)
+}
@@ -0,0 +1,220 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/handler"
+	"github.com/Eursukkul/fiber-booking-system/middleware"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/repository"
+	"github.com/Eursukkul/fiber-booking-system/router"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTenantConfig() *config.Config {
+	return &config.Config{
+		JWTSecret:     "secret",
+		DefaultTenant: "default",
+		Tenants: map[string]config.TenantSettings{
+			"acme": {
+				Hosts:               []string{"acme.example.com"},
+				PendingTTL:          time.Hour,
+				HighValueThresholds: map[string]models.Money{"THB": models.NewMoney(100, "THB")},
+				Pricing:             &config.PricingRules{WeekendSurcharge: 10},
+			},
+		},
+	}
+}
+
+func TestConfig_ForTenantAppliesItsSettings(t *testing.T) {
+	cfg := newTenantConfig()
+	cfg.PendingTTL = 5 * time.Minute
+
+	acme := cfg.ForTenant("acme")
+	assert.Equal(t, time.Hour, acme.PendingTTL)
+	assert.Equal(t, 10.0, acme.Pricing.WeekendSurcharge)
+	assert.Contains(t, acme.HighValueThresholds, "THB")
+	assert.Same(t, cfg, cfg.ForTenant("default"))
+	assert.Equal(t, []string{"default", "acme"}, cfg.TenantIDs())
+
+	tenant, exists := cfg.TenantForHost("ACME.example.com")
+	assert.True(t, exists)
+	assert.Equal(t, "acme", tenant)
+}
+
+func TestTenant_CannotReadOtherTenants(t *testing.T) {
+	cfg := newTenantConfig()
+	defaultBookings := newBookingUsecaseWithConfig(cfg.ForTenant("default"))
+	acmeBookings := newBookingUsecaseWithConfig(cfg.ForTenant("acme"))
+	_, err := acmeBookings.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 2})
+	require.NoError(t, err)
+	acmeBooking, err := acmeBookings.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1})
+	require.NoError(t, err)
+	defaultBooking, err := defaultBookings.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1})
+	require.NoError(t, err)
+	// every tenant has its own threshold
+	assert.True(t, acmeBooking.HighValue)
+	assert.False(t, defaultBooking.HighValue)
+
+	bookingHandler := handler.NewBookingHandler(defaultBookings)
+	bookingHandler.Tenants = map[string]usecase.BookingUsecase{"default": defaultBookings, "acme": acmeBookings}
+	apiKeys := usecase.NewApiKeyUsecase(repository.NewMockApiKeyRepository())
+	auth := middleware.NewAuthMiddleware(cfg, nil, apiKeys)
+	limiter := middleware.NewRateLimitMiddleware(middleware.NewInMemoryRateLimitStore())
+	app := fiber.New()
+	router.SetupRoutes(app, cfg, bookingHandler, middleware.NewLoggerMiddleware(), auth, limiter)
+	router.SetupRoutes_middleware(app, cfg, bookingHandler, middleware.NewLoggerMiddleware(), auth, limiter)
+	router.SetupApiKeyRoutes(app, cfg, bookingHandler, middleware.NewLoggerMiddleware(), auth, limiter)
+
+	token := func(tenant string) string {
+		claims := newTestClaims(1, "staff")
+		claims.Tenant = tenant
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.JWTSecret))
+		require.NoError(t, err)
+		return signed
+	}
+	get := func(path, host string, headers map[string]string) int {
+		req := httptest.NewRequest("GET", path, nil)
+		if host != "" {
+			req.Host = host
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	bearer := func(tenant string) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + token(tenant)}
+	}
+
+	// the default tenant has no booking with the id of the acme booking
+	path := "/v1/bookings/12"
+	assert.Equal(t, fiber.StatusOK, get(path, "", bearer("acme")))
+	assert.Equal(t, fiber.StatusOK, get(path, "acme.example.com", bearer("acme")))
+	assert.Equal(t, fiber.StatusNotFound, get(path, "", bearer("")))
+	assert.Equal(t, fiber.StatusForbidden, get(path, "acme.example.com", bearer("")))
+	assert.Equal(t, fiber.StatusForbidden, get(path, "", bearer("globex")))
+
+	// bookings are never anonymous
+	assert.Equal(t, fiber.StatusUnauthorized, get("/api/bookings/12", "acme.example.com", nil))
+	assert.Equal(t, fiber.StatusOK, get("/api/bookings/12", "acme.example.com", bearer("acme")))
+	assert.Equal(t, fiber.StatusNotFound, get("/api/bookings/12", "", bearer("")))
+
+	acmeKey, err := apiKeys.CreateApiKey("acme", dto.ApiKeyRequest{Name: "acme", Scopes: []string{models.ScopeBookingsRead}})
+	require.NoError(t, err)
+	defaultKey, err := apiKeys.CreateApiKey("default", dto.ApiKeyRequest{Name: "default", Scopes: []string{models.ScopeBookingsRead}})
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, get("/partner/bookings/12", "", map[string]string{"X-Api-Key": acmeKey.Key}))
+	assert.Equal(t, fiber.StatusNotFound, get("/partner/bookings/12", "", map[string]string{"X-Api-Key": defaultKey.Key}))
+	assert.Len(t, apiKeys.GetAllApiKeys("acme"), 1)
+	assert.Error(t, apiKeys.RevokeApiKey("acme", defaultKey.ID))
+}
+
+func TestTenant_PublicRoutesFindTheirTenant(t *testing.T) {
+	cfg := newTenantConfig()
+	cfg.Payment.CallbackSecret = testCallbackSecret
+	provider := utils.NewFakePaymentProvider()
+	provider.AsyncCapture = true
+	defaultBookings := newBookingUsecaseWithProvider(cfg.ForTenant("default"), provider)
+	acmeBookings := newBookingUsecaseWithProvider(cfg.ForTenant("acme"), provider)
+	bookingHandler := handler.NewBookingHandler(defaultBookings)
+	bookingHandler.Tenants = map[string]usecase.BookingUsecase{"default": defaultBookings, "acme": acmeBookings}
+	app := fiber.New()
+	router.SetupPaymentRoutes(app, cfg, bookingHandler, middleware.NewLoggerMiddleware())
+	router.SetupCalendarRoutes(app, cfg, bookingHandler, middleware.NewLoggerMiddleware(), middleware.NewRateLimitMiddleware(middleware.NewInMemoryRateLimitStore()))
+	app.Get("/tenant", middleware.Tenant(cfg), func(c *fiber.Ctx) error {
+		return c.SendString(utils.TenantFromCtx(c))
+	})
+
+	// the host picks the tenant of anonymous requests
+	tenantOf := func(host string) string {
+		req := httptest.NewRequest("GET", "/tenant", nil)
+		req.Host = host
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	assert.Equal(t, "acme", tenantOf("acme.example.com"))
+	assert.Equal(t, "default", tenantOf("example.com"))
+
+	// the callback of an acme payment reaches acme on any host
+	booking, err := acmeBookings.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1})
+	require.NoError(t, err)
+	payment, err := acmeBookings.StartPayment(booking.ID)
+	require.NoError(t, err)
+	payload, _ := json.Marshal(dto.PaymentCallback{Reference: payment.Reference, Status: utils.PaymentSucceeded})
+	req := httptest.NewRequest("POST", "/api/payments/callback", bytes.NewReader(payload))
+	req.Header.Set("X-Payment-Signature", utils.SignPayment(testCallbackSecret, payload))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	confirmed, err := acmeBookings.GetBookingByID(utils.SystemClaims, booking.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusConfirmed, confirmed.Status)
+
+	payload, _ = json.Marshal(dto.PaymentCallback{Reference: "fake_999", Status: utils.PaymentSucceeded})
+	req = httptest.NewRequest("POST", "/api/payments/callback", bytes.NewReader(payload))
+	req.Header.Set("X-Payment-Signature", utils.SignPayment(testCallbackSecret, payload))
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	feed, err := acmeBookings.CreateCalendarFeed(&utils.Claims{Id: 100}, dto.CalendarFeedRequest{})
+	require.NoError(t, err)
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/calendar/"+feed.Token+".ics", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/calendar/unknown.ics", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestTenant_AnonymousQuotesUseTheHost(t *testing.T) {
+	cfg := newTenantConfig()
+	defaultTenant := usecase.NewTenant(cfg.ForTenant("default"), utils.NewFakePaymentProvider())
+	acmeTenant := usecase.NewTenant(cfg.ForTenant("acme"), utils.NewFakePaymentProvider())
+	pricingHandler := handler.NewPricingHandler(defaultTenant.Pricing)
+	pricingHandler.Tenants = map[string]usecase.PricingUsecase{"default": defaultTenant.Pricing, "acme": acmeTenant.Pricing}
+	auth := middleware.NewAuthMiddleware(cfg, nil, usecase.NewApiKeyUsecase(repository.NewMockApiKeyRepository()))
+	limiter := middleware.NewRateLimitMiddleware(middleware.NewInMemoryRateLimitStore())
+	app := fiber.New()
+	router.SetupPricingRoutes(app, cfg, pricingHandler, middleware.NewLoggerMiddleware(), auth, limiter)
+
+	saturday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 7)
+	for saturday.Weekday() != time.Saturday {
+		saturday = saturday.AddDate(0, 0, 1)
+	}
+	quote := func(host string) dto.PriceBreakdown {
+		body, _ := json.Marshal(dto.PriceQuoteRequest{ServiceID: 1, StartAt: saturday.Add(10 * time.Hour).Format(time.RFC3339)})
+		req := httptest.NewRequest("POST", "/api/pricing/quote", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Host = host
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var breakdown dto.PriceBreakdown
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&breakdown))
+		return breakdown
+	}
+
+	// only acme has a weekend surcharge
+	acme, other := quote("acme.example.com"), quote("example.com")
+	assert.Equal(t, 1, acme.Total.Cmp(other.Total))
+	assert.Empty(t, other.Adjustments)
+	require.Len(t, acme.Adjustments, 1)
+}
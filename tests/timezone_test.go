@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	"github.com/Eursukkul/fiber-booking-system/handler"
+	"github.com/Eursukkul/fiber-booking-system/middleware"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookingTimes_AreStoredInUTC(t *testing.T) {
+	bangkok, err := time.LoadLocation("Asia/Bangkok")
+	require.NoError(t, err)
+	u := newBookingUsecaseWithConfig(&config.Config{})
+	start, _ := time.Parse(time.RFC3339, slotIn(48*time.Hour))
+
+	booking, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1, StartAt: start.In(bangkok).Format(time.RFC3339)})
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, booking.StartAt.Location())
+	assert.True(t, start.Equal(booking.StartAt))
+	assert.Equal(t, time.UTC, booking.CreatedAt.Location())
+
+	seeded, err := u.GetBookingByID(utils.SystemClaims, 1)
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, seeded.CreatedAt.Location())
+}
+
+func TestTimezone_RendersResponseTimes(t *testing.T) {
+	bangkok, _ := time.LoadLocation("Asia/Bangkok")
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	london, _ := time.LoadLocation("Europe/London")
+	u := newBookingUsecaseWithConfig(&config.Config{Timezones: config.TimezoneSettings{
+		Default:  bangkok,
+		Services: map[int]*time.Location{2: tokyo},
+		Users:    map[int]*time.Location{7: london},
+	}})
+	start, _ := time.Parse(time.RFC3339, slotIn(48*time.Hour))
+	first, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 1, StartAt: start.Format(time.RFC3339)})
+	require.NoError(t, err)
+	second, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 100, ServiceID: 2, StartAt: start.Format(time.RFC3339)})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	claims := &utils.Claims{Id: 100}
+	api := app.Group("/api", func(c *fiber.Ctx) error {
+		c.Locals("claims", &utils.AuthMapClaims{Claims: claims})
+		return c.Next()
+	}, middleware.Timezone())
+	api.Get("/bookings/:id", handler.NewBookingHandler(u).GetBookingByID)
+
+	startAt := func(id int, tz, header string) string {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/bookings/%d?tz=%s", id, tz), nil)
+		if header != "" {
+			req.Header.Set(utils.HeaderAcceptTimezone, header)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body["start_at"].(string)
+	}
+
+	// the zone of the service, or the default zone
+	assert.Equal(t, start.In(bangkok).Format(time.RFC3339), startAt(first.ID, "", ""))
+	assert.Equal(t, start.In(tokyo).Format(time.RFC3339), startAt(second.ID, "", ""))
+	// the caller asks for a zone
+	assert.Equal(t, start.Format(time.RFC3339), startAt(first.ID, "UTC", ""))
+	assert.Equal(t, start.In(tokyo).Format(time.RFC3339), startAt(first.ID, "", "Asia/Tokyo"))
+	assert.Equal(t, start.In(bangkok).Format(time.RFC3339), startAt(first.ID, "Asia/Bangkok", "Asia/Tokyo"))
+
+	// the zone of the user wins over the zone of the service
+	claims = &utils.Claims{Id: 7, Role: utils.RoleStaff}
+	assert.Equal(t, start.In(london).Format(time.RFC3339), startAt(second.ID, "", ""))
+
+	for _, tz := range []string{"Mars/Olympus", "Local"} {
+		resp, err := app.Test(httptest.NewRequest("GET", fmt.Sprintf("/api/bookings/%d?tz=%s", first.ID, tz), nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	}
+}
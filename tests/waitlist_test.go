@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/usecase"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fillSlot books every place of the slot, services take 5 bookings
+func fillSlot(t *testing.T, u usecase.BookingUsecase, serviceID int, startAt string) []*dto.BookingResponse {
+	bookings := []*dto.BookingResponse{}
+	for user := 100; user < 105; user++ {
+		booking, err := u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: user, ServiceID: serviceID, StartAt: startAt})
+		require.NoError(t, err)
+		bookings = append(bookings, booking)
+	}
+	return bookings
+}
+
+func TestWaitlist_CanceledBookingIsOfferedToNextUser(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{WaitlistAcceptWindow: 30 * time.Minute})
+	start := slotIn(48 * time.Hour)
+	bookings := fillSlot(t, u, 1, start)
+	first, second := &utils.Claims{Id: 200}, &utils.Claims{Id: 201}
+
+	_, err := u.JoinWaitlist(first, dto.WaitlistRequest{ServiceID: 1, StartAt: slotIn(72 * time.Hour)})
+	assert.ErrorIs(t, err, usecase.ErrSlotAvailable)
+
+	entry, err := u.JoinWaitlist(first, dto.WaitlistRequest{ServiceID: 1, StartAt: start})
+	require.NoError(t, err)
+	assert.Equal(t, 1, entry.Position)
+	_, err = u.JoinWaitlist(first, dto.WaitlistRequest{ServiceID: 1, StartAt: start})
+	assert.ErrorIs(t, err, usecase.ErrAlreadyWaitlisted)
+	next, err := u.JoinWaitlist(second, dto.WaitlistRequest{ServiceID: 1, StartAt: start})
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.Position)
+
+	_, err = u.AcceptWaitlistOffer(first, entry.ID)
+	assert.ErrorIs(t, err, usecase.ErrNoWaitlistOffer)
+
+	require.NoError(t, u.CancelBooking(utils.SystemClaims, bookings[0].ID))
+	entries, err := u.GetWaitlist(first)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, models.WaitlistOffered, entries[0].Status)
+	assert.NotEmpty(t, entries[0].OfferExpiresAt)
+
+	// the offer holds the freed place
+	_, err = u.CreateBooking(utils.SystemClaims, dto.BookingRequest{UserID: 300, ServiceID: 1, StartAt: start})
+	assert.ErrorIs(t, err, usecase.ErrSlotFull)
+	_, err = u.AcceptWaitlistOffer(second, entry.ID)
+	assert.ErrorIs(t, err, usecase.ErrForbidden)
+
+	booking, err := u.AcceptWaitlistOffer(first, entry.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 200, booking.UserID)
+	assert.Equal(t, start, booking.StartAt.Format(time.RFC3339))
+
+	entries, err = u.GetWaitlist(utils.SystemClaims)
+	require.NoError(t, err)
+	assert.Equal(t, models.WaitlistAccepted, entries[0].Status)
+	assert.Equal(t, booking.ID, entries[0].BookingID)
+	assert.Equal(t, models.WaitlistWaiting, entries[1].Status)
+	assert.Equal(t, 1, entries[1].Position)
+}
+
+func TestWaitlist_LeavingPassesOfferOn(t *testing.T) {
+	u := newBookingUsecaseWithConfig(&config.Config{WaitlistAcceptWindow: 30 * time.Minute})
+	start := slotIn(48 * time.Hour)
+	bookings := fillSlot(t, u, 1, start)
+	first, second := &utils.Claims{Id: 200}, &utils.Claims{Id: 201}
+
+	entry, err := u.JoinWaitlist(first, dto.WaitlistRequest{ServiceID: 1, StartAt: start})
+	require.NoError(t, err)
+	next, err := u.JoinWaitlist(second, dto.WaitlistRequest{ServiceID: 1, StartAt: start})
+	require.NoError(t, err)
+
+	require.NoError(t, u.UpdateBooking(utils.SystemClaims, bookings[2].ID, models.StatusRejected))
+	assert.ErrorIs(t, u.LeaveWaitlist(second, entry.ID), usecase.ErrForbidden)
+	require.NoError(t, u.LeaveWaitlist(first, entry.ID))
+	assert.ErrorIs(t, u.LeaveWaitlist(first, entry.ID), usecase.ErrWaitlistEntryClosed)
+
+	entries, err := u.GetWaitlist(second)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, next.ID, entries[0].ID)
+	assert.Equal(t, models.WaitlistOffered, entries[0].Status)
+}
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/repository"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+type (
+	ApiKeyUsecase interface {
+		CreateApiKey(tenant string, req dto.ApiKeyRequest) (*dto.ApiKeyCreatedResponse, error)
+		GetAllApiKeys(tenant string) []*dto.ApiKeyResponse
+		RevokeApiKey(tenant string, id int) error
+		ImportApiKey(tenant string, name string, plain string) error
+		Authenticate(key string) (*models.ApiKey, error)
+	}
+
+	apiKeyUsecase struct {
+		repo repository.ApiKeyRepository
+	}
+)
+
+// apiKeyScopes are the scopes that can be granted to a key
+var apiKeyScopes = map[string]bool{
+	models.ScopeBookingsRead:  true,
+	models.ScopeBookingsWrite: true,
+}
+
+func NewApiKeyUsecase(repo repository.ApiKeyRepository) ApiKeyUsecase {
+	return &apiKeyUsecase{repo: repo}
+}
+
+// Create a new key of the tenant, the plain key is only returned here
+func (u *apiKeyUsecase) CreateApiKey(tenant string, req dto.ApiKeyRequest) (*dto.ApiKeyCreatedResponse, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidApiKeyRequest)
+	}
+	if len(req.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidApiKeyRequest)
+	}
+	for _, scope := range req.Scopes {
+		if !apiKeyScopes[scope] {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidApiKeyRequest, scope)
+		}
+	}
+	if req.QuotaPerDay < 0 {
+		return nil, fmt.Errorf("%w: quota_per_day must not be negative", ErrInvalidApiKeyRequest)
+	}
+	switch req.Role {
+	case "", utils.RoleStaff:
+		if req.UserID != 0 {
+			return nil, fmt.Errorf("%w: only customer keys have a user_id", ErrInvalidApiKeyRequest)
+		}
+		req.Role = utils.RoleStaff
+	case utils.RoleCustomer:
+		if req.UserID <= 0 {
+			return nil, fmt.Errorf("%w: customer keys need a user_id", ErrInvalidApiKeyRequest)
+		}
+	default:
+		return nil, fmt.Errorf("%w: role must be %s or %s", ErrInvalidApiKeyRequest, utils.RoleStaff, utils.RoleCustomer)
+	}
+
+	plain, prefix, err := utils.GenerateApiKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key")
+	}
+
+	key := u.repo.Create(models.ApiKey{
+		Tenant:      tenant,
+		Name:        req.Name,
+		Prefix:      prefix,
+		Hash:        utils.HashApiKey(plain),
+		Role:        req.Role,
+		UserID:      req.UserID,
+		Scopes:      req.Scopes,
+		QuotaPerDay: req.QuotaPerDay,
+	})
+
+	return &dto.ApiKeyCreatedResponse{
+		ApiKeyResponse: *toApiKeyResponse(key),
+		Key:            plain,
+	}, nil
+}
+
+// Get all keys of the tenant, without the hashes
+func (u *apiKeyUsecase) GetAllApiKeys(tenant string) []*dto.ApiKeyResponse {
+	keys := u.repo.GetAll(tenant)
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+
+	res := []*dto.ApiKeyResponse{}
+	for _, key := range keys {
+		res = append(res, toApiKeyResponse(key))
+	}
+	return res
+}
+
+// Revoke a key of the tenant, revoked keys are kept for auditing
+func (u *apiKeyUsecase) RevokeApiKey(tenant string, id int) error {
+	if err := u.repo.Revoke(tenant, id); err != nil {
+		return ErrApiKeyNotFound
+	}
+	return nil
+}
+
+// ImportApiKey stores an existing plain key of the tenant acting as staff
+// with every scope, used for the API_KEY from the config so deployments keep
+// working
+func (u *apiKeyUsecase) ImportApiKey(tenant string, name string, plain string) error {
+	hash := utils.HashApiKey(plain)
+	if _, exists := u.repo.GetByHash(hash); exists {
+		return nil
+	}
+
+	scopes := []string{}
+	for scope := range apiKeyScopes {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+
+	prefix := plain
+	if len(prefix) > 6 {
+		prefix = prefix[:6]
+	}
+	u.repo.Create(models.ApiKey{
+		Tenant: tenant,
+		Name:   name,
+		Prefix: prefix,
+		Hash:   hash,
+		Role:   utils.RoleStaff,
+		Scopes: scopes,
+	})
+	return nil
+}
+
+// Authenticate finds the key by its hash, checks it is active and counts the
+// request against its daily quota
+func (u *apiKeyUsecase) Authenticate(plain string) (*models.ApiKey, error) {
+	if plain == "" {
+		return nil, ErrApiKeyInvalid
+	}
+
+	key, exists := u.repo.GetByHash(utils.HashApiKey(plain))
+	if !exists {
+		return nil, ErrApiKeyInvalid
+	}
+	if key.IsRevoked() {
+		return nil, ErrApiKeyRevoked
+	}
+
+	key, ok := u.repo.RecordUsage(key.ID, time.Now())
+	if !ok {
+		return nil, ErrApiKeyQuotaExceeded
+	}
+	return key, nil
+}
+
+func toApiKeyResponse(key *models.ApiKey) *dto.ApiKeyResponse {
+	res := &dto.ApiKeyResponse{
+		ID:          key.ID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		Role:        key.Role,
+		UserID:      key.UserID,
+		Scopes:      key.Scopes,
+		QuotaPerDay: key.QuotaPerDay,
+		CreatedAt:   key.CreatedAt.Format(time.RFC3339),
+	}
+	if key.UsageDay == time.Now().UTC().Format("2006-01-02") {
+		res.UsedToday = key.UsageCount
+	}
+	if !key.LastUsedAt.IsZero() {
+		res.LastUsedAt = key.LastUsedAt.Format(time.RFC3339)
+	}
+	if key.IsRevoked() {
+		res.RevokedAt = key.RevokedAt.Format(time.RFC3339)
+	}
+	return res
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// maxBatchOperations is the most operations one batch can have
+const maxBatchOperations = 100
+
+// stagedChange is a booking saved by a batch whose coupon, payments,
+// invoices and history are not settled yet
+type stagedChange struct {
+	// previous is the booking before the change, nil for new bookings
+	previous *dto.BookingResponse
+	booking  *dto.BookingResponse
+	action   string
+	changes  map[string]models.FieldChange
+}
+
+// ExecuteBatch runs the operations in order with the checks of
+// CreateBooking, CancelBooking and PatchBooking. The bookings are saved as
+// the operations run so every operation sees the ones before it, refunds,
+// invoices, waitlist offers and history only follow once the batch is
+// committed. An all or nothing batch with a failed operation is rolled back,
+// a partial batch commits the operations that succeeded. Anonymous callers
+// cannot run batches.
+func (u *bookingUsecase) ExecuteBatch(claims *utils.Claims, req dto.BookingBatchRequest) (*dto.BookingBatchResponse, error) {
+	if claims == nil {
+		return nil, ErrForbidden
+	}
+	if req.Mode == "" {
+		req.Mode = dto.BatchAllOrNothing
+	}
+	if req.Mode != dto.BatchAllOrNothing && req.Mode != dto.BatchPartial {
+		return nil, fmt.Errorf("%w: mode must be %s or %s", ErrInvalidBatch, dto.BatchAllOrNothing, dto.BatchPartial)
+	}
+	if len(req.Operations) == 0 {
+		return nil, fmt.Errorf("%w: no operations", ErrInvalidBatch)
+	}
+	if len(req.Operations) > maxBatchOperations {
+		return nil, fmt.Errorf("%w: a batch can have at most %d operations", ErrInvalidBatch, maxBatchOperations)
+	}
+
+	u.paymentMu.Lock()
+	defer u.paymentMu.Unlock()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	res := &dto.BookingBatchResponse{
+		Mode:    req.Mode,
+		Results: make([]dto.BookingBatchResult, len(req.Operations)),
+	}
+	staged := make([]*stagedChange, len(req.Operations))
+	for i, op := range req.Operations {
+		result := dto.BookingBatchResult{Index: i, Op: op.Op, Status: dto.BatchItemApplied}
+		change, err := u.stageOperation(claims, op)
+		if err != nil {
+			result.Status = dto.BatchItemFailed
+			result.Message = err.Error()
+			var policyErr *PolicyError
+			if errors.As(err, &policyErr) {
+				result.Code = string(policyErr.Code)
+			}
+			res.Failed++
+		}
+		staged[i] = change
+		res.Results[i] = result
+	}
+
+	if req.Mode == dto.BatchAllOrNothing && res.Failed > 0 {
+		for i := len(staged) - 1; i >= 0; i-- {
+			if staged[i] == nil {
+				continue
+			}
+			u.unstage(staged[i])
+			res.Results[i].Status = dto.BatchItemRolledBack
+		}
+		return res, nil
+	}
+	for i, change := range staged {
+		if change == nil {
+			continue
+		}
+		u.commitChange(claims, change)
+		res.Results[i].Booking = change.booking
+		res.Applied++
+	}
+	return res, nil
+}
+
+// stageOperation saves the booking changed by the operation, the caller
+// must hold u.mu
+func (u *bookingUsecase) stageOperation(claims *utils.Claims, op dto.BookingOperation) (*stagedChange, error) {
+	switch op.Op {
+	case dto.BatchCreate:
+		if op.Booking == nil {
+			return nil, fmt.Errorf("%w: create needs a booking", ErrInvalidBatch)
+		}
+		booking, err := u.storeBooking(claims, *op.Booking)
+		if err != nil {
+			return nil, err
+		}
+		return &stagedChange{booking: booking, action: models.HistoryCreated}, nil
+
+	case dto.BatchCancel:
+		current, booking, changes, err := u.canceledBooking(claims, op.ID)
+		if err != nil {
+			return nil, err
+		}
+		return u.stageChange(current, booking, models.HistoryCanceled, changes)
+
+	case dto.BatchUpdate:
+		if op.Patch == nil {
+			return nil, fmt.Errorf("%w: update needs a patch", ErrInvalidBatch)
+		}
+		current, booking, changes, err := u.patchedBooking(claims, op.ID, *op.Patch)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) == 0 {
+			return &stagedChange{previous: current, booking: current}, nil
+		}
+		return u.stageChange(current, booking, models.HistoryUpdated, changes)
+	}
+	return nil, fmt.Errorf("%w: op must be %s, %s or %s", ErrInvalidBatch, dto.BatchCreate, dto.BatchCancel, dto.BatchUpdate)
+}
+
+// stageChange saves a changed booking, the cache follows so the next
+// operations see it
+func (u *bookingUsecase) stageChange(previous, booking *dto.BookingResponse, action string, changes map[string]models.FieldChange) (*stagedChange, error) {
+	if err := u.repo.UpdateBooking(booking); err != nil {
+		return nil, ErrBookingNotFound
+	}
+	u.cache.Set(booking.ID, booking)
+	return &stagedChange{previous: previous, booking: booking, action: action, changes: changes}, nil
+}
+
+// commitChange settles a staged change as the operation would have on its own
+func (u *bookingUsecase) commitChange(claims *utils.Claims, change *stagedChange) {
+	switch {
+	case change.previous == nil:
+		u.recordHistory(claims, change.booking.ID, models.HistoryCreated, nil, "")
+	case len(change.changes) > 0:
+		u.settleChange(claims, change.booking, change.action, change.changes)
+	}
+}
+
+// unstage puts the booking back as it was before the change
+func (u *bookingUsecase) unstage(change *stagedChange) {
+	switch {
+	case change.previous == nil:
+		u.discardBooking(change.booking)
+	case len(change.changes) > 0:
+		previous := *change.previous
+		u.repo.UpdateBooking(&previous)
+		u.cache.Delete(previous.ID)
+	}
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// GetBookingCalendar returns the booking as an iCalendar file, bookings
+// without a slot cannot be put in a calendar
+func (u *bookingUsecase) GetBookingCalendar(claims *utils.Claims, id int) (string, error) {
+	booking, err := u.GetBookingByID(claims, id)
+	if err != nil {
+		return "", err
+	}
+	event, ok := u.calendarEvent(booking)
+	if !ok {
+		return "", ErrNoSlot
+	}
+	return utils.BuildICalendar("", u.cfg.Calendar.Location, []utils.ICalEvent{event}), nil
+}
+
+// CreateCalendarFeed gives the user a new feed token, the token of the
+// previous feed stops working. Customers always create their own feed, only
+// staff can pick the user.
+func (u *bookingUsecase) CreateCalendarFeed(claims *utils.Claims, req dto.CalendarFeedRequest) (*dto.CalendarFeedResponse, error) {
+	if claims == nil {
+		return nil, ErrForbidden
+	}
+	if !claims.IsStaff() {
+		req.UserID = claims.Id
+	}
+	if req.UserID <= 0 {
+		return nil, fmt.Errorf("%w: user_id is required", ErrInvalidCalendarFeed)
+	}
+
+	token, err := utils.GenerateCalendarToken()
+	if err != nil {
+		return nil, err
+	}
+	feed := u.calendars.Save(models.CalendarFeed{
+		UserID:    req.UserID,
+		TokenHash: utils.HashCalendarToken(token),
+	})
+	return &dto.CalendarFeedResponse{
+		UserID:    feed.UserID,
+		Token:     token,
+		URL:       "/api/calendar/" + token + ".ics",
+		CreatedAt: feed.CreatedAt.Format(time.RFC3339),
+	}, nil
+}
+
+// GetCalendarFeed returns every booking with a slot of the user the token
+// belongs to. Canceled and rejected bookings stay in the feed as cancelled
+// events so subscribed calendars remove them.
+func (u *bookingUsecase) GetCalendarFeed(token string) (string, error) {
+	feed, exists := u.calendars.GetByTokenHash(utils.HashCalendarToken(token))
+	if !exists {
+		return "", ErrCalendarFeedNotFound
+	}
+
+	events := []utils.ICalEvent{}
+	for _, booking := range u.repo.GetByUserID(feed.UserID) {
+		if event, ok := u.calendarEvent(booking); ok {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+	return utils.BuildICalendar(u.cfg.Calendar.Name, u.cfg.Calendar.Location, events), nil
+}
+
+// calendarEvent turns a booking with a slot into a VEVENT. The sequence is
+// the number of changes in the booking history, so calendar apps pick up
+// reschedules and cancellations.
+func (u *bookingUsecase) calendarEvent(booking *dto.BookingResponse) (utils.ICalEvent, bool) {
+	if booking.StartAt.IsZero() || booking.EndAt.IsZero() {
+		return utils.ICalEvent{}, false
+	}
+
+	summary := fmt.Sprintf("Booking #%d", booking.ID)
+	if service, exists := u.services.GetByID(booking.ServiceID); exists {
+		summary = service.Name
+	}
+	description := []string{fmt.Sprintf("Booking #%d", booking.ID), "Status: " + booking.Status}
+	if booking.Notes != "" {
+		description = append(description, booking.Notes)
+	}
+
+	sequence := 0
+	for _, entry := range u.history.GetByBookingID(booking.ID) {
+		if entry.Action != models.HistoryCreated && entry.Action != models.HistoryBalanceReminder {
+			sequence++
+		}
+	}
+
+	return utils.ICalEvent{
+		UID:          fmt.Sprintf("booking-%d@%s", booking.ID, u.cfg.Calendar.Domain),
+		Sequence:     sequence,
+		Start:        booking.StartAt,
+		End:          booking.EndAt,
+		Summary:      summary,
+		Description:  strings.Join(description, "\n"),
+		Status:       calendarStatus(booking.Status),
+		Created:      booking.CreatedAt,
+		LastModified: booking.UpdatedAt,
+	}, true
+}
+
+func calendarStatus(status string) string {
+	switch status {
+	case models.StatusConfirmed:
+		return utils.ICalConfirmed
+	case models.StatusCanceled, models.StatusRejected:
+		return utils.ICalCancelled
+	}
+	return utils.ICalTentative
+}
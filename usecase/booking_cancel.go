@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// paidAmount is what the customer has paid for the booking, confirmed
+// bookings are paid in full and deposit_paid bookings their deposit
+func paidAmount(booking *dto.BookingResponse) models.Money {
+	switch booking.Status {
+	case models.StatusConfirmed:
+		return totalPrice(booking)
+	case models.StatusDepositPaid:
+		return models.NewMoney(booking.Deposit.Amount, booking.Price.Currency)
+	}
+	return models.NewMoney(0, booking.Price.Currency)
+}
+
+// quoteCancellation works out the fee and refund of canceling the booking at
+// now. Bookings without a slot are treated as starting now.
+func (u *bookingUsecase) quoteCancellation(booking *dto.BookingResponse, now time.Time) *dto.CancellationQuote {
+	policy := u.cfg.CancellationPolicyFor(booking.ServiceID)
+	quote := &dto.CancellationQuote{
+		BookingID:     booking.ID,
+		Paid:          paidAmount(booking),
+		NonRefundable: policy.NonRefundable,
+	}
+
+	var untilStart time.Duration
+	if !booking.StartAt.IsZero() {
+		untilStart = booking.StartAt.Sub(now)
+		if !policy.NonRefundable && policy.FreeBefore > 0 {
+			quote.FreeUntil = booking.StartAt.Add(-policy.FreeBefore)
+		}
+	}
+
+	quote.FeePercent = policy.FeePercent(untilStart)
+	quote.Fee = quote.Paid.Percent(quote.FeePercent)
+	quote.Refund = quote.Paid.Sub(quote.Fee)
+	return quote
+}
+
+// applyCancellation cancels the booking and stores the fee and refund, the
+// changed fields are added to changes
+func (u *bookingUsecase) applyCancellation(booking *dto.BookingResponse, now time.Time, changes map[string]models.FieldChange) {
+	quote := u.quoteCancellation(booking, now)
+
+	changes["status"] = models.FieldChange{From: booking.Status, To: models.StatusCanceled}
+	if !quote.Paid.IsZero() {
+		changes["cancellation_fee"] = models.FieldChange{From: formatPrice(booking.CancellationFee, quote.Paid.Currency), To: formatPrice(quote.Fee, quote.Paid.Currency)}
+		changes["refund_amount"] = models.FieldChange{From: formatPrice(booking.RefundAmount, quote.Paid.Currency), To: formatPrice(quote.Refund, quote.Paid.Currency)}
+	}
+	booking.Status = models.StatusCanceled
+	booking.CancellationFee = quote.Fee
+	booking.RefundAmount = quote.Refund
+}
+
+// QuoteCancellation tells the caller what canceling the booking now would
+// cost without canceling it
+func (u *bookingUsecase) QuoteCancellation(claims *utils.Claims, id int) (*dto.CancellationQuote, error) {
+	booking, err := u.GetBookingByID(claims, id)
+	if err != nil {
+		return nil, err
+	}
+	if !models.CanTransition(booking.Status, models.StatusCanceled) {
+		return nil, fmt.Errorf("%w: booking is %s", ErrInvalidTransition, booking.Status)
+	}
+	return u.quoteCancellation(booking, time.Now()), nil
+}
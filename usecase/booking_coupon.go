@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/repository"
+)
+
+// RuleCoupon is the price adjustment of a coupon
+const RuleCoupon = "coupon"
+
+// couponFor finds the coupon with the code and checks it can be used for the
+// service at now. The usage limits are checked when it is redeemed.
+func (u *bookingUsecase) couponFor(code string, serviceID int, price models.Money, now time.Time) (*models.Coupon, error) {
+	coupon, exists := u.coupons.GetByCode(normalizeCouponCode(code))
+	if !exists {
+		return nil, fmt.Errorf("%w: unknown code %s", ErrCouponInvalid, code)
+	}
+	if !coupon.IsValidAt(now) {
+		return nil, fmt.Errorf("%w: %s is not valid now", ErrCouponInvalid, coupon.Code)
+	}
+	if !coupon.AppliesTo(serviceID, price) {
+		return nil, fmt.Errorf("%w: %s does not apply to this service", ErrCouponInvalid, coupon.Code)
+	}
+	return coupon, nil
+}
+
+// redeemCoupon takes one redemption of the coupon for the user
+func (u *bookingUsecase) redeemCoupon(code string, userID, serviceID int, price models.Money, now time.Time) (*models.CouponRedemption, error) {
+	redemption, err := u.coupons.Redeem(code, userID, serviceID, price, now)
+	if errors.Is(err, repository.ErrCouponUsedUp) {
+		return nil, fmt.Errorf("%w: %s", ErrCouponUsedUp, code)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCouponInvalid, code)
+	}
+	return redemption, nil
+}
+
+// applyDiscount takes the coupon discount off the breakdown, never more
+// than the total
+func applyDiscount(breakdown *dto.PriceBreakdown, code string, discount models.Money) *dto.PriceBreakdown {
+	discounted := *breakdown
+	if discount.Cmp(discounted.Total) > 0 {
+		discount = discounted.Total
+	}
+	discounted.Adjustments = append(append([]dto.PriceAdjustment{}, breakdown.Adjustments...), dto.PriceAdjustment{
+		Rule:        RuleCoupon,
+		Description: "coupon " + code,
+		Amount:      discount.Mul(-1),
+	})
+	discounted.Total = discounted.Total.Sub(discount)
+	return &discounted
+}
+
+// releaseCoupon gives the coupon redemption of a booking back once the
+// booking is canceled or rejected
+func (u *bookingUsecase) releaseCoupon(booking *dto.BookingResponse) {
+	if booking.CouponCode == "" {
+		return
+	}
+	if booking.Status != models.StatusCanceled && booking.Status != models.StatusRejected {
+		return
+	}
+	u.coupons.Release(booking.ID, time.Now())
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// applyDeposit works out the deposit and balance due date of a high value
+// booking that is not paid yet. Bookings that paid their deposit keep it and
+// only get a new due date when they move.
+func (u *bookingUsecase) applyDeposit(booking *dto.BookingResponse, now time.Time) {
+	switch booking.Status {
+	case "", models.StatusPending:
+		percent := u.cfg.DepositPercentFor(booking.ServiceID)
+		if !booking.HighValue || percent <= 0 || percent >= 100 {
+			booking.Deposit = models.Money{}
+			booking.BalanceDueAt = time.Time{}
+			booking.BalanceRemindedAt = time.Time{}
+			return
+		}
+		booking.Deposit = booking.Price.Percent(percent)
+	case models.StatusDepositPaid:
+	default:
+		return
+	}
+
+	if due := u.balanceDueAt(booking, now); !due.Equal(booking.BalanceDueAt) {
+		booking.BalanceDueAt = due
+		booking.BalanceRemindedAt = time.Time{}
+	}
+}
+
+// balanceDueAt is BalanceDueBefore the start of the booking, or the start
+// itself when the booking was made later than that. Bookings without a slot
+// are due BalanceDueWithin after they were made.
+func (u *bookingUsecase) balanceDueAt(booking *dto.BookingResponse, now time.Time) time.Time {
+	policy := u.cfg.Deposit
+	if booking.StartAt.IsZero() {
+		created := booking.CreatedAt
+		if created.IsZero() {
+			created = utc(now)
+		}
+		return created.Add(policy.BalanceDueWithin)
+	}
+	due := booking.StartAt.Add(-policy.BalanceDueBefore)
+	if due.Before(now) {
+		due = booking.StartAt
+	}
+	return due
+}
+
+// totalPrice is everything charged for the booking
+func totalPrice(booking *dto.BookingResponse) models.Money {
+	return booking.Price.Add(booking.RescheduleFees)
+}
+
+// ledgerTotals adds up the charges and refunds in the ledger of a booking,
+// entries in another currency are left out
+func (u *bookingUsecase) ledgerTotals(bookingID int, currency string) (charged, refunded models.Money) {
+	charged, refunded = models.NewMoney(0, currency), models.NewMoney(0, currency)
+	for _, entry := range u.payments.GetLedger(bookingID) {
+		if entry.Amount.Currency != currency {
+			continue
+		}
+		if entry.Kind == models.LedgerRefund {
+			refunded = refunded.Add(entry.Amount)
+		} else {
+			charged = charged.Add(entry.Amount)
+		}
+	}
+	return charged, refunded
+}
+
+// PayBalance charges what is left of the price of a booking that paid its
+// deposit, a captured balance confirms the booking. A booking with nothing
+// left to pay after a price cut is confirmed without a payment and the
+// response is nil.
+func (u *bookingUsecase) PayBalance(claims *utils.Claims, id int) (*dto.PaymentResponse, error) {
+	u.paymentMu.Lock()
+	defer u.paymentMu.Unlock()
+
+	booking, err := u.GetBookingByID(claims, id)
+	if err != nil {
+		return nil, err
+	}
+	if booking.Status != models.StatusDepositPaid {
+		return nil, fmt.Errorf("%w: booking is %s", ErrInvalidTransition, booking.Status)
+	}
+	for _, payment := range u.payments.GetByBookingID(id) {
+		if payment.Kind == models.PaymentKindBalance && payment.IsOpen() {
+			return toPaymentResponse(payment), nil
+		}
+	}
+
+	charged, refunded := u.ledgerTotals(id, booking.Price.Currency)
+	balance := totalPrice(booking).Sub(charged.Sub(refunded))
+	if balance.Amount <= 0 {
+		return nil, u.UpdateBookingStatus(id, models.StatusConfirmed)
+	}
+	return u.charge(booking, models.PaymentKindBalance, balance)
+}
+
+// GetPaymentLedger returns every charge and refund of a booking and what is
+// left to pay
+func (u *bookingUsecase) GetPaymentLedger(claims *utils.Claims, id int) (*dto.PaymentLedgerResponse, error) {
+	booking, err := u.GetBookingByID(claims, id)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := booking.Price.Currency
+	charged, refunded := u.ledgerTotals(id, currency)
+	res := &dto.PaymentLedgerResponse{
+		BookingID:    id,
+		Entries:      []*dto.LedgerEntryResponse{},
+		Charged:      charged,
+		Refunded:     refunded,
+		Paid:         charged.Sub(refunded),
+		BalanceDue:   models.NewMoney(0, currency),
+		BalanceDueAt: booking.BalanceDueAt,
+	}
+	if booking.Status == models.StatusDepositPaid {
+		if due := totalPrice(booking).Sub(res.Paid); due.Amount > 0 {
+			res.BalanceDue = due
+		}
+	}
+	for _, entry := range u.payments.GetLedger(id) {
+		res.Entries = append(res.Entries, &dto.LedgerEntryResponse{
+			ID:          entry.ID,
+			PaymentID:   entry.PaymentID,
+			PaymentKind: entry.PaymentKind,
+			Kind:        entry.Kind,
+			Reference:   entry.Reference,
+			Amount:      entry.Amount,
+			CreatedAt:   entry.CreatedAt,
+		})
+	}
+	return res, nil
+}
+
+// checkDueBalances reminds customers of balances due within ReminderBefore
+// and cancels bookings whose balance was not paid by the due date, their
+// deposit is kept as the cancellation fee
+func (u *bookingUsecase) checkDueBalances(now time.Time) {
+	u.paymentMu.Lock()
+	defer u.paymentMu.Unlock()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, current := range u.repo.GetAll() {
+		if current.Status != models.StatusDepositPaid {
+			continue
+		}
+		due := current.BalanceDueAt
+		if due.IsZero() {
+			continue
+		}
+
+		booking := *current
+		if now.After(due) {
+			booking.Status = models.StatusCanceled
+			booking.CancellationFee = booking.Deposit
+			booking.RefundAmount = models.NewMoney(0, booking.Price.Currency)
+			if err := u.repo.UpdateBooking(&booking); err != nil {
+				continue
+			}
+			u.cache.Set(booking.ID, &booking)
+			u.releaseCoupon(&booking)
+			u.settlePayments(&booking)
+			u.syncInvoices(&booking)
+			u.offerFreedSlot(&booking)
+			u.recordHistory(nil, booking.ID, models.HistoryExpired, map[string]models.FieldChange{
+				"status":           {From: models.StatusDepositPaid, To: models.StatusCanceled},
+				"cancellation_fee": {From: formatPrice(current.CancellationFee, booking.Deposit.Currency), To: formatPrice(booking.Deposit, booking.Deposit.Currency)},
+			}, "balance not paid by "+due.Format(time.RFC3339))
+			continue
+		}
+
+		if booking.BalanceRemindedAt.IsZero() && due.Sub(now) <= u.cfg.Deposit.ReminderBefore {
+			booking.BalanceRemindedAt = utc(now)
+			if err := u.repo.UpdateBooking(&booking); err != nil {
+				continue
+			}
+			u.cache.Set(booking.ID, &booking)
+			log.Printf("Reminding user %d that the balance of booking %d is due at %s", booking.UserID, booking.ID, due.Format(time.RFC3339))
+			u.recordHistory(nil, booking.ID, models.HistoryBalanceReminder, nil, "balance due at "+due.Format(time.RFC3339))
+		}
+	}
+}
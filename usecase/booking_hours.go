@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+// checkOpen makes sure the service is open for the whole slot, in its own
+// time zone. A slot may run over midnight when the service is open until
+// 24:00 and from 00:00 the next day.
+func (u *bookingUsecase) checkOpen(service *models.Service, start, end time.Time) error {
+	location := u.cfg.Timezones.ServiceLocation(service.ID)
+	for cursor := start; cursor.Before(end); {
+		local := cursor.In(location)
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+		hours, override := hoursOn(u.hours, service.ID, day)
+
+		covered := false
+		for _, h := range hours {
+			open := time.Date(day.Year(), day.Month(), day.Day(), 0, h.Open, 0, 0, location)
+			closing := time.Date(day.Year(), day.Month(), day.Day(), 0, h.Close, 0, 0, location)
+			if !cursor.Before(open) && cursor.Before(closing) {
+				cursor, covered = closing, true
+				break
+			}
+		}
+		if !covered {
+			return closedError(day, hours, override)
+		}
+	}
+	return nil
+}
+
+// closedError tells the client when the service is open on the day
+func closedError(day time.Time, hours []models.OpeningHours, override *models.HoursOverride) error {
+	date := day.Format("Monday " + models.DateLayout)
+	if len(hours) == 0 {
+		if override != nil && override.Reason != "" {
+			return fmt.Errorf("%w: closed on %s for %s", ErrServiceClosed, date, override.Reason)
+		}
+		return fmt.Errorf("%w: closed on %s", ErrServiceClosed, date)
+	}
+	open := []string{}
+	for _, h := range hours {
+		open = append(open, h.String())
+	}
+	return fmt.Errorf("%w: open %s on %s (%s)", ErrServiceClosed, strings.Join(open, ", "), date, day.Location())
+}
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// maxImportRows is the most rows one import can have
+const maxImportRows = 1000
+
+// ImportBookings books every row like a new booking and moves it to the
+// status of the row, a migration saves the rows as they were instead (see
+// stageMigration). Rows are validated in order, so a row also sees the
+// slots and coupons taken by the rows before it. An atomic import only
+// commits when every row is valid, a best effort import commits the valid
+// rows and a dry run commits nothing. Only staff can import bookings.
+func (u *bookingUsecase) ImportBookings(claims *utils.Claims, rows []dto.BookingImportRow, options dto.BookingImportOptions) (*dto.BookingImportResponse, error) {
+	if !isStaff(claims) {
+		return nil, ErrForbidden
+	}
+	if options.Mode == "" {
+		options.Mode = dto.ImportAtomic
+	}
+	if options.Mode != dto.ImportAtomic && options.Mode != dto.ImportBestEffort {
+		return nil, fmt.Errorf("%w: mode must be %s or %s", ErrInvalidImport, dto.ImportAtomic, dto.ImportBestEffort)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no rows to import", ErrInvalidImport)
+	}
+	if len(rows) > maxImportRows {
+		return nil, fmt.Errorf("%w: an import can have at most %d rows", ErrInvalidImport, maxImportRows)
+	}
+
+	// the rows are saved while they are validated and taken out again when
+	// they are not committed, nothing else can change bookings meanwhile
+	u.paymentMu.Lock()
+	defer u.paymentMu.Unlock()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	res := &dto.BookingImportResponse{
+		Mode:    options.Mode,
+		DryRun:  options.DryRun,
+		Migrate: options.Migrate,
+		Total:   len(rows),
+		Rows:    make([]dto.BookingImportResult, len(rows)),
+	}
+	stage := u.stageImport
+	if options.Migrate {
+		stage = u.stageMigration
+	}
+	staged := make([]*dto.BookingResponse, len(rows))
+	for i, row := range rows {
+		result := dto.BookingImportResult{Line: row.Line, Status: dto.ImportRowValid}
+		booking, err := stage(claims, row)
+		if err != nil {
+			result.Status = dto.ImportRowFailed
+			result.Message = err.Error()
+			var policyErr *PolicyError
+			if errors.As(err, &policyErr) {
+				result.Code = string(policyErr.Code)
+			}
+			res.Failed++
+		}
+		staged[i] = booking
+		res.Rows[i] = result
+	}
+
+	if options.DryRun || (options.Mode == dto.ImportAtomic && res.Failed > 0) {
+		u.rollbackImport(staged)
+		return res, nil
+	}
+	for i, booking := range staged {
+		if booking == nil {
+			continue
+		}
+		u.commitImport(claims, booking, options.Migrate)
+		res.Rows[i].Status = dto.ImportRowImported
+		res.Rows[i].Booking = booking
+		res.Imported++
+	}
+	return res, nil
+}
+
+// stageImport saves the booking of a row without its history, the caller
+// must hold u.mu
+func (u *bookingUsecase) stageImport(claims *utils.Claims, row dto.BookingImportRow) (*dto.BookingResponse, error) {
+	if row.Error != "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImport, row.Error)
+	}
+	status := row.Status
+	if status == "" {
+		status = models.StatusPending
+	}
+	if !models.IsBookingStatus(status) {
+		return nil, ErrInvalidStatus
+	}
+	if status != models.StatusPending && !models.CanTransition(models.StatusPending, status) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, models.StatusPending, status)
+	}
+
+	booking, err := u.storeBooking(claims, row.BookingRequest)
+	if err != nil {
+		return nil, err
+	}
+	if status != models.StatusPending {
+		booking.Status = status
+		if err := u.repo.UpdateBooking(booking); err != nil {
+			return nil, err
+		}
+		u.cache.Set(booking.ID, booking)
+	}
+	return booking, nil
+}
+
+// stageMigration saves the booking of a row as it was in the system it is
+// migrated from. Past slots, every status and the times of the row are
+// kept, the price of the row is kept and its coupon is not redeemed again.
+// The booking policies are not checked, only active bookings of future
+// slots need room in the slot. The caller must hold u.mu.
+func (u *bookingUsecase) stageMigration(claims *utils.Claims, row dto.BookingImportRow) (*dto.BookingResponse, error) {
+	if row.Error != "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImport, row.Error)
+	}
+	status := row.Status
+	if status == "" {
+		status = models.StatusPending
+	}
+	if !models.IsBookingStatus(status) {
+		return nil, ErrInvalidStatus
+	}
+	service, exists := u.services.GetByID(row.ServiceID)
+	if !exists {
+		return nil, ErrServiceNotFound
+	}
+	duration, err := bookingDuration(service, row.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	booking := dto.BookingResponse{
+		UserID:    row.UserID,
+		ServiceID: row.ServiceID,
+		Status:    status,
+		Notes:     row.Notes,
+	}
+	if duration != service.Duration {
+		booking.Duration = duration.String()
+	}
+	if booking.CreatedAt, err = importTime("created_at", row.CreatedAt); err != nil {
+		return nil, err
+	}
+	if booking.UpdatedAt, err = importTime("updated_at", row.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	var start time.Time
+	if row.StartAt != "" {
+		start, err = parseStart(row.StartAt)
+		if err != nil {
+			return nil, err
+		}
+		end := start.Add(duration)
+		if start.After(time.Now()) && models.IsActiveStatus(status) {
+			if err := u.checkCapacity(service, start, end, 0); err != nil {
+				return nil, err
+			}
+			if booking.Resources, err = u.assignResources(service, start, end, &booking); err != nil {
+				return nil, err
+			}
+		}
+		booking.StartAt = start
+		booking.EndAt = end
+	}
+
+	breakdown := u.pricing.Price(service, row.UserID, start, duration)
+	if !row.Price.IsZero() && row.Price != breakdown.Total {
+		breakdown, err = overridePrice(breakdown, row.Price)
+		if err != nil {
+			return nil, err
+		}
+	}
+	booking.Price = breakdown.Total
+	booking.PriceBreakdown = breakdown
+	booking.HighValue = u.isHighValue(breakdown.Total)
+
+	created := u.repo.Create(booking)
+	u.cache.Set(created.ID, created)
+	return created, nil
+}
+
+// importTime parses an RFC3339 time of a migrated row, empty is the zero time
+func importTime(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be an RFC3339 time", ErrInvalidImport, name)
+	}
+	return utc(t), nil
+}
+
+// commitImport records the history of an imported booking and settles its
+// coupon and invoices as a status change would. A migrated booking was
+// paid and invoiced in the system it comes from, only its history is
+// recorded.
+func (u *bookingUsecase) commitImport(claims *utils.Claims, booking *dto.BookingResponse, migrated bool) {
+	if migrated {
+		u.recordHistory(claims, booking.ID, models.HistoryCreated, nil, "migrated")
+		return
+	}
+	u.recordHistory(claims, booking.ID, models.HistoryCreated, nil, "imported")
+	if booking.Status == models.StatusPending {
+		return
+	}
+	u.recordHistory(claims, booking.ID, models.HistoryStatusChanged, map[string]models.FieldChange{
+		"status": {From: models.StatusPending, To: booking.Status},
+	}, "imported")
+	u.releaseCoupon(booking)
+	u.syncInvoices(booking)
+}
+
+// rollbackImport takes staged bookings out again, latest first so the ids
+// are handed out again in order
+func (u *bookingUsecase) rollbackImport(staged []*dto.BookingResponse) {
+	for i := len(staged) - 1; i >= 0; i-- {
+		if staged[i] != nil {
+			u.discardBooking(staged[i])
+		}
+	}
+}
+
+// discardBooking takes out a booking saved by storeBooking that was never
+// committed, with its coupon redemption
+func (u *bookingUsecase) discardBooking(booking *dto.BookingResponse) {
+	if booking.CouponCode != "" {
+		u.coupons.Release(booking.ID, time.Now())
+	}
+	u.cache.Delete(booking.ID)
+	u.repo.Delete(booking.ID)
+}
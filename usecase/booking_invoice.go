@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// owedAmount is what the customer owes for the booking: the paid amount
+// while confirmed and the cancellation fee once canceled
+func owedAmount(booking *dto.BookingResponse) models.Money {
+	if booking.Status == models.StatusCanceled {
+		return models.NewMoney(booking.CancellationFee.Amount, booking.Price.Currency)
+	}
+	return paidAmount(booking)
+}
+
+// syncInvoices issues the documents that bring the invoiced amount of the
+// booking in line with what is owed: an invoice when it is confirmed, its
+// deposit is captured or a fee is kept, another invoice for later charges and
+// a credit note for refunds and price cuts. It is called with u.mu held after
+// the booking is saved.
+func (u *bookingUsecase) syncInvoices(booking *dto.BookingResponse) {
+	documents := u.invoices.GetByBookingID(booking.ID)
+	invoiced := models.NewMoney(0, booking.Price.Currency)
+	for _, document := range documents {
+		if document.Kind == models.InvoiceKindCreditNote {
+			invoiced = invoiced.Sub(document.Total)
+		} else {
+			invoiced = invoiced.Add(document.Total)
+		}
+	}
+	difference := owedAmount(booking).Sub(invoiced)
+	if difference.IsZero() {
+		return
+	}
+
+	invoice := models.Invoice{
+		Kind:      models.InvoiceKindInvoice,
+		BookingID: booking.ID,
+		UserID:    booking.UserID,
+		ServiceID: booking.ServiceID,
+		TaxRate:   u.cfg.VATRateFor(booking.ServiceID),
+		Net:       models.NewMoney(0, booking.Price.Currency),
+		Tax:       models.NewMoney(0, booking.Price.Currency),
+		Total:     models.NewMoney(0, booking.Price.Currency),
+	}
+	switch {
+	case len(documents) == 0 && booking.Status == models.StatusConfirmed:
+		invoice.AddLine(models.NewInvoiceLine(fmt.Sprintf("Booking #%d, service #%d", booking.ID, booking.ServiceID), booking.Price, invoice.TaxRate))
+		if !booking.RescheduleFees.IsZero() {
+			invoice.AddLine(models.NewInvoiceLine("Reschedule fees", booking.RescheduleFees, invoice.TaxRate))
+		}
+	case len(documents) == 0 && booking.Status == models.StatusDepositPaid:
+		invoice.AddLine(models.NewInvoiceLine(fmt.Sprintf("Deposit for booking #%d, service #%d", booking.ID, booking.ServiceID), difference, invoice.TaxRate))
+	case len(documents) == 0:
+		invoice.AddLine(models.NewInvoiceLine(fmt.Sprintf("Cancellation fee for booking #%d, service #%d", booking.ID, booking.ServiceID), difference, invoice.TaxRate))
+	case difference.Amount > 0:
+		invoice.AddLine(models.NewInvoiceLine(fmt.Sprintf("Additional charges for booking #%d", booking.ID), difference, invoice.TaxRate))
+	default:
+		// credit notes use the rate of the invoice they correct
+		description := fmt.Sprintf("Price reduction for booking #%d", booking.ID)
+		if booking.Status == models.StatusCanceled {
+			description = fmt.Sprintf("Refund of canceled booking #%d", booking.ID)
+		}
+		invoice.Kind = models.InvoiceKindCreditNote
+		invoice.RefersTo = documents[0].Number
+		invoice.TaxRate = documents[0].TaxRate
+		invoice.AddLine(models.NewInvoiceLine(description, difference.Mul(-1), invoice.TaxRate))
+	}
+	u.invoices.Issue(invoice)
+}
+
+// GetInvoice returns the invoices and credit notes of a booking
+func (u *bookingUsecase) GetInvoice(claims *utils.Claims, id int) (*dto.BookingInvoiceResponse, error) {
+	booking, err := u.GetBookingByID(claims, id)
+	if err != nil {
+		return nil, err
+	}
+	documents := u.invoices.GetByBookingID(id)
+	if len(documents) == 0 {
+		return nil, ErrInvoiceNotFound
+	}
+
+	res := &dto.BookingInvoiceResponse{
+		BookingID:   id,
+		Invoices:    []*dto.InvoiceResponse{},
+		CreditNotes: []*dto.InvoiceResponse{},
+		Balance:     models.NewMoney(0, booking.Price.Currency),
+	}
+	for _, document := range documents {
+		if document.Kind == models.InvoiceKindCreditNote {
+			res.CreditNotes = append(res.CreditNotes, u.toInvoiceResponse(document))
+			res.Balance = res.Balance.Sub(document.Total)
+		} else {
+			res.Invoices = append(res.Invoices, u.toInvoiceResponse(document))
+			res.Balance = res.Balance.Add(document.Total)
+		}
+	}
+	return res, nil
+}
+
+func (u *bookingUsecase) toInvoiceResponse(invoice *models.Invoice) *dto.InvoiceResponse {
+	location := u.cfg.Invoice.Location
+	if location == nil {
+		location = time.UTC
+	}
+	res := &dto.InvoiceResponse{
+		Number:        invoice.Number,
+		Kind:          invoice.Kind,
+		BookingID:     invoice.BookingID,
+		UserID:        invoice.UserID,
+		ServiceID:     invoice.ServiceID,
+		IssuerName:    u.cfg.Invoice.IssuerName,
+		IssuerTaxID:   u.cfg.Invoice.IssuerTaxID,
+		IssuerAddress: u.cfg.Invoice.IssuerAddress,
+		TaxRate:       invoice.TaxRate,
+		Net:           invoice.Net,
+		Tax:           invoice.Tax,
+		Total:         invoice.Total,
+		RefersTo:      invoice.RefersTo,
+		IssuedAt:      invoice.IssuedAt.In(location).Format(time.RFC3339),
+	}
+	for _, line := range invoice.Lines {
+		res.Lines = append(res.Lines, dto.InvoiceLineResponse{
+			Description: line.Description,
+			Net:         line.Net,
+			Tax:         line.Tax,
+			Total:       line.Total,
+		})
+	}
+	return res
+}
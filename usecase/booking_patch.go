@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// maxNotesLength is the longest notes a booking can have, in characters
+const maxNotesLength = 500
+
+// PatchBooking applies a merge patch to a booking. Every field is checked
+// before anything is saved, so a patch is applied completely or not at all.
+//   - notes: owner or staff
+//   - service_id: owner or staff, pending bookings only, the slot is kept and
+//     capacity checked again
+//   - price: staff only
+//   - status: staff may make any move of the state machine, customers may
+//     only cancel, canceling refunds as CancelBooking does
+func (u *bookingUsecase) PatchBooking(claims *utils.Claims, id int, patch dto.BookingPatch) (*dto.BookingResponse, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	current, booking, changes, err := u.patchedBooking(claims, id, patch)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return current, nil
+	}
+	if err := u.repo.UpdateBooking(booking); err != nil {
+		return nil, ErrBookingNotFound
+	}
+	u.cache.Set(booking.ID, booking)
+	u.settleChange(claims, booking, models.HistoryUpdated, changes)
+
+	return booking, nil
+}
+
+// patchedBooking applies the patch to a copy of the booking without saving
+// it, the caller must hold u.mu
+func (u *bookingUsecase) patchedBooking(claims *utils.Claims, id int, patch dto.BookingPatch) (*dto.BookingResponse, *dto.BookingResponse, map[string]models.FieldChange, error) {
+	if patch.IsEmpty() {
+		return nil, nil, nil, fmt.Errorf("%w: nothing to change", ErrInvalidPatch)
+	}
+	staff := isStaff(claims)
+	if patch.Price != nil && !staff {
+		return nil, nil, nil, fmt.Errorf("%w: price", ErrFieldForbidden)
+	}
+
+	current, err := u.GetBookingByID(claims, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	booking := *current
+	changes := map[string]models.FieldChange{}
+
+	if patch.ClearNotes && booking.Notes != "" {
+		changes["notes"] = models.FieldChange{From: booking.Notes}
+		booking.Notes = ""
+	}
+	if patch.Notes != nil {
+		if utf8.RuneCountInString(*patch.Notes) > maxNotesLength {
+			return nil, nil, nil, fmt.Errorf("%w: notes can be at most %d characters", ErrInvalidPatch, maxNotesLength)
+		}
+		if *patch.Notes != booking.Notes {
+			changes["notes"] = models.FieldChange{From: booking.Notes, To: *patch.Notes}
+			booking.Notes = *patch.Notes
+		}
+	}
+
+	if patch.ServiceID != nil && *patch.ServiceID != booking.ServiceID {
+		if booking.Status != models.StatusPending {
+			return nil, nil, nil, fmt.Errorf("%w: the service of a %s booking cannot be changed", ErrInvalidPatch, booking.Status)
+		}
+		service, exists := u.services.GetByID(*patch.ServiceID)
+		if !exists {
+			return nil, nil, nil, ErrServiceNotFound
+		}
+		if err := u.moveBooking(&booking, service, booking.StartAt, time.Now(), changes); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// the price is applied after the service so staff can move and reprice at once
+	if patch.Price != nil {
+		breakdown := booking.PriceBreakdown
+		if breakdown == nil {
+			breakdown = &dto.PriceBreakdown{ServiceID: booking.ServiceID, Total: booking.Price}
+		}
+		overridden, err := overridePrice(breakdown, *patch.Price)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		booking.Price = overridden.Total
+		booking.PriceBreakdown = overridden
+		booking.HighValue = u.isHighValue(booking.Price)
+		u.applyDeposit(&booking, time.Now())
+		if booking.Price == current.Price {
+			delete(changes, "price")
+		} else {
+			changes["price"] = models.FieldChange{From: formatPrice(current.Price, booking.Price.Currency), To: formatPrice(booking.Price, booking.Price.Currency)}
+		}
+	}
+
+	if patch.Status != nil && *patch.Status != booking.Status {
+		status := *patch.Status
+		if !models.IsBookingStatus(status) {
+			return nil, nil, nil, ErrInvalidStatus
+		}
+		if !staff && status != models.StatusCanceled {
+			return nil, nil, nil, fmt.Errorf("%w: status", ErrFieldForbidden)
+		}
+		if !models.CanTransition(booking.Status, status) {
+			return nil, nil, nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, booking.Status, status)
+		}
+		if status == models.StatusCanceled {
+			u.applyCancellation(&booking, time.Now(), changes)
+		} else {
+			changes["status"] = models.FieldChange{From: booking.Status, To: status}
+			booking.Status = status
+		}
+	}
+
+	return current, &booking, changes, nil
+}
@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// StartPayment authorizes and captures the price of a pending booking, or
+// its deposit when it has one. The authorization is the credit check of the
+// provider. A captured payment confirms the booking or marks its deposit
+// paid, a declined one rejects it and a pending capture waits for the
+// callback of the provider.
+func (u *bookingUsecase) StartPayment(id int) (*dto.PaymentResponse, error) {
+	u.paymentMu.Lock()
+	defer u.paymentMu.Unlock()
+
+	booking, exists := u.repo.GetByID(id)
+	if !exists {
+		return nil, ErrBookingNotFound
+	}
+	if booking.Status != models.StatusPending {
+		return nil, fmt.Errorf("%w: booking is %s", ErrInvalidTransition, booking.Status)
+	}
+	for _, payment := range u.payments.GetByBookingID(id) {
+		if payment.IsOpen() {
+			return toPaymentResponse(payment), nil
+		}
+	}
+
+	if !booking.Deposit.IsZero() {
+		return u.charge(booking, models.PaymentKindDeposit, booking.Deposit)
+	}
+	return u.charge(booking, models.PaymentKindFull, booking.Price)
+}
+
+// charge authorizes and captures amount for the booking, the caller must
+// hold u.paymentMu
+func (u *bookingUsecase) charge(booking *dto.BookingResponse, kind string, amount models.Money) (*dto.PaymentResponse, error) {
+	started := time.Now()
+	result, err := u.provider.Authorize(utils.PaymentRequest{
+		BookingID: booking.ID,
+		UserID:    booking.UserID,
+		Amount:    amount,
+		HighValue: booking.HighValue,
+	})
+	payment := models.Payment{
+		BookingID:            booking.ID,
+		Kind:                 kind,
+		Provider:             u.provider.Name(),
+		Amount:               amount,
+		AuthorizationLatency: time.Since(started),
+	}
+	if err != nil {
+		payment.Status = models.PaymentFailed
+		payment.Message = err.Error()
+		u.payments.Create(payment)
+		return nil, fmt.Errorf("%w: %v", ErrPaymentFailed, err)
+	}
+
+	payment.Reference = result.Reference
+	payment.Message = result.Message
+	if result.Status != utils.PaymentSucceeded {
+		payment.Status = models.PaymentDeclined
+		created := u.payments.Create(payment)
+		u.rejectUnpaid(created)
+		return toPaymentResponse(created), nil
+	}
+	payment.Status = models.PaymentAuthorized
+	return u.capturePayment(u.payments.Create(payment))
+}
+
+// capturePayment takes the authorized amount, a failed call leaves the
+// payment authorized so it is voided when the booking expires
+func (u *bookingUsecase) capturePayment(payment *models.Payment) (*dto.PaymentResponse, error) {
+	result, err := u.provider.Capture(payment.Reference, payment.Amount)
+	if err != nil {
+		payment.Message = err.Error()
+		u.payments.Update(payment)
+		return nil, fmt.Errorf("%w: %v", ErrPaymentFailed, err)
+	}
+
+	payment.Message = result.Message
+	switch result.Status {
+	case utils.PaymentSucceeded:
+		u.completeCapture(payment)
+	case utils.PaymentPending:
+		payment.Status = models.PaymentCapturePending
+		u.payments.Update(payment)
+	default:
+		u.voidPayment(payment)
+		payment.Status = models.PaymentDeclined
+		u.payments.Update(payment)
+		u.rejectUnpaid(payment)
+	}
+	return toPaymentResponse(payment), nil
+}
+
+// completeCapture confirms the booking of a captured payment, a captured
+// deposit only marks the deposit paid. Bookings that were canceled or
+// expired in the meantime are refunded instead.
+func (u *bookingUsecase) completeCapture(payment *models.Payment) {
+	payment.Status = models.PaymentCaptured
+	u.payments.Update(payment)
+	u.recordLedger(payment, models.LedgerCharge, payment.Amount)
+
+	status := models.StatusConfirmed
+	if payment.Kind == models.PaymentKindDeposit {
+		status = models.StatusDepositPaid
+	}
+	if err := u.UpdateBookingStatus(payment.BookingID, status); err != nil {
+		log.Printf("Refunding payment %s, booking %d was not %s: %v", payment.Reference, payment.BookingID, status, err)
+		u.refundPayment(payment, payment.Amount)
+	}
+}
+
+// rejectUnpaid rejects a booking whose payment was declined, a declined
+// balance leaves the booking waiting for another try until it is due
+func (u *bookingUsecase) rejectUnpaid(payment *models.Payment) {
+	if payment.Kind == models.PaymentKindBalance {
+		return
+	}
+	if err := u.UpdateBookingStatus(payment.BookingID, models.StatusRejected); err != nil {
+		log.Printf("Failed to reject booking %d after a declined payment: %v", payment.BookingID, err)
+	}
+}
+
+// HandlePaymentCallback finishes a pending capture, the payload must be
+// signed with the callback secret
+func (u *bookingUsecase) HandlePaymentCallback(signature string, payload []byte) (*dto.PaymentResponse, error) {
+	if !utils.VerifyPaymentSignature(u.cfg.Payment.CallbackSecret, payload, signature) {
+		return nil, ErrInvalidPaymentSignature
+	}
+	var callback dto.PaymentCallback
+	if err := json.Unmarshal(payload, &callback); err != nil || callback.Reference == "" {
+		return nil, fmt.Errorf("%w: reference is required", ErrInvalidPaymentCallback)
+	}
+	if callback.Status != utils.PaymentSucceeded && callback.Status != utils.PaymentDeclined {
+		return nil, fmt.Errorf("%w: status must be succeeded or declined", ErrInvalidPaymentCallback)
+	}
+
+	u.paymentMu.Lock()
+	defer u.paymentMu.Unlock()
+
+	payment, exists := u.payments.GetByReference(callback.Reference)
+	if !exists {
+		return nil, ErrPaymentNotFound
+	}
+	// providers retry callbacks, only the first one changes the payment
+	if payment.Status != models.PaymentCapturePending {
+		return toPaymentResponse(payment), nil
+	}
+
+	payment.Message = callback.Message
+	if callback.Status == utils.PaymentSucceeded {
+		u.completeCapture(payment)
+	} else {
+		u.voidPayment(payment)
+		payment.Status = models.PaymentDeclined
+		u.payments.Update(payment)
+		u.rejectUnpaid(payment)
+	}
+	return toPaymentResponse(payment), nil
+}
+
+// settlePayments voids the open authorizations of a canceled or rejected
+// booking and refunds captured payments by the refund of the booking, the
+// newest payment first so a balance is refunded before its deposit. It is
+// called with u.mu held after the booking is saved.
+func (u *bookingUsecase) settlePayments(booking *dto.BookingResponse) {
+	if booking.Status != models.StatusCanceled && booking.Status != models.StatusRejected {
+		return
+	}
+	refund := booking.RefundAmount
+	payments := u.payments.GetByBookingID(booking.ID)
+	for i := len(payments) - 1; i >= 0; i-- {
+		payment := payments[i]
+		switch payment.Status {
+		case models.PaymentAuthorized, models.PaymentCapturePending:
+			if u.voidPayment(payment) {
+				payment.Status = models.PaymentVoided
+				u.payments.Update(payment)
+			}
+		case models.PaymentCaptured:
+			if !refund.SameCurrency(payment.Amount) || refund.Amount <= 0 {
+				continue
+			}
+			amount := refund
+			if amount.Cmp(payment.Amount) > 0 {
+				amount = payment.Amount
+			}
+			if u.refundPayment(payment, amount) {
+				refund = refund.Sub(amount)
+			}
+		}
+	}
+}
+
+func (u *bookingUsecase) voidPayment(payment *models.Payment) bool {
+	if _, err := u.provider.Void(payment.Reference); err != nil {
+		log.Printf("Failed to void payment %s: %v", payment.Reference, err)
+		return false
+	}
+	return true
+}
+
+func (u *bookingUsecase) refundPayment(payment *models.Payment, amount models.Money) bool {
+	result, err := u.provider.Refund(payment.Reference, amount)
+	if err != nil || result.Status == utils.PaymentDeclined {
+		log.Printf("Failed to refund %s of payment %s: %v", amount, payment.Reference, err)
+		return false
+	}
+	payment.Status = models.PaymentRefunded
+	payment.Refunded = amount
+	u.payments.Update(payment)
+	u.recordLedger(payment, models.LedgerRefund, amount)
+	return true
+}
+
+// recordLedger adds money moved by a payment to the ledger of its booking
+func (u *bookingUsecase) recordLedger(payment *models.Payment, kind string, amount models.Money) {
+	u.payments.AddLedgerEntry(models.LedgerEntry{
+		BookingID:   payment.BookingID,
+		PaymentID:   payment.ID,
+		PaymentKind: payment.Kind,
+		Kind:        kind,
+		Reference:   payment.Reference,
+		Amount:      amount,
+	})
+}
+
+// GetPayments returns the payments of a booking, oldest first
+func (u *bookingUsecase) GetPayments(claims *utils.Claims, id int) ([]*dto.PaymentResponse, error) {
+	if _, err := u.GetBookingByID(claims, id); err != nil {
+		return nil, err
+	}
+	res := []*dto.PaymentResponse{}
+	for _, payment := range u.payments.GetByBookingID(id) {
+		res = append(res, toPaymentResponse(payment))
+	}
+	return res, nil
+}
+
+func toPaymentResponse(payment *models.Payment) *dto.PaymentResponse {
+	return &dto.PaymentResponse{
+		ID:        payment.ID,
+		BookingID: payment.BookingID,
+		Kind:      payment.Kind,
+		Provider:  payment.Provider,
+		Reference: payment.Reference,
+		Amount:    payment.Amount,
+		Refunded:  payment.Refunded,
+		Status:    payment.Status,
+		Message:   payment.Message,
+		CreatedAt: payment.CreatedAt,
+		UpdatedAt: payment.UpdatedAt,
+	}
+}
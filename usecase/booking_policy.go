@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+// checkBookingPolicy enforces the per user limits before a booking is created,
+// the caller must hold u.mu so two requests cannot both pass the limits. A
+// booking series counts as a single booking towards the limits.
+func (u *bookingUsecase) checkBookingPolicy(req dto.BookingRequest, now time.Time) error {
+	policy := u.cfg.BookingPolicy
+
+	if containsID(policy.BlockedUsers, req.UserID) {
+		return &PolicyError{Code: BlockedUserErr, Message: "user is not allowed to make bookings"}
+	}
+	if containsID(policy.BlockedServices, req.ServiceID) {
+		return &PolicyError{Code: BlockedServiceErr, Message: "service is not available for booking"}
+	}
+
+	bookings := u.repo.GetByUserID(req.UserID)
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	pending, recent := 0, 0
+	// only bookings in the currency of the limit count towards it
+	spent := models.NewMoney(0, policy.MaxDailySpend.Currency)
+	series := map[int]bool{}
+	for _, booking := range bookings {
+		if booking.SeriesID != 0 {
+			if series[booking.SeriesID] {
+				continue
+			}
+			series[booking.SeriesID] = true
+		}
+		if booking.Status == models.StatusPending {
+			pending++
+		}
+
+		createdAt := booking.CreatedAt
+		if policy.Velocity.Requests > 0 && now.Sub(createdAt) < policy.Velocity.Period {
+			recent++
+		}
+		if !createdAt.Before(startOfDay) && booking.Status != models.StatusCanceled && booking.Status != models.StatusRejected && booking.Price.Currency == spent.Currency {
+			spent = spent.Add(booking.Price)
+		}
+	}
+
+	if policy.MaxPendingPerUser > 0 && pending >= policy.MaxPendingPerUser {
+		return &PolicyError{
+			Code:    PendingLimitErr,
+			Message: fmt.Sprintf("user already has %d pending bookings", pending),
+		}
+	}
+	if policy.Velocity.Requests > 0 && recent >= policy.Velocity.Requests {
+		return &PolicyError{
+			Code:    VelocityErr,
+			Message: fmt.Sprintf("too many bookings created in the last %s", policy.Velocity.Period),
+		}
+	}
+	if policy.MaxDailySpend.Amount > 0 && req.Price.Currency == spent.Currency && spent.Add(req.Price).Cmp(policy.MaxDailySpend) > 0 {
+		return &PolicyError{
+			Code:    DailySpendErr,
+			Message: fmt.Sprintf("booking exceeds the daily spend limit of %s", policy.MaxDailySpend),
+		}
+	}
+
+	return nil
+}
+
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
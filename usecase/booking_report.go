@@ -0,0 +1,308 @@
+package usecase
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// reports cover the last defaultReportDays days unless a range is asked
+// for, and at most maxReportDays days
+const (
+	defaultReportDays = 30
+	maxReportDays     = 366
+)
+
+// reportScope is the bookings made in the range of a report
+type reportScope struct {
+	groupBy  string
+	location *time.Location
+	start    time.Time
+	last     time.Time
+	bookings []*dto.BookingResponse
+}
+
+// BookingReport counts the bookings made in the range by group, with their
+// conversion, cancellation and expiry rates and how long their credit
+// checks took. Only staff can see reports.
+func (u *bookingUsecase) BookingReport(claims *utils.Claims, filter dto.ReportFilter, location *time.Location) (*dto.BookingReportResponse, error) {
+	scope, err := u.reportScope(claims, filter, location)
+	if err != nil {
+		return nil, err
+	}
+
+	// converted bookings were confirmed at some point, expired ones were
+	// canceled by the expiry job
+	converted := map[int]bool{}
+	expired := map[int]bool{}
+	for _, entry := range u.history.GetAfter(0) {
+		if entry.Action == models.HistoryExpired {
+			expired[entry.BookingID] = true
+		}
+		if entry.Changes["status"].To == models.StatusConfirmed {
+			converted[entry.BookingID] = true
+		}
+	}
+	latency := map[int]time.Duration{}
+	checks := map[int]int{}
+	for _, payment := range u.payments.GetAll() {
+		latency[payment.BookingID] += payment.AuthorizationLatency
+		checks[payment.BookingID]++
+	}
+
+	rows := map[string]*dto.BookingReportRow{}
+	latencies := map[string]time.Duration{}
+	for _, key := range scope.keys() {
+		rows[key] = &dto.BookingReportRow{Key: key}
+	}
+	total := &dto.BookingReportRow{Key: dto.ReportTotalKey}
+	for _, booking := range scope.bookings {
+		key := scope.key(booking)
+		for _, row := range []*dto.BookingReportRow{rows[key], total} {
+			row.Bookings++
+			switch {
+			case booking.Status == models.StatusPending:
+				row.Pending++
+			case booking.Status == models.StatusDepositPaid:
+				row.DepositPaid++
+			case booking.Status == models.StatusConfirmed:
+				row.Confirmed++
+			case booking.Status == models.StatusRejected:
+				row.Rejected++
+			case expired[booking.ID]:
+				row.Expired++
+			default:
+				row.Canceled++
+			}
+			if converted[booking.ID] || booking.Status == models.StatusConfirmed {
+				row.Converted++
+			}
+			row.CreditChecks += checks[booking.ID]
+		}
+		latencies[key] += latency[booking.ID]
+		latencies[dto.ReportTotalKey] += latency[booking.ID]
+	}
+
+	res := &dto.BookingReportResponse{
+		From:     scope.start.Format(models.DateLayout),
+		To:       scope.last.Format(models.DateLayout),
+		Timezone: scope.location.String(),
+		GroupBy:  scope.groupBy,
+		Rows:     []dto.BookingReportRow{},
+	}
+	for _, key := range scope.keys() {
+		res.Rows = append(res.Rows, bookingReportRates(*rows[key], latencies[key]))
+	}
+	res.Total = bookingReportRates(*total, latencies[dto.ReportTotalKey])
+	return res, nil
+}
+
+func bookingReportRates(row dto.BookingReportRow, latency time.Duration) dto.BookingReportRow {
+	row.ConversionRate = reportRate(row.Converted, row.Bookings)
+	row.CancellationRate = reportRate(row.Canceled, row.Bookings)
+	row.ExpiryRate = reportRate(row.Expired, row.Bookings)
+	if row.CreditChecks > 0 {
+		ms := float64(latency) / float64(row.CreditChecks) / float64(time.Millisecond)
+		row.AvgCreditCheckMs = math.Round(ms*100) / 100
+	}
+	return row
+}
+
+// reportRate is the share of n in total rounded to four decimals
+func reportRate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(n)/float64(total)*10000) / 10000
+}
+
+// RevenueReport sums the booked, collected and refunded money of the
+// bookings made in the range by group and currency. Only staff can see
+// reports.
+func (u *bookingUsecase) RevenueReport(claims *utils.Claims, filter dto.ReportFilter, location *time.Location) (*dto.RevenueReportResponse, error) {
+	scope, err := u.reportScope(claims, filter, location)
+	if err != nil {
+		return nil, err
+	}
+
+	type rowKey struct{ key, currency string }
+	rows := map[rowKey]*dto.RevenueReportRow{}
+	totals := map[string]*dto.RevenueReportRow{}
+	// row is the row of the group in the currency of amount, with the total
+	// of that currency
+	row := func(key, currency string) []*dto.RevenueReportRow {
+		if _, exists := totals[currency]; !exists {
+			totals[currency] = newRevenueReportRow(dto.ReportTotalKey, currency)
+		}
+		id := rowKey{key, currency}
+		if _, exists := rows[id]; !exists {
+			rows[id] = newRevenueReportRow(key, currency)
+		}
+		return []*dto.RevenueReportRow{rows[id], totals[currency]}
+	}
+
+	for _, booking := range scope.bookings {
+		key := scope.key(booking)
+		for _, r := range row(key, booking.Price.Currency) {
+			r.Bookings++
+			if booking.Status == models.StatusConfirmed || booking.Status == models.StatusDepositPaid {
+				r.Booked = r.Booked.Add(booking.Price)
+			}
+		}
+		for _, entry := range u.payments.GetLedger(booking.ID) {
+			for _, r := range row(key, entry.Amount.Currency) {
+				if entry.Kind == models.LedgerRefund {
+					r.Refunded = r.Refunded.Add(entry.Amount)
+				} else {
+					r.Collected = r.Collected.Add(entry.Amount)
+				}
+			}
+		}
+	}
+
+	currencies := make([]string, 0, len(totals))
+	for currency := range totals {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	res := &dto.RevenueReportResponse{
+		From:     scope.start.Format(models.DateLayout),
+		To:       scope.last.Format(models.DateLayout),
+		Timezone: scope.location.String(),
+		GroupBy:  scope.groupBy,
+		Rows:     []dto.RevenueReportRow{},
+		Totals:   []dto.RevenueReportRow{},
+	}
+	// every group has a row in every currency so the rows line up
+	for _, key := range scope.keys() {
+		for _, currency := range currencies {
+			r := row(key, currency)[0]
+			r.Net = r.Collected.Sub(r.Refunded)
+			res.Rows = append(res.Rows, *r)
+		}
+	}
+	for _, currency := range currencies {
+		total := totals[currency]
+		total.Net = total.Collected.Sub(total.Refunded)
+		res.Totals = append(res.Totals, *total)
+	}
+	return res, nil
+}
+
+func newRevenueReportRow(key, currency string) *dto.RevenueReportRow {
+	zero := models.NewMoney(0, currency)
+	return &dto.RevenueReportRow{Key: key, Currency: currency, Booked: zero, Collected: zero, Refunded: zero, Net: zero}
+}
+
+// reportScope checks the filter of a report and picks its bookings, the
+// range is in location or the display time zone of the caller
+func (u *bookingUsecase) reportScope(claims *utils.Claims, filter dto.ReportFilter, location *time.Location) (*reportScope, error) {
+	if !isStaff(claims) {
+		return nil, ErrForbidden
+	}
+	if location == nil {
+		location = u.DisplayLocation(claims, filter.ServiceID)
+	}
+	scope := &reportScope{groupBy: filter.GroupBy, location: location}
+	switch scope.groupBy {
+	case "":
+		scope.groupBy = dto.ReportByDay
+	case dto.ReportByDay, dto.ReportByWeek, dto.ReportByMonth, dto.ReportByService, dto.ReportByStatus:
+	default:
+		return nil, fmt.Errorf("%w: group_by must be day, week, month, service or status", ErrInvalidReport)
+	}
+
+	scope.last = today(location)
+	scope.start = scope.last.AddDate(0, 0, -(defaultReportDays - 1))
+	var err error
+	if filter.To != "" {
+		if scope.last, err = time.ParseInLocation(models.DateLayout, filter.To, location); err != nil {
+			return nil, fmt.Errorf("%w: to must be a date such as 2024-12-05", ErrInvalidReport)
+		}
+		if filter.From == "" {
+			scope.start = scope.last.AddDate(0, 0, -(defaultReportDays - 1))
+		}
+	}
+	if filter.From != "" {
+		if scope.start, err = time.ParseInLocation(models.DateLayout, filter.From, location); err != nil {
+			return nil, fmt.Errorf("%w: from must be a date such as 2024-12-05", ErrInvalidReport)
+		}
+	}
+	if scope.last.Before(scope.start) || scope.last.After(scope.start.AddDate(0, 0, maxReportDays-1)) {
+		return nil, fmt.Errorf("%w: to must be on or after from and at most %d days later", ErrInvalidReport, maxReportDays-1)
+	}
+
+	end := scope.last.AddDate(0, 0, 1)
+	for _, booking := range u.repo.GetAll() {
+		if booking.CreatedAt.Before(scope.start) || !booking.CreatedAt.Before(end) {
+			continue
+		}
+		if filter.ServiceID != 0 && booking.ServiceID != filter.ServiceID {
+			continue
+		}
+		scope.bookings = append(scope.bookings, booking)
+	}
+	return scope, nil
+}
+
+// key is the group of a booking
+func (s *reportScope) key(booking *dto.BookingResponse) string {
+	switch s.groupBy {
+	case dto.ReportByService:
+		return strconv.Itoa(booking.ServiceID)
+	case dto.ReportByStatus:
+		return booking.Status
+	}
+	return s.timeKey(booking.CreatedAt.In(s.location))
+}
+
+func (s *reportScope) timeKey(t time.Time) string {
+	switch s.groupBy {
+	case dto.ReportByWeek:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case dto.ReportByMonth:
+		return t.Format("2006-01")
+	}
+	return t.Format(models.DateLayout)
+}
+
+// keys are the groups in order, every day, week or month of the range has
+// a group even without bookings, services are sorted by id
+func (s *reportScope) keys() []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	switch s.groupBy {
+	case dto.ReportByService, dto.ReportByStatus:
+		for _, booking := range s.bookings {
+			add(s.key(booking))
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if s.groupBy == dto.ReportByService {
+				a, _ := strconv.Atoi(keys[i])
+				b, _ := strconv.Atoi(keys[j])
+				return a < b
+			}
+			return keys[i] < keys[j]
+		})
+	default:
+		for day := s.start; !day.After(s.last); day = day.AddDate(0, 0, 1) {
+			add(s.timeKey(day))
+		}
+	}
+	return keys
+}
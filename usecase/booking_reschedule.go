@@ -0,0 +1,292 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// utc is how times are persisted, in UTC to the second
+func utc(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// parseStart parses an RFC3339 start_at, slots are kept in UTC
+func parseStart(startAt string) (time.Time, error) {
+	start, err := time.Parse(time.RFC3339, startAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: start_at must be an RFC3339 time", ErrInvalidSlot)
+	}
+	return utc(start), nil
+}
+
+// slotFor parses the start of a slot lasting duration
+func slotFor(startAt string, duration time.Duration, now time.Time) (time.Time, time.Time, error) {
+	start, err := parseStart(startAt)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return slotAt(start, duration, now)
+}
+
+// slotAt is the slot lasting duration from start, which must be in the future
+func slotAt(start time.Time, duration time.Duration, now time.Time) (time.Time, time.Time, error) {
+	if !start.After(now) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: start_at must be in the future", ErrInvalidSlot)
+	}
+	return start, start.Add(duration), nil
+}
+
+// checkCapacity counts the active bookings of the service that overlap the
+// slot, excludeID is left out so a booking does not count against itself
+func (u *bookingUsecase) checkCapacity(service *models.Service, start, end time.Time, excludeID int) error {
+	taken := 0
+	for _, booking := range u.repo.GetByServiceID(service.ID) {
+		if booking.ID == excludeID || booking.StartAt.IsZero() {
+			continue
+		}
+		if !models.IsActiveStatus(booking.Status) {
+			continue
+		}
+		if booking.StartAt.Before(end) && start.Before(booking.EndAt) {
+			taken++
+		}
+	}
+	// open waitlist offers hold their place until they are accepted or expire
+	now := time.Now()
+	for _, entry := range u.waitlist.GetByServiceID(service.ID) {
+		if entry.HoldsSlot(now) && entry.Overlaps(start, end) {
+			taken++
+		}
+	}
+	if taken >= service.Capacity {
+		return ErrSlotFull
+	}
+	return nil
+}
+
+// moveBooking points the booking at the service and the slot starting at
+// start after checking capacity, a zero start keeps the booking without a
+// slot. The changed fields are added to changes.
+// The booking keeps its duration and coupon discount and is priced again, a
+// price set by staff is only replaced when the service changes.
+func (u *bookingUsecase) moveBooking(booking *dto.BookingResponse, service *models.Service, start time.Time, now time.Time, changes map[string]models.FieldChange) error {
+	duration, err := bookingDuration(service, booking.Duration)
+	if err != nil {
+		return err
+	}
+
+	moved := false
+	if !start.IsZero() {
+		var end time.Time
+		start, end, err = slotAt(start, duration, now)
+		if err != nil {
+			return err
+		}
+		if err := u.checkOpen(service, start, end); err != nil {
+			return err
+		}
+		if err := u.checkCapacity(service, start, end, booking.ID); err != nil {
+			return err
+		}
+		if !booking.StartAt.Equal(start) {
+			changes["start_at"] = models.FieldChange{From: formatTime(booking.StartAt), To: formatTime(start)}
+			moved = true
+		}
+		booking.StartAt = start
+		booking.EndAt = end
+	}
+
+	resources, err := u.assignResources(service, booking.StartAt, booking.EndAt, booking)
+	if err != nil {
+		return err
+	}
+	if from, to := formatResources(booking.Resources), formatResources(resources); from != to {
+		changes["resources"] = models.FieldChange{From: from, To: to}
+	}
+	booking.Resources = resources
+
+	serviceChanged := service.ID != booking.ServiceID
+	if serviceChanged {
+		changes["service_id"] = models.FieldChange{From: strconv.Itoa(booking.ServiceID), To: strconv.Itoa(service.ID)}
+		booking.ServiceID = service.ID
+	}
+
+	overridden := booking.PriceBreakdown != nil && booking.PriceBreakdown.Override
+	if serviceChanged || (moved && !overridden) {
+		breakdown := u.pricing.Price(service, booking.UserID, start, duration)
+		if !booking.Discount.IsZero() {
+			if !booking.Discount.SameCurrency(breakdown.Total) {
+				return fmt.Errorf("%w: the coupon discount is in %s", ErrCurrencyMismatch, booking.Discount.Currency)
+			}
+			breakdown = applyDiscount(breakdown, booking.CouponCode, booking.Discount)
+		}
+		if breakdown.Total != booking.Price {
+			changes["price"] = models.FieldChange{From: formatPrice(booking.Price, booking.Price.Currency), To: formatPrice(breakdown.Total, breakdown.Total.Currency)}
+		}
+		booking.Price = breakdown.Total
+		booking.PriceBreakdown = breakdown
+		booking.HighValue = u.isHighValue(booking.Price)
+	}
+	u.applyDeposit(booking, now)
+	return nil
+}
+
+// recordHistory adds an entry to the history of a booking
+func (u *bookingUsecase) recordHistory(claims *utils.Claims, bookingID int, action string, changes map[string]models.FieldChange, reason string) {
+	entry := models.BookingHistory{
+		BookingID: bookingID,
+		Action:    action,
+		Changes:   changes,
+		Reason:    reason,
+	}
+	if claims != nil {
+		entry.ActorID = claims.Id
+		entry.ActorRole = claims.GetRole()
+	}
+	u.history.Add(entry)
+}
+
+// RescheduleBooking moves a booking to a new slot and/or service. Capacity is
+// checked and the booking updated while holding the lock, so the booking keeps
+// its place until the new slot is confirmed to be free.
+func (u *bookingUsecase) RescheduleBooking(claims *utils.Claims, id int, req dto.RescheduleRequest) (*dto.BookingResponse, error) {
+	if req.ServiceID == 0 && req.StartAt == "" {
+		return nil, fmt.Errorf("%w: service_id or start_at is required", ErrInvalidSlot)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	current, err := u.GetBookingByID(claims, id)
+	if err != nil {
+		return nil, err
+	}
+	booking := *current
+
+	if !models.IsActiveStatus(booking.Status) {
+		return nil, fmt.Errorf("%w: booking is %s", ErrRescheduleNotAllowed, booking.Status)
+	}
+
+	rules := u.cfg.ReschedulePolicy
+	if rules.MaxReschedules > 0 && booking.RescheduleCount >= rules.MaxReschedules {
+		return nil, fmt.Errorf("%w: booking was already rescheduled %d times", ErrRescheduleNotAllowed, booking.RescheduleCount)
+	}
+
+	now := time.Now()
+	oldStart := booking.StartAt
+	if !oldStart.IsZero() && oldStart.Sub(now) < rules.MinNotice {
+		return nil, fmt.Errorf("%w: bookings can only be moved up to %s before the start", ErrRescheduleNotAllowed, rules.MinNotice)
+	}
+
+	serviceID := booking.ServiceID
+	if req.ServiceID != 0 {
+		serviceID = req.ServiceID
+	}
+	service, exists := u.services.GetByID(serviceID)
+	if !exists {
+		return nil, ErrServiceNotFound
+	}
+
+	start := booking.StartAt
+	if req.StartAt != "" {
+		if start, err = parseStart(req.StartAt); err != nil {
+			return nil, err
+		}
+	}
+	changes := map[string]models.FieldChange{}
+	if err := u.moveBooking(&booking, service, start, now, changes); err != nil {
+		return nil, err
+	}
+
+	// moving late costs a fee, moving early enough is free
+	if rules.Fee.Amount > 0 && (oldStart.IsZero() || oldStart.Sub(now) < rules.FreeBefore) {
+		if rules.Fee.Currency != booking.Price.Currency {
+			return nil, fmt.Errorf("%w: the reschedule fee is in %s", ErrCurrencyMismatch, rules.Fee.Currency)
+		}
+		fees := booking.RescheduleFees.Add(rules.Fee)
+		changes["reschedule_fees"] = models.FieldChange{
+			From: formatPrice(booking.RescheduleFees, fees.Currency),
+			To:   formatPrice(fees, fees.Currency),
+		}
+		booking.RescheduleFees = fees
+	}
+	booking.RescheduleCount++
+
+	if err := u.repo.UpdateBooking(&booking); err != nil {
+		return nil, ErrBookingNotFound
+	}
+	u.cache.Set(booking.ID, &booking)
+	u.settlePayments(&booking)
+	u.syncInvoices(&booking)
+	if !booking.StartAt.Equal(current.StartAt) || booking.ServiceID != current.ServiceID {
+		u.offerSlot(current.ServiceID, current.StartAt, current.EndAt, now)
+	}
+	u.recordHistory(claims, booking.ID, models.HistoryRescheduled, changes, req.Reason)
+
+	return &booking, nil
+}
+
+// GetBookingHistory returns every change made to a booking, oldest first
+func (u *bookingUsecase) GetBookingHistory(claims *utils.Claims, id int) ([]*dto.BookingHistoryResponse, error) {
+	if _, err := u.GetBookingByID(claims, id); err != nil {
+		return nil, err
+	}
+
+	res := []*dto.BookingHistoryResponse{}
+	for _, entry := range u.history.GetByBookingID(id) {
+		res = append(res, toBookingHistoryResponse(entry))
+	}
+	return res, nil
+}
+
+// GetAuditLog returns the history entries of every booking added after the
+// entry with the id, oldest first, for operators following the changes
+func (u *bookingUsecase) GetAuditLog(after int) []*dto.BookingHistoryResponse {
+	res := []*dto.BookingHistoryResponse{}
+	for _, entry := range u.history.GetAfter(after) {
+		res = append(res, toBookingHistoryResponse(entry))
+	}
+	return res
+}
+
+func toBookingHistoryResponse(entry *models.BookingHistory) *dto.BookingHistoryResponse {
+	res := &dto.BookingHistoryResponse{
+		ID:        entry.ID,
+		BookingID: entry.BookingID,
+		Action:    entry.Action,
+		Reason:    entry.Reason,
+		ActorID:   entry.ActorID,
+		ActorRole: entry.ActorRole,
+		CreatedAt: entry.CreatedAt,
+	}
+	if len(entry.Changes) > 0 {
+		res.Changes = map[string]dto.FieldChange{}
+		for field, change := range entry.Changes {
+			res.Changes[field] = dto.FieldChange{From: change.From, To: change.To}
+		}
+	}
+	return res
+}
+
+// formatTime formats a time for the history, times that were never set are
+// empty
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatPrice formats an amount for the history, amounts that were never
+// set have no currency and get the given one
+func formatPrice(price models.Money, currency string) string {
+	if price.Currency == "" {
+		price.Currency = currency
+	}
+	return price.String()
+}
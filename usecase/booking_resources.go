@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+// busyResources returns the resources assigned to active bookings that
+// overlap the slot, excludeID is left out so a booking does not block itself
+func (u *bookingUsecase) busyResources(start, end time.Time, excludeID int) map[int]bool {
+	busy := map[int]bool{}
+	for _, booking := range u.repo.GetAll() {
+		if booking.ID == excludeID || booking.StartAt.IsZero() || !models.IsActiveStatus(booking.Status) {
+			continue
+		}
+		if booking.StartAt.Before(end) && start.Before(booking.EndAt) {
+			for _, resource := range booking.Resources {
+				busy[resource.ID] = true
+			}
+		}
+	}
+	return busy
+}
+
+// assignResources picks free resources for every requirement of the service
+// in the slot. The resources the booking already has are tried first so a
+// moved booking keeps them when it can. Requirements with more skills are
+// filled first, a resource only fills one requirement. Bookings without a
+// slot or of services without requirements get no resources.
+func (u *bookingUsecase) assignResources(service *models.Service, start, end time.Time, booking *dto.BookingResponse) ([]dto.BookingResource, error) {
+	requirements := u.resources.GetRequirements(service.ID)
+	if len(requirements) == 0 || start.IsZero() {
+		return nil, nil
+	}
+	sort.SliceStable(requirements, func(i, j int) bool {
+		return len(requirements[i].Skills) > len(requirements[j].Skills)
+	})
+
+	current := map[int]bool{}
+	for _, resource := range booking.Resources {
+		current[resource.ID] = true
+	}
+	candidates := u.resources.GetAll()
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return current[candidates[i].ID] && !current[candidates[j].ID]
+	})
+
+	busy := u.busyResources(start, end, booking.ID)
+	assigned := []dto.BookingResource{}
+	for _, requirement := range requirements {
+		found := 0
+		for _, resource := range candidates {
+			if found == requirement.Quantity {
+				break
+			}
+			if busy[resource.ID] || !resource.Meets(requirement) {
+				continue
+			}
+			busy[resource.ID] = true
+			found++
+			assigned = append(assigned, dto.BookingResource{ID: resource.ID, Name: resource.Name, Type: resource.Type})
+		}
+		if found < requirement.Quantity {
+			return nil, fmt.Errorf("%w: %s", ErrNoResourceAvailable, describeRequirement(requirement))
+		}
+	}
+	sort.Slice(assigned, func(i, j int) bool {
+		return assigned[i].ID < assigned[j].ID
+	})
+	return assigned, nil
+}
+
+// describeRequirement writes the requirement as "2 staff with fiber, splicing"
+func describeRequirement(requirement models.ResourceRequirement) string {
+	description := fmt.Sprintf("%d %s", requirement.Quantity, requirement.Type)
+	if len(requirement.Skills) > 0 {
+		description += " with " + strings.Join(requirement.Skills, ", ")
+	}
+	return description
+}
+
+// formatResources writes the resources for the booking history
+func formatResources(resources []dto.BookingResource) string {
+	names := []string{}
+	for _, resource := range resources {
+		names = append(names, fmt.Sprintf("%s (%d)", resource.Name, resource.ID))
+	}
+	return strings.Join(names, ", ")
+}
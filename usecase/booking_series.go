@@ -0,0 +1,312 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// maxSeriesOccurrences is the most bookings a series can create
+const maxSeriesOccurrences = 100
+
+// CreateBookingSeries books every occurrence of the recurrence rule. The
+// series is checked against the booking policy once, as a single booking,
+// then each occurrence is booked on its own and the ones that cannot be
+// booked are reported as conflicts, a series without any booked occurrence
+// is an error.
+func (u *bookingUsecase) CreateBookingSeries(claims *utils.Claims, req dto.BookingSeriesRequest) (*dto.BookingSeriesResponse, error) {
+	// customers always book for themselves
+	if claims == nil {
+		return nil, ErrForbidden
+	}
+	if !claims.IsStaff() {
+		req.UserID = claims.Id
+	}
+
+	rule, err := utils.ParseRRule(req.RRule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSeries, err)
+	}
+	if !rule.IsBounded() {
+		return nil, fmt.Errorf("%w: the rule needs COUNT or UNTIL", ErrInvalidSeries)
+	}
+	start, err := time.Parse(time.RFC3339, req.StartAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: start_at must be an RFC3339 time", ErrInvalidSeries)
+	}
+	start = utc(start)
+	exceptions := []time.Time{}
+	for _, value := range req.Exceptions {
+		exception, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: exception %q must be an RFC3339 time", ErrInvalidSeries, value)
+		}
+		exceptions = append(exceptions, utc(exception))
+	}
+	service, exists := u.services.GetByID(req.ServiceID)
+	if !exists {
+		return nil, ErrServiceNotFound
+	}
+
+	// the rule is expanded in the time zone of the service, so BYDAY and the
+	// time of day follow its calendar and daylight saving time
+	occurrences := rule.Occurrences(start.In(u.cfg.Timezones.ServiceLocation(req.ServiceID)), maxSeriesOccurrences+1)
+	if len(occurrences) > maxSeriesOccurrences {
+		return nil, fmt.Errorf("%w: a series can have at most %d occurrences", ErrInvalidSeries, maxSeriesOccurrences)
+	}
+	for i, occurrence := range occurrences {
+		occurrences[i] = utc(occurrence)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if first, ok := firstOccurrence(occurrences, exceptions); ok {
+		duration, err := bookingDuration(service, req.Duration)
+		if err != nil {
+			return nil, err
+		}
+		price := u.pricing.Price(service, req.UserID, first, duration).Total
+		if err := u.checkBookingPolicy(dto.BookingRequest{UserID: req.UserID, ServiceID: req.ServiceID, Price: price}, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	series := u.series.Create(models.BookingSeries{
+		UserID:     req.UserID,
+		ServiceID:  req.ServiceID,
+		RRule:      req.RRule,
+		StartAt:    start,
+		Exceptions: exceptions,
+		Duration:   req.Duration,
+		Notes:      req.Notes,
+		Status:     models.SeriesActive,
+	})
+	res := u.toBookingSeriesResponse(series, nil)
+	for _, occurrence := range occurrences {
+		if isException(exceptions, occurrence) {
+			continue
+		}
+		booking, err := u.createBooking(claims, dto.BookingRequest{
+			UserID:    req.UserID,
+			ServiceID: req.ServiceID,
+			StartAt:   occurrence.Format(time.RFC3339),
+			Duration:  req.Duration,
+			Notes:     req.Notes,
+			SeriesID:  series.ID,
+		})
+		if err != nil {
+			res.Conflicts = append(res.Conflicts, seriesConflict(occurrence, 0, err))
+			continue
+		}
+		res.Bookings = append(res.Bookings, booking)
+	}
+
+	if len(res.Bookings) == 0 {
+		series.Status = models.SeriesCanceled
+		u.series.Update(series)
+		if len(res.Conflicts) == 0 {
+			return nil, fmt.Errorf("%w: every occurrence is an exception", ErrInvalidSeries)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrSeriesConflict, res.Conflicts[0].Message)
+	}
+	return res, nil
+}
+
+// GetBookingSeries returns a series with its bookings
+func (u *bookingUsecase) GetBookingSeries(claims *utils.Claims, id int) (*dto.BookingSeriesResponse, error) {
+	series, err := u.getSeries(claims, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.toBookingSeriesResponse(series, u.seriesBookings(series)), nil
+}
+
+// UpdateBookingSeries changes the occurrence starting at from and every
+// following one, like "this and following" in calendar apps. Each booking is
+// rescheduled or patched on its own and the ones that cannot be changed are
+// reported as conflicts.
+func (u *bookingUsecase) UpdateBookingSeries(claims *utils.Claims, id int, req dto.BookingSeriesUpdate) (*dto.BookingSeriesResponse, error) {
+	series, err := u.getSeries(claims, id)
+	if err != nil {
+		return nil, err
+	}
+	from, err := time.Parse(time.RFC3339, req.From)
+	if err != nil {
+		return nil, fmt.Errorf("%w: from must be an RFC3339 time", ErrInvalidSeries)
+	}
+	var shift time.Duration
+	if req.StartAt != "" {
+		newStart, err := time.Parse(time.RFC3339, req.StartAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: start_at must be an RFC3339 time", ErrInvalidSeries)
+		}
+		shift = newStart.Sub(from)
+	}
+	if shift == 0 && req.ServiceID == 0 && req.Notes == nil {
+		return nil, fmt.Errorf("%w: nothing to change", ErrInvalidSeries)
+	}
+
+	conflicts := []dto.SeriesConflict{}
+	for _, booking := range u.followingBookings(series, from) {
+		if shift != 0 || (req.ServiceID != 0 && req.ServiceID != booking.ServiceID) {
+			reschedule := dto.RescheduleRequest{ServiceID: req.ServiceID, Reason: fmt.Sprintf("series #%d updated", series.ID)}
+			if shift != 0 {
+				reschedule.StartAt = booking.StartAt.Add(shift).Format(time.RFC3339)
+			}
+			if _, err := u.RescheduleBooking(claims, booking.ID, reschedule); err != nil {
+				conflicts = append(conflicts, seriesConflict(booking.StartAt, booking.ID, err))
+				continue
+			}
+		}
+		if req.Notes != nil && *req.Notes != booking.Notes {
+			patch := dto.BookingPatch{Notes: req.Notes}
+			if *req.Notes == "" {
+				patch = dto.BookingPatch{ClearNotes: true}
+			}
+			if _, err := u.PatchBooking(claims, booking.ID, patch); err != nil {
+				conflicts = append(conflicts, seriesConflict(booking.StartAt, booking.ID, err))
+			}
+		}
+	}
+
+	if !from.After(series.StartAt) {
+		if req.ServiceID != 0 {
+			series.ServiceID = req.ServiceID
+		}
+		if req.Notes != nil {
+			series.Notes = *req.Notes
+		}
+		series.StartAt = series.StartAt.Add(shift)
+		u.series.Update(series)
+	}
+	res := u.toBookingSeriesResponse(series, u.seriesBookings(series))
+	res.Conflicts = conflicts
+	return res, nil
+}
+
+// CancelBookingSeries cancels the occurrence starting at from and every
+// following one, an empty from cancels every upcoming booking and the series
+// itself. Each booking is refunded by the cancellation policy.
+func (u *bookingUsecase) CancelBookingSeries(claims *utils.Claims, id int, from string) (*dto.BookingSeriesResponse, error) {
+	series, err := u.getSeries(claims, id)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	if from != "" {
+		start, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, fmt.Errorf("%w: from must be an RFC3339 time", ErrInvalidSeries)
+		}
+	}
+
+	conflicts := []dto.SeriesConflict{}
+	for _, booking := range u.followingBookings(series, start) {
+		if err := u.CancelBooking(claims, booking.ID); err != nil {
+			conflicts = append(conflicts, seriesConflict(booking.StartAt, booking.ID, err))
+		}
+	}
+
+	if from == "" || !start.After(series.StartAt) {
+		series.Status = models.SeriesCanceled
+		u.series.Update(series)
+	}
+	res := u.toBookingSeriesResponse(series, u.seriesBookings(series))
+	res.Conflicts = conflicts
+	return res, nil
+}
+
+func (u *bookingUsecase) getSeries(claims *utils.Claims, id int) (*models.BookingSeries, error) {
+	series, exists := u.series.GetByID(id)
+	if !exists {
+		return nil, ErrSeriesNotFound
+	}
+	if !canActFor(claims, series.UserID) {
+		return nil, ErrForbidden
+	}
+	return series, nil
+}
+
+// seriesBookings returns the bookings of a series by start
+func (u *bookingUsecase) seriesBookings(series *models.BookingSeries) []*dto.BookingResponse {
+	bookings := []*dto.BookingResponse{}
+	for _, booking := range u.repo.GetByUserID(series.UserID) {
+		if booking.SeriesID == series.ID {
+			bookings = append(bookings, booking)
+		}
+	}
+	sort.Slice(bookings, func(i, j int) bool {
+		return bookings[i].StartAt.Before(bookings[j].StartAt)
+	})
+	return bookings
+}
+
+// followingBookings returns the active bookings of a series starting at or
+// after from
+func (u *bookingUsecase) followingBookings(series *models.BookingSeries, from time.Time) []*dto.BookingResponse {
+	following := []*dto.BookingResponse{}
+	for _, booking := range u.seriesBookings(series) {
+		if booking.StartAt.IsZero() || booking.StartAt.Before(from) || !models.IsActiveStatus(booking.Status) {
+			continue
+		}
+		following = append(following, booking)
+	}
+	return following
+}
+
+// firstOccurrence returns the first occurrence that is not an exception
+func firstOccurrence(occurrences, exceptions []time.Time) (time.Time, bool) {
+	for _, occurrence := range occurrences {
+		if !isException(exceptions, occurrence) {
+			return occurrence, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func isException(exceptions []time.Time, occurrence time.Time) bool {
+	for _, exception := range exceptions {
+		if exception.Equal(occurrence) {
+			return true
+		}
+	}
+	return false
+}
+
+// seriesConflict reports why an occurrence failed, broken booking policies
+// carry their code
+func seriesConflict(startAt time.Time, bookingID int, err error) dto.SeriesConflict {
+	conflict := dto.SeriesConflict{StartAt: startAt, BookingID: bookingID, Message: err.Error()}
+	var policyErr *PolicyError
+	if errors.As(err, &policyErr) {
+		conflict.Code = string(policyErr.Code)
+	}
+	return conflict
+}
+
+func (u *bookingUsecase) toBookingSeriesResponse(series *models.BookingSeries, bookings []*dto.BookingResponse) *dto.BookingSeriesResponse {
+	if bookings == nil {
+		bookings = []*dto.BookingResponse{}
+	}
+	res := &dto.BookingSeriesResponse{
+		ID:         series.ID,
+		UserID:     series.UserID,
+		ServiceID:  series.ServiceID,
+		RRule:      series.RRule,
+		StartAt:    series.StartAt,
+		Duration:   series.Duration,
+		Notes:      series.Notes,
+		Status:     series.Status,
+		Bookings:   bookings,
+		Exceptions: series.Exceptions,
+		CreatedAt:  series.CreatedAt,
+		UpdatedAt:  series.UpdatedAt,
+	}
+	return res
+}
@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// DisplayLocation is the time zone times of a booking of the service are
+// shown in when the caller does not ask for one, the zone of the caller
+// wins over the zone of the service
+func (u *bookingUsecase) DisplayLocation(claims *utils.Claims, serviceID int) *time.Location {
+	userID := 0
+	if claims != nil {
+		userID = claims.Id
+	}
+	return u.cfg.Timezones.DisplayLocation(userID, serviceID)
+}
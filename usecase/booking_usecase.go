@@ -6,45 +6,275 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Eursukkul/fiber-booking-system/config"
 	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
 	"github.com/Eursukkul/fiber-booking-system/repository"
 	"github.com/Eursukkul/fiber-booking-system/utils"
 )
 
 type (
 	BookingUsecase interface {
-		CreateBooking(req dto.BookingRequest) (*dto.BookingResponse, error)
-		GetBookingByID(id int) (*dto.BookingResponse, error)
-		GetAllBookings(sortBy string, highValue string) ([]*dto.BookingResponse, error)
-		UpdateBooking(id int, status string) error
-		CancelBooking(id int) error
+		CreateBooking(claims *utils.Claims, req dto.BookingRequest) (*dto.BookingResponse, error)
+		GetBookingByID(claims *utils.Claims, id int) (*dto.BookingResponse, error)
+		GetAllBookings(claims *utils.Claims, sortBy string, highValue string) ([]*dto.BookingResponse, error)
+		UpdateBooking(claims *utils.Claims, id int, status string) error
+		CancelBooking(claims *utils.Claims, id int) error
+		RescheduleBooking(claims *utils.Claims, id int, req dto.RescheduleRequest) (*dto.BookingResponse, error)
+		GetBookingHistory(claims *utils.Claims, id int) ([]*dto.BookingHistoryResponse, error)
+		PatchBooking(claims *utils.Claims, id int, patch dto.BookingPatch) (*dto.BookingResponse, error)
+		QuoteCancellation(claims *utils.Claims, id int) (*dto.CancellationQuote, error)
+		GetInvoice(claims *utils.Claims, id int) (*dto.BookingInvoiceResponse, error)
+		StartPayment(id int) (*dto.PaymentResponse, error)
+		HandlePaymentCallback(signature string, payload []byte) (*dto.PaymentResponse, error)
+		GetPayments(claims *utils.Claims, id int) ([]*dto.PaymentResponse, error)
+		PayBalance(claims *utils.Claims, id int) (*dto.PaymentResponse, error)
+		GetPaymentLedger(claims *utils.Claims, id int) (*dto.PaymentLedgerResponse, error)
+		JoinWaitlist(claims *utils.Claims, req dto.WaitlistRequest) (*dto.WaitlistResponse, error)
+		GetWaitlist(claims *utils.Claims) ([]*dto.WaitlistResponse, error)
+		LeaveWaitlist(claims *utils.Claims, id int) error
+		AcceptWaitlistOffer(claims *utils.Claims, id int) (*dto.BookingResponse, error)
+		CreateBookingSeries(claims *utils.Claims, req dto.BookingSeriesRequest) (*dto.BookingSeriesResponse, error)
+		GetBookingSeries(claims *utils.Claims, id int) (*dto.BookingSeriesResponse, error)
+		UpdateBookingSeries(claims *utils.Claims, id int, req dto.BookingSeriesUpdate) (*dto.BookingSeriesResponse, error)
+		CancelBookingSeries(claims *utils.Claims, id int, from string) (*dto.BookingSeriesResponse, error)
+		GetBookingCalendar(claims *utils.Claims, id int) (string, error)
+		CreateCalendarFeed(claims *utils.Claims, req dto.CalendarFeedRequest) (*dto.CalendarFeedResponse, error)
+		GetCalendarFeed(token string) (string, error)
+		DisplayLocation(claims *utils.Claims, serviceID int) *time.Location
+		ExpirePendingBookings() []int
+		GetAuditLog(after int) []*dto.BookingHistoryResponse
+		ImportBookings(claims *utils.Claims, rows []dto.BookingImportRow, options dto.BookingImportOptions) (*dto.BookingImportResponse, error)
+		ExecuteBatch(claims *utils.Claims, req dto.BookingBatchRequest) (*dto.BookingBatchResponse, error)
+		BookingReport(claims *utils.Claims, filter dto.ReportFilter, location *time.Location) (*dto.BookingReportResponse, error)
+		RevenueReport(claims *utils.Claims, filter dto.ReportFilter, location *time.Location) (*dto.RevenueReportResponse, error)
 		BackgroundTaskBooking(wg *sync.WaitGroup)
 		UpdateBookingStatus(id int, status string) error
 	}
 
 	bookingUsecase struct {
-		repo  repository.BookingRepository
-		cache utils.Cache
-		mu    sync.RWMutex
+		repo     repository.BookingRepository
+		services repository.ServiceRepository
+		history  repository.BookingHistoryRepository
+		cache    utils.Cache
+		pricing  PricingUsecase
+		coupons  repository.CouponRepository
+		invoices repository.InvoiceRepository
+		payments repository.PaymentRepository
+		provider utils.PaymentProvider
+		waitlist repository.WaitlistRepository
+		series   repository.BookingSeriesRepository
+		// calendars are the iCalendar feed tokens of users
+		calendars repository.CalendarFeedRepository
+		// hours are the business hours and closures of services
+		hours repository.BusinessHoursRepository
+		// resources fulfil the bookings of services that need them
+		resources repository.ResourceRepository
+		cfg       *config.Config
+		mu        sync.RWMutex
+		// paymentMu serializes payment changes, it is taken before mu
+		paymentMu sync.Mutex
 	}
 )
 
-func NewBookingUsecase(repo repository.BookingRepository, cache utils.Cache) BookingUsecase {
+// BookingRepositories are the stores a booking usecase works on, the
+// repositories shared with other usecases of the tenant are passed in here
+type BookingRepositories struct {
+	Bookings  repository.BookingRepository
+	Services  repository.ServiceRepository
+	History   repository.BookingHistoryRepository
+	Coupons   repository.CouponRepository
+	Invoices  repository.InvoiceRepository
+	Payments  repository.PaymentRepository
+	Waitlist  repository.WaitlistRepository
+	Series    repository.BookingSeriesRepository
+	Calendars repository.CalendarFeedRepository
+	Hours     repository.BusinessHoursRepository
+	Resources repository.ResourceRepository
+}
+
+func NewBookingUsecase(repos BookingRepositories, cache utils.Cache, pricing PricingUsecase, provider utils.PaymentProvider, cfg *config.Config) BookingUsecase {
 	return &bookingUsecase{
-		repo:  repo,
-		cache: cache,
+		repo:      repos.Bookings,
+		services:  repos.Services,
+		history:   repos.History,
+		cache:     cache,
+		pricing:   pricing,
+		coupons:   repos.Coupons,
+		invoices:  repos.Invoices,
+		payments:  repos.Payments,
+		provider:  provider,
+		waitlist:  repos.Waitlist,
+		series:    repos.Series,
+		calendars: repos.Calendars,
+		hours:     repos.Hours,
+		resources: repos.Resources,
+		cfg:       cfg,
 	}
 }
 
+// canActFor reports whether the caller may act for the user. nil claims are
+// anonymous callers and may not, internal callers use utils.SystemClaims.
+func canActFor(claims *utils.Claims, userID int) bool {
+	return claims != nil && (claims.IsStaff() || claims.Id == userID)
+}
+
+// canAccess reports whether the caller may see or change the booking
+func canAccess(claims *utils.Claims, booking *dto.BookingResponse) bool {
+	return canActFor(claims, booking.UserID)
+}
+
+// isStaff reports whether the caller is staff, anonymous callers never are
+func isStaff(claims *utils.Claims) bool {
+	return claims != nil && claims.IsStaff()
+}
+
+// isHighValue reports whether price is above the high value threshold of its
+// currency, currencies without a threshold are never high value
+func (u *bookingUsecase) isHighValue(price models.Money) bool {
+	threshold, exists := u.cfg.HighValueThresholds[price.Currency]
+	return exists && price.Cmp(threshold) > 0
+}
+
 // Create
-func (u *bookingUsecase) CreateBooking(req dto.BookingRequest) (*dto.BookingResponse, error) {
-	booking := u.repo.Create(req)
-	u.cache.Set(booking.ID, booking)
-	return booking, nil
+func (u *bookingUsecase) CreateBooking(claims *utils.Claims, req dto.BookingRequest) (*dto.BookingResponse, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.createBooking(claims, req)
+}
+
+// createBooking is CreateBooking for callers that already hold u.mu
+func (u *bookingUsecase) createBooking(claims *utils.Claims, req dto.BookingRequest) (*dto.BookingResponse, error) {
+	created, err := u.storeBooking(claims, req)
+	if err != nil {
+		return nil, err
+	}
+	u.recordHistory(claims, created.ID, models.HistoryCreated, nil, "")
+	return created, nil
+}
+
+// storeBooking validates and saves a new booking without recording its
+// history, the caller must hold u.mu
+func (u *bookingUsecase) storeBooking(claims *utils.Claims, req dto.BookingRequest) (*dto.BookingResponse, error) {
+	// customers always book for themselves, anonymous callers cannot book
+	if claims == nil {
+		return nil, ErrForbidden
+	}
+	if !claims.IsStaff() {
+		req.UserID = claims.Id
+	}
+
+	now := time.Now()
+	service, exists := u.services.GetByID(req.ServiceID)
+	if !exists {
+		return nil, ErrServiceNotFound
+	}
+	duration, err := bookingDuration(service, req.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	var start, end time.Time
+	if req.StartAt != "" {
+		start, end, err = slotFor(req.StartAt, duration, now)
+		if err != nil {
+			return nil, err
+		}
+		if err := u.checkOpen(service, start, end); err != nil {
+			return nil, err
+		}
+	}
+
+	// the price comes from the pricing rules, only staff can override it and
+	// the price sent by customers is ignored
+	breakdown := u.pricing.Price(service, req.UserID, start, duration)
+	if isStaff(claims) && !req.Price.IsZero() && req.Price != breakdown.Total {
+		breakdown, err = overridePrice(breakdown, req.Price)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var coupon *models.Coupon
+	var discount models.Money
+	if req.CouponCode != "" {
+		coupon, err = u.couponFor(req.CouponCode, req.ServiceID, breakdown.Total, now)
+		if err != nil {
+			return nil, err
+		}
+		discount = coupon.Discount(breakdown.Total)
+		breakdown = applyDiscount(breakdown, coupon.Code, discount)
+	}
+	req.Price = breakdown.Total
+
+	// the occurrences of a series were checked once with the series
+	if req.SeriesID == 0 {
+		if err := u.checkBookingPolicy(req, now); err != nil {
+			return nil, err
+		}
+	}
+
+	booking := dto.BookingResponse{
+		UserID:         req.UserID,
+		ServiceID:      req.ServiceID,
+		Price:          breakdown.Total,
+		PriceBreakdown: breakdown,
+		HighValue:      u.isHighValue(breakdown.Total),
+		Notes:          req.Notes,
+		SeriesID:       req.SeriesID,
+	}
+	if duration != service.Duration {
+		booking.Duration = duration.String()
+	}
+	if !start.IsZero() {
+		if err := u.checkCapacity(service, start, end, 0); err != nil {
+			return nil, err
+		}
+		resources, err := u.assignResources(service, start, end, &booking)
+		if err != nil {
+			return nil, err
+		}
+		booking.StartAt = start
+		booking.EndAt = end
+		booking.Resources = resources
+	}
+	u.applyDeposit(&booking, now)
+
+	// the coupon is redeemed last, nothing can fail after it
+	var redemption *models.CouponRedemption
+	if coupon != nil {
+		redemption, err = u.redeemCoupon(coupon.Code, req.UserID, req.ServiceID, breakdown.Total, now)
+		if err != nil {
+			return nil, err
+		}
+		booking.CouponCode = coupon.Code
+		booking.Discount = discount
+	}
+
+	created := u.repo.Create(booking)
+	if redemption != nil {
+		u.coupons.AttachBooking(redemption.ID, created.ID)
+	}
+	u.cache.Set(created.ID, created)
+	return created, nil
 }
 
 // Get booking by id
-func (u *bookingUsecase) GetBookingByID(id int) (*dto.BookingResponse, error) {
+func (u *bookingUsecase) GetBookingByID(claims *utils.Claims, id int) (*dto.BookingResponse, error) {
+	booking, err := u.getBooking(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canAccess(claims, booking) {
+		return nil, ErrForbidden
+	}
+
+	return booking, nil
+}
+
+func (u *bookingUsecase) getBooking(id int) (*dto.BookingResponse, error) {
 	// try get data from cache
 	cachedBooking, err := u.cache.Get(id)
 	if err == nil {
@@ -54,7 +284,7 @@ func (u *bookingUsecase) GetBookingByID(id int) (*dto.BookingResponse, error) {
 	// get data from repository
 	booking, exists := u.repo.GetByID(id)
 	if !exists {
-		return nil, fmt.Errorf("booking not found")
+		return nil, ErrBookingNotFound
 	}
 
 	// set data to cache
@@ -64,68 +294,139 @@ func (u *bookingUsecase) GetBookingByID(id int) (*dto.BookingResponse, error) {
 }
 
 // Get all bookings
-func (u *bookingUsecase) GetAllBookings(sortParam, highValue string) ([]*dto.BookingResponse, error) {
+func (u *bookingUsecase) GetAllBookings(claims *utils.Claims, sortParam, highValue string) ([]*dto.BookingResponse, error) {
 	var bookings []*dto.BookingResponse
 
 	if highValue == "true" {
-		bookings = u.repo.GetHighValueBookings(50000)
+		bookings = u.repo.GetHighValueBookings(u.cfg.HighValueThresholds)
 	} else {
 		bookings = u.repo.GetAll()
 	}
 
+	// customers only see their own bookings
+	if claims == nil {
+		return nil, ErrForbidden
+	}
+	if !claims.IsStaff() {
+		owned := []*dto.BookingResponse{}
+		for _, booking := range bookings {
+			if booking.UserID == claims.Id {
+				owned = append(owned, booking)
+			}
+		}
+		bookings = owned
+	}
+
 	// เรียงลำดับ
 	switch sortParam {
 	case "price":
+		// prices of different currencies are not compared, they are grouped
+		// by currency instead
 		sort.Slice(bookings, func(i, j int) bool {
-			return bookings[i].Price < bookings[j].Price
+			if bookings[i].Price.Currency != bookings[j].Price.Currency {
+				return bookings[i].Price.Currency < bookings[j].Price.Currency
+			}
+			return bookings[i].Price.Amount < bookings[j].Price.Amount
 		})
 	case "date":
 		sort.Slice(bookings, func(i, j int) bool {
-			t1, _ := time.Parse(time.RFC3339, bookings[i].CreatedAt)
-			t2, _ := time.Parse(time.RFC3339, bookings[j].CreatedAt)
-			return t1.Before(t2)
+			return bookings[i].CreatedAt.Before(bookings[j].CreatedAt)
 		})
 	}
 
 	return bookings, nil
 }
 
-// Update status booking
-func (u *bookingUsecase) UpdateBooking(id int, status string) error {
+// Update status booking, only staff can override the status
+func (u *bookingUsecase) UpdateBooking(claims *utils.Claims, id int, status string) error {
+	if !isStaff(claims) {
+		return ErrForbidden
+	}
+	if !models.IsBookingStatus(status) {
+		return ErrInvalidStatus
+	}
+
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
-	err := u.repo.UpdateBookingStatus(id, status)
-	if err != nil {
-		return fmt.Errorf("booking not found")
+	previous, exists := u.repo.GetByID(id)
+	if !exists {
+		return ErrBookingNotFound
+	}
+	if !models.CanTransition(previous.Status, status) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, previous.Status, status)
 	}
 
-	// get data from repository
-	booking, exists := u.repo.GetByID(id)
-	if !exists {
-		return fmt.Errorf("booking not found in repository")
+	booking := *previous
+	changes := map[string]models.FieldChange{}
+	if status == models.StatusCanceled {
+		u.applyCancellation(&booking, time.Now(), changes)
+	} else {
+		changes["status"] = models.FieldChange{From: previous.Status, To: status}
+		booking.Status = status
+	}
+
+	if err := u.repo.UpdateBooking(&booking); err != nil {
+		return ErrBookingNotFound
 	}
 
 	// update cache
-	u.cache.Set(id, booking)
+	u.cache.Set(id, &booking)
+	u.settleChange(claims, &booking, models.HistoryStatusChanged, changes)
 
 	return nil
 }
 
-// Cancel booking
-func (u *bookingUsecase) CancelBooking(id int) error {
-	// change status to canceled
-	err := u.repo.UpdateBookingStatus(id, "canceled")
+// Cancel booking, the refund follows the cancellation policy of the service
+func (u *bookingUsecase) CancelBooking(claims *utils.Claims, id int) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	_, booking, changes, err := u.canceledBooking(claims, id)
 	if err != nil {
+		return err
+	}
+
+	// change status to canceled
+	if err := u.repo.UpdateBooking(booking); err != nil {
 		return fmt.Errorf("failed to cancel booking")
 	}
 
 	// delete from cache
 	u.cache.Delete(id)
+	u.settleChange(claims, booking, models.HistoryCanceled, changes)
 
 	return nil
 }
 
+// canceledBooking returns the booking and a canceled copy of it that is not
+// saved yet, the caller must hold u.mu
+func (u *bookingUsecase) canceledBooking(claims *utils.Claims, id int) (*dto.BookingResponse, *dto.BookingResponse, map[string]models.FieldChange, error) {
+	current, err := u.GetBookingByID(claims, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !models.CanTransition(current.Status, models.StatusCanceled) {
+		return nil, nil, nil, fmt.Errorf("%w: booking is %s", ErrInvalidTransition, current.Status)
+	}
+
+	booking := *current
+	changes := map[string]models.FieldChange{}
+	u.applyCancellation(&booking, time.Now(), changes)
+	return current, &booking, changes, nil
+}
+
+// settleChange follows a saved change of a booking: the coupon, payments,
+// invoices and a freed slot are brought in line with it and the change is
+// recorded. The caller must hold u.mu.
+func (u *bookingUsecase) settleChange(claims *utils.Claims, booking *dto.BookingResponse, action string, changes map[string]models.FieldChange) {
+	u.releaseCoupon(booking)
+	u.settlePayments(booking)
+	u.syncInvoices(booking)
+	u.offerFreedSlot(booking)
+	u.recordHistory(claims, booking.ID, action, changes, "")
+}
+
 // Background task for check expired booking
 func (u *bookingUsecase) BackgroundTaskBooking(wg *sync.WaitGroup) {
 	ticker := time.NewTicker(1 * time.Minute)
@@ -133,34 +434,62 @@ func (u *bookingUsecase) BackgroundTaskBooking(wg *sync.WaitGroup) {
 	go func() {
 		defer wg.Done()
 		for range ticker.C {
-			u.checkExpiredBookings()
+			u.ExpirePendingBookings()
+			u.checkDueBalances(time.Now())
+			u.checkWaitlist(time.Now())
 		}
 	}()
 }
 
-// checkExpiredBookings handles the expiration logic for pending bookings
-func (u *bookingUsecase) checkExpiredBookings() {
+// pendingTTL is how long a booking can stay pending, 5 minutes when the
+// config leaves it unset
+func (u *bookingUsecase) pendingTTL() time.Duration {
+	if u.cfg.PendingTTL > 0 {
+		return u.cfg.PendingTTL
+	}
+	return 5 * time.Minute
+}
+
+// ExpirePendingBookings cancels the bookings pending for longer than the
+// pending TTL and returns their ids in order, the background task runs it every minute.
+// It holds the locks of a status change, so a booking confirmed by a payment
+// meanwhile is left alone.
+func (u *bookingUsecase) ExpirePendingBookings() []int {
+	u.paymentMu.Lock()
+	defer u.paymentMu.Unlock()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	expired := []int{}
 	bookings := u.repo.GetAll()
 	currentTime := time.Now()
+	ttl := u.pendingTTL()
 
 	for _, booking := range bookings {
-		if booking.Status == "pending" {
-			createdAt, err := time.Parse(time.RFC3339, booking.CreatedAt)
-			if err != nil {
-				continue // ข้ามถ้ามี error ในการ parse เวลา
-			}
-			if currentTime.Sub(createdAt) > 5*time.Minute {
-				// เปลี่ยนสถานะเป็น canceled
-				err := u.repo.UpdateBookingStatus(booking.ID, "canceled")
-				if err != nil {
-					continue // ข้ามถ้าไม่สามารถอัปเดต
-				}
-				// อัปเดตแคช
-				booking.Status = "canceled"
-				u.cache.Set(booking.ID, booking)
-			}
+		if booking.Status != models.StatusPending || currentTime.Sub(booking.CreatedAt) <= ttl {
+			continue
+		}
+		if !models.CanTransition(booking.Status, models.StatusCanceled) {
+			continue
 		}
+		// เปลี่ยนสถานะเป็น canceled
+		err := u.repo.UpdateBookingStatus(booking.ID, models.StatusCanceled)
+		if err != nil {
+			continue // ข้ามถ้าไม่สามารถอัปเดต
+		}
+		// อัปเดตแคช
+		booking.Status = models.StatusCanceled
+		u.cache.Set(booking.ID, booking)
+		u.releaseCoupon(booking)
+		u.settlePayments(booking)
+		u.offerFreedSlot(booking)
+		u.recordHistory(nil, booking.ID, models.HistoryExpired, map[string]models.FieldChange{
+			"status": {From: models.StatusPending, To: models.StatusCanceled},
+		}, "pending for more than "+ttl.String())
+		expired = append(expired, booking.ID)
 	}
+	sort.Ints(expired)
+	return expired
 }
 
 // Update booking status
@@ -168,36 +497,50 @@ func (u *bookingUsecase) UpdateBookingStatus(id int, status string) error {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
+	previous, exists := u.repo.GetByID(id)
+	if !exists {
+		return fmt.Errorf("failed to update booking status")
+	}
+	if !models.CanTransition(previous.Status, status) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, previous.Status, status)
+	}
+
 	// Change status in Repository
 	err := u.repo.UpdateBookingStatus(id, status)
 	if err != nil {
 		return fmt.Errorf("failed to update booking status")
 	}
+	updated := *previous
+	updated.Status = status
+	u.releaseCoupon(&updated)
+	u.settlePayments(&updated)
+	u.syncInvoices(&updated)
+	u.offerFreedSlot(&updated)
+	u.recordHistory(nil, id, models.HistoryStatusChanged, map[string]models.FieldChange{
+		"status": {From: previous.Status, To: status},
+	}, "")
 
 	// Update cache if it exists
-	cachedBooking, err := u.cache.Get(id)
-	if err != nil {
-		return fmt.Errorf("failed to get booking from cache")
+	if cachedBooking, err := u.cache.Get(id); err == nil {
+		cachedBooking.Status = status
+		cachedBooking.UpdatedAt = utc(time.Now())
+		u.cache.Set(id, cachedBooking)
 	}
 
-	cachedBooking.Status = status
-	cachedBooking.UpdatedAt = time.Now().Format(time.RFC3339)
-	u.cache.Set(id, cachedBooking)
-
 	return nil
 }
 
 func (u *bookingUsecase) CheckExpiredBookings() {
     bookings := u.repo.GetAll()
     for _, booking := range bookings {
-        if booking.Status == "pending" && isExpired(booking.CreatedAt) {
-            u.repo.UpdateBookingStatus(booking.ID, "canceled")
+        if booking.Status == models.StatusPending && isExpired(booking.CreatedAt) {
+            u.repo.UpdateBookingStatus(booking.ID, models.StatusCanceled)
             u.cache.Delete(booking.ID)
         }
     }
 }
 
 
-func isExpired(createdAt string) bool {
+func isExpired(createdAt time.Time) bool {
     return false
 }
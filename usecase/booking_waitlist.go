@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// offerFreedSlot offers the slot of a canceled or rejected booking to the
+// waitlist. It is called with u.mu held after the booking is saved.
+func (u *bookingUsecase) offerFreedSlot(booking *dto.BookingResponse) {
+	if booking.Status != models.StatusCanceled && booking.Status != models.StatusRejected {
+		return
+	}
+	u.offerSlot(booking.ServiceID, booking.StartAt, booking.EndAt, time.Now())
+}
+
+// offerSlot offers the slot [start, end) of the service to the waiting
+// entries that overlap it, oldest first, while their slot has room. Offers
+// hold their place so one freed place is offered to one entry at a time.
+func (u *bookingUsecase) offerSlot(serviceID int, start, end time.Time, now time.Time) {
+	if start.IsZero() || end.IsZero() {
+		return
+	}
+	service, exists := u.services.GetByID(serviceID)
+	if !exists {
+		return
+	}
+
+	for _, entry := range u.waitlist.GetByServiceID(serviceID) {
+		if entry.Status != models.WaitlistWaiting || !entry.Overlaps(start, end) || !entry.StartAt.After(now) {
+			continue
+		}
+		if u.checkCapacity(service, entry.StartAt, entry.EndAt, 0) != nil {
+			continue
+		}
+		entry.Status = models.WaitlistOffered
+		entry.OfferExpiresAt = utc(now.Add(u.cfg.WaitlistAcceptWindow))
+		u.waitlist.Update(entry)
+		log.Printf("Offering service %d at %s to user %d until %s", serviceID, entry.StartAt.Format(time.RFC3339), entry.UserID, entry.OfferExpiresAt.Format(time.RFC3339))
+	}
+}
+
+// checkWaitlist expires offers that were not accepted in time and offers
+// their place to the next entry, waiting entries expire once their slot has
+// started
+func (u *bookingUsecase) checkWaitlist(now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, entry := range u.waitlist.GetAll() {
+		switch {
+		case entry.Status == models.WaitlistOffered && !entry.HoldsSlot(now):
+			entry.Status = models.WaitlistExpired
+			u.waitlist.Update(entry)
+			u.offerSlot(entry.ServiceID, entry.StartAt, entry.EndAt, now)
+		case entry.Status == models.WaitlistWaiting && !entry.StartAt.After(now):
+			entry.Status = models.WaitlistExpired
+			u.waitlist.Update(entry)
+		}
+	}
+}
+
+// JoinWaitlist puts the user in the queue of a full slot, slots with room
+// left must be booked instead
+func (u *bookingUsecase) JoinWaitlist(claims *utils.Claims, req dto.WaitlistRequest) (*dto.WaitlistResponse, error) {
+	// customers always join for themselves
+	if claims == nil {
+		return nil, ErrForbidden
+	}
+	if !claims.IsStaff() {
+		req.UserID = claims.Id
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	service, exists := u.services.GetByID(req.ServiceID)
+	if !exists {
+		return nil, ErrServiceNotFound
+	}
+	start, end, err := slotFor(req.StartAt, service.Duration, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkOpen(service, start, end); err != nil {
+		return nil, err
+	}
+	if u.checkCapacity(service, start, end, 0) == nil {
+		return nil, ErrSlotAvailable
+	}
+	for _, entry := range u.waitlist.GetByUserID(req.UserID) {
+		if entry.IsOpen() && entry.ServiceID == service.ID && entry.StartAt.Equal(start) {
+			return nil, ErrAlreadyWaitlisted
+		}
+	}
+
+	created := u.waitlist.Create(models.WaitlistEntry{
+		UserID:    req.UserID,
+		ServiceID: service.ID,
+		StartAt:   start,
+		EndAt:     end,
+		Status:    models.WaitlistWaiting,
+	})
+	return u.toWaitlistResponse(created), nil
+}
+
+// GetWaitlist returns the waitlist entries of the caller, staff see every
+// entry
+func (u *bookingUsecase) GetWaitlist(claims *utils.Claims) ([]*dto.WaitlistResponse, error) {
+	if claims == nil {
+		return nil, ErrForbidden
+	}
+	entries := u.waitlist.GetAll()
+	if !claims.IsStaff() {
+		entries = u.waitlist.GetByUserID(claims.Id)
+	}
+
+	res := []*dto.WaitlistResponse{}
+	for _, entry := range entries {
+		res = append(res, u.toWaitlistResponse(entry))
+	}
+	return res, nil
+}
+
+// LeaveWaitlist takes the entry out of the queue, an open offer goes to the
+// next entry
+func (u *bookingUsecase) LeaveWaitlist(claims *utils.Claims, id int) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	entry, err := u.getWaitlistEntry(claims, id)
+	if err != nil {
+		return err
+	}
+	if !entry.IsOpen() {
+		return fmt.Errorf("%w: entry is %s", ErrWaitlistEntryClosed, entry.Status)
+	}
+
+	offered := entry.Status == models.WaitlistOffered
+	entry.Status = models.WaitlistLeft
+	u.waitlist.Update(entry)
+	if offered {
+		u.offerSlot(entry.ServiceID, entry.StartAt, entry.EndAt, time.Now())
+	}
+	return nil
+}
+
+// AcceptWaitlistOffer books the slot offered to the entry. The offer gives
+// its place to the booking, so the slot cannot be taken in between.
+func (u *bookingUsecase) AcceptWaitlistOffer(claims *utils.Claims, id int) (*dto.BookingResponse, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	entry, err := u.getWaitlistEntry(claims, id)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.HoldsSlot(time.Now()) {
+		return nil, fmt.Errorf("%w: entry is %s", ErrNoWaitlistOffer, entry.Status)
+	}
+
+	entry.Status = models.WaitlistAccepted
+	u.waitlist.Update(entry)
+	booking, err := u.createBooking(claims, dto.BookingRequest{
+		UserID:    entry.UserID,
+		ServiceID: entry.ServiceID,
+		StartAt:   entry.StartAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		entry.Status = models.WaitlistOffered
+		u.waitlist.Update(entry)
+		return nil, err
+	}
+	entry.BookingID = booking.ID
+	u.waitlist.Update(entry)
+	return booking, nil
+}
+
+func (u *bookingUsecase) getWaitlistEntry(claims *utils.Claims, id int) (*models.WaitlistEntry, error) {
+	entry, exists := u.waitlist.GetByID(id)
+	if !exists {
+		return nil, ErrWaitlistNotFound
+	}
+	if !canActFor(claims, entry.UserID) {
+		return nil, ErrForbidden
+	}
+	return entry, nil
+}
+
+func (u *bookingUsecase) toWaitlistResponse(entry *models.WaitlistEntry) *dto.WaitlistResponse {
+	res := &dto.WaitlistResponse{
+		ID:        entry.ID,
+		UserID:    entry.UserID,
+		ServiceID: entry.ServiceID,
+		StartAt:   entry.StartAt,
+		EndAt:     entry.EndAt,
+		Status:    entry.Status,
+		BookingID: entry.BookingID,
+		CreatedAt: entry.CreatedAt,
+		UpdatedAt: entry.UpdatedAt,
+	}
+	if entry.Status == models.WaitlistOffered {
+		res.OfferExpiresAt = entry.OfferExpiresAt
+	}
+	if entry.Status == models.WaitlistWaiting {
+		for _, other := range u.waitlist.GetByServiceID(entry.ServiceID) {
+			if other.Status == models.WaitlistWaiting && other.StartAt.Equal(entry.StartAt) && other.ID <= entry.ID {
+				res.Position++
+			}
+		}
+	}
+	return res
+}
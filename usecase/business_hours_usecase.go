@@ -0,0 +1,357 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/repository"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// maxScheduleDays is the longest range GetSchedule returns
+const maxScheduleDays = 62
+
+// maxClosureDays is the longest holiday ImportHolidays accepts
+const maxClosureDays = 366
+
+// minutesPerDay is the close of hours open until midnight
+const minutesPerDay = 24 * 60
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+type (
+	BusinessHoursUsecase interface {
+		GetBusinessHours(serviceID int) (*dto.BusinessHoursResponse, error)
+		SetBusinessHours(serviceID int, req dto.BusinessHoursRequest) (*dto.BusinessHoursResponse, error)
+		DeleteBusinessHours(serviceID int) error
+		GetHoursOverrides(serviceID int) []*dto.HoursOverrideResponse
+		CreateHoursOverride(req dto.HoursOverrideRequest) (*dto.HoursOverrideResponse, error)
+		DeleteHoursOverride(id int) error
+		ImportHolidays(serviceID int, calendar string) (*dto.HolidayImportResponse, error)
+		GetSchedule(serviceID int, from, to string) (*dto.ScheduleResponse, error)
+	}
+
+	businessHoursUsecase struct {
+		repo     repository.BusinessHoursRepository
+		services repository.ServiceRepository
+		cfg      *config.Config
+	}
+)
+
+func NewBusinessHoursUsecase(repo repository.BusinessHoursRepository, services repository.ServiceRepository, cfg *config.Config) BusinessHoursUsecase {
+	return &businessHoursUsecase{repo: repo, services: services, cfg: cfg}
+}
+
+// parseMinutes parses 15:04 into minutes after midnight, 24:00 is allowed
+// for hours open until midnight
+func parseMinutes(value string) (int, error) {
+	if value == "24:00" {
+		return minutesPerDay, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is not a time such as 09:00", ErrInvalidBusinessHours, value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// toOpeningHours validates the hours of one day, they are sorted and must
+// not overlap
+func toOpeningHours(hours []dto.OpeningHours) ([]models.OpeningHours, error) {
+	res := []models.OpeningHours{}
+	for _, h := range hours {
+		open, err := parseMinutes(h.Open)
+		if err != nil {
+			return nil, err
+		}
+		closing, err := parseMinutes(h.Close)
+		if err != nil {
+			return nil, err
+		}
+		if closing <= open {
+			return nil, fmt.Errorf("%w: %s must close after it opens", ErrInvalidBusinessHours, h.Open+"-"+h.Close)
+		}
+		res = append(res, models.OpeningHours{Open: open, Close: closing})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Open < res[j].Open
+	})
+	for i := 1; i < len(res); i++ {
+		if res[i].Open < res[i-1].Close {
+			return nil, fmt.Errorf("%w: %s overlaps %s", ErrInvalidBusinessHours, res[i], res[i-1])
+		}
+	}
+	return res, nil
+}
+
+func toOpeningHoursResponse(hours []models.OpeningHours) []dto.OpeningHours {
+	res := []dto.OpeningHours{}
+	for _, h := range hours {
+		res = append(res, dto.OpeningHours{Open: models.FormatMinutes(h.Open), Close: models.FormatMinutes(h.Close)})
+	}
+	return res
+}
+
+func toHoursOverrideResponse(override *models.HoursOverride) *dto.HoursOverrideResponse {
+	return &dto.HoursOverrideResponse{
+		ID:        override.ID,
+		ServiceID: override.ServiceID,
+		Date:      override.Date,
+		Closed:    override.IsClosed(),
+		Hours:     toOpeningHoursResponse(override.Hours),
+		Reason:    override.Reason,
+		Source:    override.Source,
+		CreatedAt: override.CreatedAt,
+	}
+}
+
+// hoursOn returns the hours of the service on the date of day and the
+// override that set them, services without weekly hours are open all day
+func hoursOn(repo repository.BusinessHoursRepository, serviceID int, day time.Time) ([]models.OpeningHours, *models.HoursOverride) {
+	date := day.Format(models.DateLayout)
+	if override, exists := repo.GetOverride(serviceID, date); exists {
+		return override.Hours, override
+	}
+	if override, exists := repo.GetOverride(0, date); exists {
+		return override.Hours, override
+	}
+	weekly, exists := repo.GetHours(serviceID)
+	if !exists {
+		return []models.OpeningHours{{Open: 0, Close: minutesPerDay}}, nil
+	}
+	return weekly.Weekly[day.Weekday()], nil
+}
+
+// today is the date in the time zone of the service
+func today(location *time.Location) time.Time {
+	now := time.Now().In(location)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+}
+
+func (u *businessHoursUsecase) serviceLocation(serviceID int) (*time.Location, error) {
+	if _, exists := u.services.GetByID(serviceID); !exists {
+		return nil, ErrServiceNotFound
+	}
+	return u.cfg.Timezones.ServiceLocation(serviceID), nil
+}
+
+// GetBusinessHours returns the weekly hours of the service and the
+// overrides from today on
+func (u *businessHoursUsecase) GetBusinessHours(serviceID int) (*dto.BusinessHoursResponse, error) {
+	location, err := u.serviceLocation(serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.BusinessHoursResponse{
+		ServiceID: serviceID,
+		Timezone:  location.String(),
+		Overrides: []*dto.HoursOverrideResponse{},
+	}
+	if weekly, exists := u.repo.GetHours(serviceID); exists {
+		res.Weekly = map[string][]dto.OpeningHours{}
+		for name, weekday := range weekdayNames {
+			if hours := weekly.Weekly[weekday]; len(hours) > 0 {
+				res.Weekly[name] = toOpeningHoursResponse(hours)
+			}
+		}
+		res.UpdatedAt = weekly.UpdatedAt
+	} else {
+		res.AlwaysOpen = true
+	}
+
+	from := today(location).Format(models.DateLayout)
+	for _, override := range u.repo.GetOverrides(serviceID) {
+		if override.Date >= from {
+			res.Overrides = append(res.Overrides, toHoursOverrideResponse(override))
+		}
+	}
+	return res, nil
+}
+
+// SetBusinessHours replaces the weekly hours of the service, bookings that
+// were made already are kept
+func (u *businessHoursUsecase) SetBusinessHours(serviceID int, req dto.BusinessHoursRequest) (*dto.BusinessHoursResponse, error) {
+	if _, err := u.serviceLocation(serviceID); err != nil {
+		return nil, err
+	}
+
+	weekly := map[time.Weekday][]models.OpeningHours{}
+	for name, hours := range req.Weekly {
+		weekday, exists := weekdayNames[strings.ToLower(name)]
+		if !exists {
+			return nil, fmt.Errorf("%w: %q is not a weekday", ErrInvalidBusinessHours, name)
+		}
+		opening, err := toOpeningHours(hours)
+		if err != nil {
+			return nil, err
+		}
+		weekly[weekday] = opening
+	}
+
+	u.repo.SaveHours(models.BusinessHours{ServiceID: serviceID, Weekly: weekly})
+	return u.GetBusinessHours(serviceID)
+}
+
+// DeleteBusinessHours opens the service all the time again, overrides are
+// kept
+func (u *businessHoursUsecase) DeleteBusinessHours(serviceID int) error {
+	if _, err := u.serviceLocation(serviceID); err != nil {
+		return err
+	}
+	if !u.repo.DeleteHours(serviceID) {
+		return ErrBusinessHoursNotFound
+	}
+	return nil
+}
+
+// GetHoursOverrides lists the overrides of the service including the ones
+// for every service, serviceID 0 only lists the ones for every service
+func (u *businessHoursUsecase) GetHoursOverrides(serviceID int) []*dto.HoursOverrideResponse {
+	res := []*dto.HoursOverrideResponse{}
+	for _, override := range u.repo.GetOverrides(serviceID) {
+		res = append(res, toHoursOverrideResponse(override))
+	}
+	return res
+}
+
+// CreateHoursOverride sets the hours of one date, replacing the override the
+// service already had on that date
+func (u *businessHoursUsecase) CreateHoursOverride(req dto.HoursOverrideRequest) (*dto.HoursOverrideResponse, error) {
+	if req.ServiceID != 0 {
+		if _, err := u.serviceLocation(req.ServiceID); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := time.Parse(models.DateLayout, req.Date); err != nil {
+		return nil, fmt.Errorf("%w: date must be a date such as 2024-12-05", ErrInvalidBusinessHours)
+	}
+	hours, err := toOpeningHours(req.Hours)
+	if err != nil {
+		return nil, err
+	}
+
+	override := u.repo.SaveOverride(models.HoursOverride{
+		ServiceID: req.ServiceID,
+		Date:      req.Date,
+		Hours:     hours,
+		Reason:    strings.TrimSpace(req.Reason),
+		Source:    models.OverrideManual,
+	})
+	return toHoursOverrideResponse(override), nil
+}
+
+// DeleteHoursOverride
+func (u *businessHoursUsecase) DeleteHoursOverride(id int) error {
+	if !u.repo.DeleteOverride(id) {
+		return ErrHoursOverrideNotFound
+	}
+	return nil
+}
+
+// ImportHolidays closes the service, or every service when serviceID is 0,
+// on each day of the all-day events of an iCalendar file. Importing the
+// file again replaces the closures of the same dates.
+func (u *businessHoursUsecase) ImportHolidays(serviceID int, calendar string) (*dto.HolidayImportResponse, error) {
+	location := u.cfg.Timezones.ServiceLocation(serviceID)
+	if serviceID != 0 {
+		var err error
+		if location, err = u.serviceLocation(serviceID); err != nil {
+			return nil, err
+		}
+	}
+	events, err := utils.ParseICalendar(calendar)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHolidayCalendar, err)
+	}
+
+	res := &dto.HolidayImportResponse{Imported: []*dto.HoursOverrideResponse{}}
+	skip := func(event utils.ICalEvent, reason string) {
+		res.Skipped = append(res.Skipped, dto.SkippedHoliday{UID: event.UID, Summary: event.Summary, Reason: reason})
+	}
+	// all-day events are dates, they are compared to the date of the service
+	from := today(location)
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	for _, event := range events {
+		days := int(event.End.Sub(event.Start).Hours() / 24)
+		switch {
+		case event.Status == utils.ICalCancelled:
+			skip(event, "the event is canceled")
+		case !event.AllDay:
+			skip(event, "only all-day events close whole days")
+		case event.RRule != "":
+			skip(event, "recurring events are not supported")
+		case days < 1 || days > maxClosureDays:
+			skip(event, fmt.Sprintf("the event must last between 1 and %d days", maxClosureDays))
+		case !event.End.After(from):
+			skip(event, "the event is in the past")
+		default:
+			for day := event.Start; day.Before(event.End); day = day.AddDate(0, 0, 1) {
+				if day.Before(from) {
+					continue
+				}
+				override := u.repo.SaveOverride(models.HoursOverride{
+					ServiceID: serviceID,
+					Date:      day.Format(models.DateLayout),
+					Reason:    event.Summary,
+					Source:    models.OverrideICS,
+				})
+				res.Imported = append(res.Imported, toHoursOverrideResponse(override))
+			}
+		}
+	}
+	return res, nil
+}
+
+// GetSchedule lists when the service is open on each date from from to to,
+// both included, a week from today by default
+func (u *businessHoursUsecase) GetSchedule(serviceID int, from, to string) (*dto.ScheduleResponse, error) {
+	location, err := u.serviceLocation(serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := today(location)
+	if from != "" {
+		if start, err = time.ParseInLocation(models.DateLayout, from, location); err != nil {
+			return nil, fmt.Errorf("%w: from must be a date such as 2024-12-05", ErrInvalidBusinessHours)
+		}
+	}
+	end := start.AddDate(0, 0, 6)
+	if to != "" {
+		if end, err = time.ParseInLocation(models.DateLayout, to, location); err != nil {
+			return nil, fmt.Errorf("%w: to must be a date such as 2024-12-05", ErrInvalidBusinessHours)
+		}
+	}
+	if end.Before(start) || end.After(start.AddDate(0, 0, maxScheduleDays-1)) {
+		return nil, fmt.Errorf("%w: to must be on or after from and at most %d days later", ErrInvalidBusinessHours, maxScheduleDays-1)
+	}
+
+	res := &dto.ScheduleResponse{ServiceID: serviceID, Timezone: location.String(), Days: []dto.ScheduleDay{}}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		hours, override := hoursOn(u.repo, serviceID, day)
+		scheduleDay := dto.ScheduleDay{
+			Date:   day.Format(models.DateLayout),
+			Closed: len(hours) == 0,
+			Hours:  toOpeningHoursResponse(hours),
+		}
+		if override != nil {
+			scheduleDay.Reason = override.Reason
+		}
+		res.Days = append(res.Days, scheduleDay)
+	}
+	return res, nil
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/repository"
+)
+
+type (
+	CouponUsecase interface {
+		CreateCoupon(req dto.CouponRequest) (*dto.CouponResponse, error)
+		GetAllCoupons() []*dto.CouponResponse
+		GetCoupon(id int) (*dto.CouponResponse, error)
+		UpdateCoupon(id int, req dto.CouponRequest) (*dto.CouponResponse, error)
+		DeleteCoupon(id int) error
+	}
+
+	couponUsecase struct {
+		repo repository.CouponRepository
+	}
+)
+
+func NewCouponUsecase(repo repository.CouponRepository) CouponUsecase {
+	return &couponUsecase{repo: repo}
+}
+
+// normalizeCouponCode makes codes case insensitive
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// toCoupon validates the request and builds the coupon it describes
+func toCoupon(req dto.CouponRequest) (models.Coupon, error) {
+	coupon := models.Coupon{
+		Code:           normalizeCouponCode(req.Code),
+		Type:           req.Type,
+		Percent:        req.Percent,
+		Amount:         req.Amount,
+		MaxRedemptions: req.MaxRedemptions,
+		MaxPerUser:     req.MaxPerUser,
+		ServiceIDs:     req.ServiceIDs,
+		Disabled:       req.Disabled,
+	}
+	if coupon.Code == "" {
+		return coupon, fmt.Errorf("%w: code is required", ErrInvalidCouponRequest)
+	}
+	if coupon.Type != models.CouponPercent && coupon.Type != models.CouponFixed {
+		return coupon, fmt.Errorf("%w: type must be percent or fixed", ErrInvalidCouponRequest)
+	}
+	if coupon.Type == models.CouponPercent && (coupon.Percent <= 0 || coupon.Percent > 100 || !coupon.Amount.IsZero()) {
+		return coupon, fmt.Errorf("%w: percent coupons need a percent greater than 0 and at most 100", ErrInvalidCouponRequest)
+	}
+	if coupon.Type == models.CouponFixed && (coupon.Amount.Amount <= 0 || coupon.Percent != 0) {
+		return coupon, fmt.Errorf("%w: fixed coupons need an amount greater than 0", ErrInvalidCouponRequest)
+	}
+	if coupon.MaxRedemptions < 0 || coupon.MaxPerUser < 0 {
+		return coupon, fmt.Errorf("%w: limits must not be negative", ErrInvalidCouponRequest)
+	}
+
+	var err error
+	if req.ValidFrom != "" {
+		if coupon.ValidFrom, err = time.Parse(time.RFC3339, req.ValidFrom); err != nil {
+			return coupon, fmt.Errorf("%w: valid_from must be an RFC3339 time", ErrInvalidCouponRequest)
+		}
+	}
+	if req.ValidUntil != "" {
+		if coupon.ValidUntil, err = time.Parse(time.RFC3339, req.ValidUntil); err != nil {
+			return coupon, fmt.Errorf("%w: valid_until must be an RFC3339 time", ErrInvalidCouponRequest)
+		}
+	}
+	if !coupon.ValidFrom.IsZero() && !coupon.ValidUntil.IsZero() && !coupon.ValidUntil.After(coupon.ValidFrom) {
+		return coupon, fmt.Errorf("%w: valid_until must be after valid_from", ErrInvalidCouponRequest)
+	}
+	return coupon, nil
+}
+
+// Create
+func (u *couponUsecase) CreateCoupon(req dto.CouponRequest) (*dto.CouponResponse, error) {
+	coupon, err := toCoupon(req)
+	if err != nil {
+		return nil, err
+	}
+	created, err := u.repo.Create(coupon)
+	if err != nil {
+		return nil, ErrCouponCodeTaken
+	}
+	return toCouponResponse(created), nil
+}
+
+// Get all coupons
+func (u *couponUsecase) GetAllCoupons() []*dto.CouponResponse {
+	coupons := u.repo.GetAll()
+	sort.Slice(coupons, func(i, j int) bool {
+		return coupons[i].ID < coupons[j].ID
+	})
+
+	res := []*dto.CouponResponse{}
+	for _, coupon := range coupons {
+		res = append(res, toCouponResponse(coupon))
+	}
+	return res
+}
+
+// Get coupon by id
+func (u *couponUsecase) GetCoupon(id int) (*dto.CouponResponse, error) {
+	coupon, exists := u.repo.GetByID(id)
+	if !exists {
+		return nil, ErrCouponNotFound
+	}
+	return toCouponResponse(coupon), nil
+}
+
+// Update replaces every setting of the coupon
+func (u *couponUsecase) UpdateCoupon(id int, req dto.CouponRequest) (*dto.CouponResponse, error) {
+	coupon, err := toCoupon(req)
+	if err != nil {
+		return nil, err
+	}
+	coupon.ID = id
+	err = u.repo.Update(&coupon)
+	if errors.Is(err, repository.ErrCouponCodeTaken) {
+		return nil, ErrCouponCodeTaken
+	}
+	if err != nil {
+		return nil, ErrCouponNotFound
+	}
+	return toCouponResponse(&coupon), nil
+}
+
+// Delete
+func (u *couponUsecase) DeleteCoupon(id int) error {
+	if err := u.repo.Delete(id); err != nil {
+		return ErrCouponNotFound
+	}
+	return nil
+}
+
+func toCouponResponse(coupon *models.Coupon) *dto.CouponResponse {
+	res := &dto.CouponResponse{
+		ID:             coupon.ID,
+		Code:           coupon.Code,
+		Type:           coupon.Type,
+		Percent:        coupon.Percent,
+		Amount:         coupon.Amount,
+		MaxRedemptions: coupon.MaxRedemptions,
+		MaxPerUser:     coupon.MaxPerUser,
+		ServiceIDs:     coupon.ServiceIDs,
+		Disabled:       coupon.Disabled,
+		Redeemed:       coupon.Redeemed,
+		CreatedAt:      coupon.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      coupon.UpdatedAt.Format(time.RFC3339),
+	}
+	if !coupon.ValidFrom.IsZero() {
+		res.ValidFrom = coupon.ValidFrom.Format(time.RFC3339)
+	}
+	if !coupon.ValidUntil.IsZero() {
+		res.ValidUntil = coupon.ValidUntil.Format(time.RFC3339)
+	}
+	return res
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import "errors"
+
+type bookingUsecaseErrCode string
+
+// codes returned when a booking request breaks a booking policy
+const (
+	PendingLimitErr   bookingUsecaseErrCode = "booking-001"
+	DailySpendErr     bookingUsecaseErrCode = "booking-002"
+	VelocityErr       bookingUsecaseErrCode = "booking-003"
+	BlockedUserErr    bookingUsecaseErrCode = "booking-004"
+	BlockedServiceErr bookingUsecaseErrCode = "booking-005"
+)
+
+var (
+	ErrBookingNotFound   = errors.New("booking not found")
+	ErrForbidden         = errors.New("you are not allowed to access this booking")
+	ErrInvalidStatus     = errors.New("invalid booking status")
+	ErrInvalidTransition = errors.New("booking status cannot be changed")
+	ErrInvalidPatch      = errors.New("invalid booking patch")
+	ErrFieldForbidden    = errors.New("you are not allowed to change this field")
+	ErrServiceNotFound   = errors.New("service not found")
+	ErrInvalidSlot       = errors.New("invalid booking slot")
+	ErrSlotFull          = errors.New("no capacity left in this slot")
+	ErrInvalidPrice      = errors.New("invalid price")
+	ErrCurrencyMismatch  = errors.New("currency does not match")
+
+	ErrRescheduleNotAllowed = errors.New("booking cannot be rescheduled")
+	ErrInvoiceNotFound      = errors.New("booking has no invoice")
+
+	ErrPaymentNotFound         = errors.New("payment not found")
+	ErrPaymentFailed           = errors.New("payment provider is not available")
+	ErrInvalidPaymentCallback  = errors.New("invalid payment callback")
+	ErrInvalidPaymentSignature = errors.New("invalid payment signature")
+
+	ErrApiKeyNotFound       = errors.New("api key not found")
+	ErrApiKeyInvalid        = errors.New("apikey is invalid or required")
+	ErrApiKeyRevoked        = errors.New("apikey has been revoked")
+	ErrApiKeyQuotaExceeded  = errors.New("apikey daily quota exceeded")
+	ErrInvalidApiKeyRequest = errors.New("invalid api key request")
+
+	ErrWaitlistNotFound    = errors.New("waitlist entry not found")
+	ErrSlotAvailable       = errors.New("slot still has capacity, book it instead")
+	ErrAlreadyWaitlisted   = errors.New("already on the waitlist of this slot")
+	ErrNoWaitlistOffer     = errors.New("waitlist entry has no open offer")
+	ErrWaitlistEntryClosed = errors.New("waitlist entry is closed")
+
+	ErrSeriesNotFound = errors.New("booking series not found")
+	ErrInvalidSeries  = errors.New("invalid booking series")
+	ErrSeriesConflict = errors.New("no occurrence of the series could be booked")
+
+	ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+	ErrInvalidCalendarFeed  = errors.New("invalid calendar feed request")
+	ErrNoSlot               = errors.New("booking has no slot")
+
+	ErrServiceClosed          = errors.New("service is closed at this time")
+	ErrInvalidBusinessHours   = errors.New("invalid business hours")
+	ErrBusinessHoursNotFound  = errors.New("service has no business hours")
+	ErrHoursOverrideNotFound  = errors.New("hours override not found")
+	ErrInvalidHolidayCalendar = errors.New("invalid holiday calendar")
+
+	ErrResourceNotFound    = errors.New("resource not found")
+	ErrInvalidResource     = errors.New("invalid resource")
+	ErrResourceInUse       = errors.New("resource is assigned to upcoming bookings")
+	ErrNoResourceAvailable = errors.New("no resource available for this slot")
+
+	ErrCouponNotFound       = errors.New("coupon not found")
+	ErrInvalidCouponRequest = errors.New("invalid coupon request")
+	ErrCouponCodeTaken      = errors.New("coupon code already exists")
+	ErrCouponInvalid        = errors.New("coupon cannot be used")
+	ErrCouponUsedUp         = errors.New("coupon has no redemptions left")
+
+	ErrInvalidImport = errors.New("invalid booking import")
+	ErrInvalidBatch  = errors.New("invalid booking batch")
+	ErrInvalidReport = errors.New("invalid report")
+)
+
+// PolicyError is returned when a booking request breaks a booking policy,
+// Code tells the client which one
+type PolicyError struct {
+	Code    bookingUsecaseErrCode
+	Message string
+}
+
+func (e *PolicyError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/repository"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// pricing rules
+const (
+	RuleDuration = "duration"
+	RulePeak     = "peak_hour"
+	RuleWeekend  = "weekend"
+	RuleTier     = "user_tier"
+)
+
+type (
+	PricingUsecase interface {
+		Quote(claims *utils.Claims, req dto.PriceQuoteRequest) (*dto.PriceBreakdown, error)
+		// Price calculates the price of booking the service for userID, start
+		// is zero for bookings without a slot
+		Price(service *models.Service, userID int, start time.Time, duration time.Duration) *dto.PriceBreakdown
+	}
+
+	pricingUsecase struct {
+		services repository.ServiceRepository
+		cfg      *config.Config
+	}
+)
+
+func NewPricingUsecase(services repository.ServiceRepository, cfg *config.Config) PricingUsecase {
+	return &pricingUsecase{
+		services: services,
+		cfg:      cfg,
+	}
+}
+
+// bookingDuration parses the duration of a booking, it must be a whole
+// number of service slots and defaults to one slot
+func bookingDuration(service *models.Service, value string) (time.Duration, error) {
+	if value == "" {
+		return service.Duration, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 || duration%service.Duration != 0 {
+		return 0, fmt.Errorf("%w: duration must be a multiple of %s", ErrInvalidSlot, service.Duration)
+	}
+	return duration, nil
+}
+
+// Quote prices a booking without creating it, customers get their own prices
+// and anonymous callers the prices of no tier
+func (p *pricingUsecase) Quote(claims *utils.Claims, req dto.PriceQuoteRequest) (*dto.PriceBreakdown, error) {
+	switch {
+	case claims == nil:
+		req.UserID = 0
+	case !claims.IsStaff():
+		req.UserID = claims.Id
+	}
+
+	service, exists := p.services.GetByID(req.ServiceID)
+	if !exists {
+		return nil, ErrServiceNotFound
+	}
+	duration, err := bookingDuration(service, req.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	var start time.Time
+	if req.StartAt != "" {
+		start, err = time.Parse(time.RFC3339, req.StartAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: start_at must be an RFC3339 time", ErrInvalidSlot)
+		}
+	}
+	return p.Price(service, req.UserID, start, duration), nil
+}
+
+// Price applies the pricing rules in order, each percentage is of the price
+// after the rules before it
+func (p *pricingUsecase) Price(service *models.Service, userID int, start time.Time, duration time.Duration) *dto.PriceBreakdown {
+	rules := p.cfg.Pricing
+	units := int(duration / service.Duration)
+	if units < 1 {
+		units = 1
+	}
+	breakdown := &dto.PriceBreakdown{
+		ServiceID: service.ID,
+		BasePrice: service.BasePrice,
+		Units:     units,
+	}
+	total := service.BasePrice.Mul(int64(units))
+
+	adjust := func(rule, description string, percent float64) {
+		amount := total.Percent(percent)
+		if amount.IsZero() {
+			return
+		}
+		total = total.Add(amount)
+		breakdown.Adjustments = append(breakdown.Adjustments, dto.PriceAdjustment{
+			Rule:        rule,
+			Description: description,
+			Amount:      amount,
+		})
+	}
+
+	// the longest duration the booking reaches sets the multiplier
+	multiplier := 1.0
+	matched := time.Duration(0)
+	for _, tier := range rules.DurationMultipliers {
+		if duration >= tier.MinDuration && tier.MinDuration > matched {
+			multiplier, matched = tier.Multiplier, tier.MinDuration
+		}
+	}
+	if multiplier != 1 {
+		adjust(RuleDuration, fmt.Sprintf("x%g for %s or longer", multiplier, matched), (multiplier-1)*100)
+	}
+
+	if !start.IsZero() {
+		// peak hours and weekends are in the time zone of the service
+		start = start.In(p.cfg.Timezones.ServiceLocation(service.ID))
+		if rules.PeakStart != rules.PeakEnd && start.Hour() >= rules.PeakStart && start.Hour() < rules.PeakEnd {
+			adjust(RulePeak, fmt.Sprintf("+%g%% between %02d:00 and %02d:00", rules.PeakSurcharge, rules.PeakStart, rules.PeakEnd), rules.PeakSurcharge)
+		}
+		if weekday := start.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			adjust(RuleWeekend, fmt.Sprintf("+%g%% on weekends", rules.WeekendSurcharge), rules.WeekendSurcharge)
+		}
+	}
+
+	if tier, exists := rules.UserTiers[userID]; exists {
+		discount := rules.TierDiscounts[tier]
+		adjust(RuleTier, fmt.Sprintf("-%g%% for %s members", discount, tier), -discount)
+	}
+
+	breakdown.Total = total
+	return breakdown
+}
+
+// overridePrice replaces the calculated total with a price set by staff, the
+// price must be in the currency of the breakdown
+func overridePrice(breakdown *dto.PriceBreakdown, price models.Money) (*dto.PriceBreakdown, error) {
+	if price.Amount <= 0 {
+		return nil, fmt.Errorf("%w: price must be greater than 0", ErrInvalidPrice)
+	}
+	if breakdown.Total.Currency != "" && price.Currency != breakdown.Total.Currency {
+		return nil, fmt.Errorf("%w: price must be in %s", ErrCurrencyMismatch, breakdown.Total.Currency)
+	}
+	overridden := *breakdown
+	overridden.Total = price
+	overridden.Override = true
+	return &overridden, nil
+}
@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/dto"
+	models "github.com/Eursukkul/fiber-booking-system/model"
+	"github.com/Eursukkul/fiber-booking-system/repository"
+)
+
+type (
+	ResourceUsecase interface {
+		CreateResource(req dto.ResourceRequest) (*dto.ResourceResponse, error)
+		GetAllResources() []*dto.ResourceResponse
+		GetResource(id int) (*dto.ResourceResponse, error)
+		UpdateResource(id int, req dto.ResourceRequest) (*dto.ResourceResponse, error)
+		DeleteResource(id int) error
+		GetServiceRequirements(serviceID int) (*dto.ServiceRequirementsResponse, error)
+		SetServiceRequirements(serviceID int, req dto.ServiceRequirementsRequest) (*dto.ServiceRequirementsResponse, error)
+		GetResourceSchedule(id int, from, to string, location *time.Location) (*dto.ResourceScheduleResponse, error)
+	}
+
+	resourceUsecase struct {
+		repo     repository.ResourceRepository
+		services repository.ServiceRepository
+		bookings repository.BookingRepository
+		cfg      *config.Config
+	}
+)
+
+func NewResourceUsecase(repo repository.ResourceRepository, services repository.ServiceRepository, bookings repository.BookingRepository, cfg *config.Config) ResourceUsecase {
+	return &resourceUsecase{repo: repo, services: services, bookings: bookings, cfg: cfg}
+}
+
+// normalizeSkills makes skills case insensitive and drops duplicates
+func normalizeSkills(skills []string) []string {
+	res := []string{}
+	seen := map[string]bool{}
+	for _, skill := range skills {
+		skill = strings.ToLower(strings.TrimSpace(skill))
+		if skill != "" && !seen[skill] {
+			seen[skill] = true
+			res = append(res, skill)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// toResource validates the request and builds the resource it describes
+func toResource(req dto.ResourceRequest) (models.Resource, error) {
+	resource := models.Resource{
+		Name:     strings.TrimSpace(req.Name),
+		Type:     req.Type,
+		Skills:   normalizeSkills(req.Skills),
+		Disabled: req.Disabled,
+	}
+	if resource.Name == "" {
+		return resource, fmt.Errorf("%w: name is required", ErrInvalidResource)
+	}
+	if !models.IsResourceType(resource.Type) {
+		return resource, fmt.Errorf("%w: type must be staff, room or equipment", ErrInvalidResource)
+	}
+	return resource, nil
+}
+
+func toResourceResponse(resource *models.Resource) *dto.ResourceResponse {
+	return &dto.ResourceResponse{
+		ID:        resource.ID,
+		Name:      resource.Name,
+		Type:      resource.Type,
+		Skills:    resource.Skills,
+		Disabled:  resource.Disabled,
+		CreatedAt: resource.CreatedAt,
+		UpdatedAt: resource.UpdatedAt,
+	}
+}
+
+// Create
+func (u *resourceUsecase) CreateResource(req dto.ResourceRequest) (*dto.ResourceResponse, error) {
+	resource, err := toResource(req)
+	if err != nil {
+		return nil, err
+	}
+	return toResourceResponse(u.repo.Create(resource)), nil
+}
+
+// Get all resources
+func (u *resourceUsecase) GetAllResources() []*dto.ResourceResponse {
+	res := []*dto.ResourceResponse{}
+	for _, resource := range u.repo.GetAll() {
+		res = append(res, toResourceResponse(resource))
+	}
+	return res
+}
+
+// Get resource by id
+func (u *resourceUsecase) GetResource(id int) (*dto.ResourceResponse, error) {
+	resource, exists := u.repo.GetByID(id)
+	if !exists {
+		return nil, ErrResourceNotFound
+	}
+	return toResourceResponse(resource), nil
+}
+
+// Update replaces the resource, the bookings it is assigned to keep it
+func (u *resourceUsecase) UpdateResource(id int, req dto.ResourceRequest) (*dto.ResourceResponse, error) {
+	resource, err := toResource(req)
+	if err != nil {
+		return nil, err
+	}
+	resource.ID = id
+	if !u.repo.Update(&resource) {
+		return nil, ErrResourceNotFound
+	}
+	return toResourceResponse(&resource), nil
+}
+
+// DeleteResource removes a resource that no upcoming booking needs, disable
+// it instead to keep it on its bookings
+func (u *resourceUsecase) DeleteResource(id int) error {
+	if _, exists := u.repo.GetByID(id); !exists {
+		return ErrResourceNotFound
+	}
+	now := time.Now()
+	for _, booking := range u.bookings.GetAll() {
+		if !models.IsActiveStatus(booking.Status) || !booking.EndAt.After(now) {
+			continue
+		}
+		for _, resource := range booking.Resources {
+			if resource.ID == id {
+				return fmt.Errorf("%w: booking %d, disable the resource instead", ErrResourceInUse, booking.ID)
+			}
+		}
+	}
+	u.repo.Delete(id)
+	return nil
+}
+
+func toRequirementsResponse(serviceID int, requirements []models.ResourceRequirement) *dto.ServiceRequirementsResponse {
+	res := &dto.ServiceRequirementsResponse{ServiceID: serviceID, Requirements: []dto.ResourceRequirement{}}
+	for _, requirement := range requirements {
+		res.Requirements = append(res.Requirements, dto.ResourceRequirement{
+			Type:     requirement.Type,
+			Skills:   requirement.Skills,
+			Quantity: requirement.Quantity,
+		})
+	}
+	return res
+}
+
+// GetServiceRequirements returns the resources each booking of the service
+// needs
+func (u *resourceUsecase) GetServiceRequirements(serviceID int) (*dto.ServiceRequirementsResponse, error) {
+	if _, exists := u.services.GetByID(serviceID); !exists {
+		return nil, ErrServiceNotFound
+	}
+	return toRequirementsResponse(serviceID, u.repo.GetRequirements(serviceID)), nil
+}
+
+// SetServiceRequirements replaces the requirements of the service, bookings
+// made already keep their resources
+func (u *resourceUsecase) SetServiceRequirements(serviceID int, req dto.ServiceRequirementsRequest) (*dto.ServiceRequirementsResponse, error) {
+	if _, exists := u.services.GetByID(serviceID); !exists {
+		return nil, ErrServiceNotFound
+	}
+
+	requirements := []models.ResourceRequirement{}
+	for _, requirement := range req.Requirements {
+		if !models.IsResourceType(requirement.Type) {
+			return nil, fmt.Errorf("%w: type must be staff, room or equipment", ErrInvalidResource)
+		}
+		if requirement.Quantity < 1 {
+			return nil, fmt.Errorf("%w: quantity must be at least 1", ErrInvalidResource)
+		}
+		requirements = append(requirements, models.ResourceRequirement{
+			Type:     requirement.Type,
+			Skills:   normalizeSkills(requirement.Skills),
+			Quantity: requirement.Quantity,
+		})
+	}
+
+	u.repo.SetRequirements(serviceID, requirements)
+	return toRequirementsResponse(serviceID, requirements), nil
+}
+
+// GetResourceSchedule lists the active bookings of the resource from from
+// to to, both included and a week from today by default. Dates and times are
+// in location, the default time zone when it is nil.
+func (u *resourceUsecase) GetResourceSchedule(id int, from, to string, location *time.Location) (*dto.ResourceScheduleResponse, error) {
+	resource, exists := u.repo.GetByID(id)
+	if !exists {
+		return nil, ErrResourceNotFound
+	}
+	if location == nil {
+		location = u.cfg.Timezones.ServiceLocation(0)
+	}
+
+	start := today(location)
+	var err error
+	if from != "" {
+		if start, err = time.ParseInLocation(models.DateLayout, from, location); err != nil {
+			return nil, fmt.Errorf("%w: from must be a date such as 2024-12-05", ErrInvalidResource)
+		}
+	}
+	last := start.AddDate(0, 0, 6)
+	if to != "" {
+		if last, err = time.ParseInLocation(models.DateLayout, to, location); err != nil {
+			return nil, fmt.Errorf("%w: to must be a date such as 2024-12-05", ErrInvalidResource)
+		}
+	}
+	if last.Before(start) || last.After(start.AddDate(0, 0, maxScheduleDays-1)) {
+		return nil, fmt.Errorf("%w: to must be on or after from and at most %d days later", ErrInvalidResource, maxScheduleDays-1)
+	}
+	end := last.AddDate(0, 0, 1)
+
+	res := &dto.ResourceScheduleResponse{
+		Resource: toResourceResponse(resource),
+		Timezone: location.String(),
+		From:     start.Format(models.DateLayout),
+		To:       last.Format(models.DateLayout),
+		Bookings: []dto.ResourceScheduleBooking{},
+	}
+	for _, booking := range u.bookings.GetAll() {
+		if !models.IsActiveStatus(booking.Status) || !booking.StartAt.Before(end) || !start.Before(booking.EndAt) {
+			continue
+		}
+		for _, assigned := range booking.Resources {
+			if assigned.ID == id {
+				res.Bookings = append(res.Bookings, dto.ResourceScheduleBooking{
+					BookingID: booking.ID,
+					ServiceID: booking.ServiceID,
+					UserID:    booking.UserID,
+					Status:    booking.Status,
+					StartAt:   booking.StartAt.In(location),
+					EndAt:     booking.EndAt.In(location),
+				})
+			}
+		}
+	}
+	sort.Slice(res.Bookings, func(i, j int) bool {
+		return res.Bookings[i].StartAt.Before(res.Bookings[j].StartAt)
+	})
+	return res, nil
+}
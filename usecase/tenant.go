@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"github.com/Eursukkul/fiber-booking-system/config"
+	"github.com/Eursukkul/fiber-booking-system/repository"
+	"github.com/Eursukkul/fiber-booking-system/utils"
+)
+
+// Tenant are the usecases of one business unit
+type Tenant struct {
+	Bookings      BookingUsecase
+	Pricing       PricingUsecase
+	Coupons       CouponUsecase
+	BusinessHours BusinessHoursUsecase
+	Resources     ResourceUsecase
+}
+
+// NewTenant builds the usecases of a tenant on repositories of its own so
+// no query can read the data of another tenant, cfg is the config of the
+// tenant from config.ForTenant
+func NewTenant(cfg *config.Config, provider utils.PaymentProvider) *Tenant {
+	bookingRepo := repository.NewMockBookingRepository()
+	serviceRepo := repository.NewMockServiceRepository()
+	couponRepo := repository.NewMockCouponRepository()
+	businessHoursRepo := repository.NewMockBusinessHoursRepository()
+	resourceRepo := repository.NewMockResourceRepository()
+	pricing := NewPricingUsecase(serviceRepo, cfg)
+
+	bookings := NewBookingUsecase(BookingRepositories{
+		Bookings:  bookingRepo,
+		Services:  serviceRepo,
+		History:   repository.NewMockBookingHistoryRepository(),
+		Coupons:   couponRepo,
+		Invoices:  repository.NewMockInvoiceRepository(),
+		Payments:  repository.NewMockPaymentRepository(),
+		Waitlist:  repository.NewMockWaitlistRepository(),
+		Series:    repository.NewMockBookingSeriesRepository(),
+		Calendars: repository.NewMockCalendarFeedRepository(),
+		Hours:     businessHoursRepo,
+		Resources: resourceRepo,
+	}, utils.NewInMemoryCache(), pricing, provider, cfg)
+
+	return &Tenant{
+		Bookings:      bookings,
+		Pricing:       pricing,
+		Coupons:       NewCouponUsecase(couponRepo),
+		BusinessHours: NewBusinessHoursUsecase(businessHoursRepo, serviceRepo, cfg),
+		Resources:     NewResourceUsecase(resourceRepo, serviceRepo, bookingRepo, cfg),
+	}
+}
+
+// NewPaymentProvider returns the payment provider chosen in the config,
+// shared by every tenant
+func NewPaymentProvider(cfg *config.Config) utils.PaymentProvider {
+	if cfg.Payment.Provider == "http" {
+		return utils.NewHTTPPaymentProvider(cfg.Payment.URL, cfg.Payment.APIKey, cfg.Payment.Timeout)
+	}
+	return utils.NewFakePaymentProvider()
+}
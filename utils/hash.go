@@ -1,13 +1,54 @@
 package utils
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"strconv"
 )
 
+// prefixes of every issued api key and calendar token, make leaked keys
+// easy to recognise
+const (
+	apiKeyPrefix        = "bk_"
+	calendarTokenPrefix = "cal_"
+)
+
 func HashID(id int) string {
 	hash := sha256.New()
 	hash.Write([]byte(strconv.Itoa(id)))
 	return hex.EncodeToString(hash.Sum(nil))
-}
\ No newline at end of file
+}
+
+// GenerateApiKey returns a new random api key and the short prefix that is
+// safe to display
+func GenerateApiKey() (key string, prefix string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:len(apiKeyPrefix)+6], nil
+}
+
+// HashApiKey returns the hash that is stored instead of the api key
+func HashApiKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// GenerateCalendarToken returns a new random token for a calendar feed URL
+func GenerateCalendarToken() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return calendarTokenPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// HashCalendarToken returns the hash that is stored instead of the token
+func HashCalendarToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,331 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// iCalendar event statuses
+const (
+	ICalTentative = "TENTATIVE"
+	ICalConfirmed = "CONFIRMED"
+	ICalCancelled = "CANCELLED"
+)
+
+// icalLocalTime is the form of DATE-TIME values with a TZID
+const icalLocalTime = "20060102T150405"
+
+// icalDate is the form of DATE values of all-day events
+const icalDate = "20060102"
+
+// icalLineLength is the longest content line in octets, longer lines are
+// folded
+const icalLineLength = 75
+
+// ICalEvent is one VEVENT, Sequence goes up every time the event changes so
+// calendar apps replace their copy. All-day events start and end at
+// midnight UTC of their dates, End is the day after the last one.
+type ICalEvent struct {
+	UID          string
+	Sequence     int
+	Start        time.Time
+	End          time.Time
+	AllDay       bool
+	RRule        string
+	Summary      string
+	Description  string
+	Status       string
+	Created      time.Time
+	LastModified time.Time
+}
+
+// BuildICalendar renders the events as an RFC 5545 calendar. Times are
+// written in location with a VTIMEZONE covering the events, or in UTC when
+// location is nil or UTC.
+func BuildICalendar(name string, location *time.Location, events []ICalEvent) string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//SPD Fiber Booking//Bookings//EN",
+		"CALSCALE:GREGORIAN",
+	}
+	if name != "" {
+		lines = append(lines, "X-WR-CALNAME:"+escapeICalText(name))
+	}
+
+	if location == nil || location == time.UTC {
+		location = nil
+	} else if len(events) > 0 {
+		from, to := events[0].Start, events[0].End
+		for _, event := range events {
+			if event.Start.Before(from) {
+				from = event.Start
+			}
+			if event.End.After(to) {
+				to = event.End
+			}
+		}
+		lines = append(lines, vtimezone(location, from, to)...)
+	}
+
+	for _, event := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+event.UID,
+			"DTSTAMP:"+icalUTC(event.LastModified),
+			"CREATED:"+icalUTC(event.Created),
+			"LAST-MODIFIED:"+icalUTC(event.LastModified),
+			fmt.Sprintf("SEQUENCE:%d", event.Sequence),
+		)
+		if event.AllDay {
+			lines = append(lines,
+				"DTSTART;VALUE=DATE:"+event.Start.Format(icalDate),
+				"DTEND;VALUE=DATE:"+event.End.Format(icalDate),
+			)
+		} else {
+			lines = append(lines,
+				icalTime("DTSTART", event.Start, location),
+				icalTime("DTEND", event.End, location),
+			)
+		}
+		if event.RRule != "" {
+			lines = append(lines, "RRULE:"+event.RRule)
+		}
+		lines = append(lines, "SUMMARY:"+escapeICalText(event.Summary))
+		if event.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escapeICalText(event.Description))
+		}
+		if event.Status != "" {
+			lines = append(lines, "STATUS:"+event.Status)
+		}
+		if event.Status == ICalCancelled {
+			lines = append(lines, "TRANSP:TRANSPARENT")
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var calendar strings.Builder
+	for _, line := range lines {
+		calendar.WriteString(foldICalLine(line))
+	}
+	return calendar.String()
+}
+
+func icalUTC(t time.Time) string {
+	return t.UTC().Format(icalLocalTime + "Z")
+}
+
+func icalTime(property string, t time.Time, location *time.Location) string {
+	if location == nil {
+		return property + ":" + icalUTC(t)
+	}
+	return property + ";TZID=" + location.String() + ":" + t.In(location).Format(icalLocalTime)
+}
+
+// vtimezone describes the offsets of location from the start of the year of
+// from to the end of the year of to. Go does not expose the rules of a time
+// zone, so every transition in between is found and written as its own
+// observance.
+func vtimezone(location *time.Location, from, to time.Time) []string {
+	start := time.Date(from.In(location).Year(), time.January, 1, 0, 0, 0, 0, location)
+	end := time.Date(to.In(location).Year()+1, time.January, 1, 0, 0, 0, 0, location)
+
+	lines := []string{"BEGIN:VTIMEZONE", "TZID:" + location.String()}
+	_, offset := start.Zone()
+	lines = append(lines, observance(start, offset)...)
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		if _, nextOffset := next.Zone(); nextOffset != offset {
+			transition := findTransition(day, next)
+			lines = append(lines, observance(transition, offset)...)
+			offset = nextOffset
+		}
+	}
+	return append(lines, "END:VTIMEZONE")
+}
+
+// findTransition returns the first second after before with the offset of
+// after, the offset changes once in between
+func findTransition(before, after time.Time) time.Time {
+	_, offset := before.Zone()
+	for after.Sub(before) > time.Second {
+		middle := before.Add(after.Sub(before) / 2).Truncate(time.Second)
+		if _, middleOffset := middle.Zone(); middleOffset == offset {
+			before = middle
+		} else {
+			after = middle
+		}
+	}
+	return after
+}
+
+// observance is the STANDARD or DAYLIGHT component that starts at t,
+// DTSTART is in the local time of the offset in effect before it
+func observance(t time.Time, offsetFrom int) []string {
+	name, offsetTo := t.Zone()
+	kind := "STANDARD"
+	if t.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	return []string{
+		"BEGIN:" + kind,
+		"DTSTART:" + t.In(time.FixedZone("", offsetFrom)).Format(icalLocalTime),
+		"TZOFFSETFROM:" + icalOffset(offsetFrom),
+		"TZOFFSETTO:" + icalOffset(offsetTo),
+		"TZNAME:" + escapeICalText(name),
+		"END:" + kind,
+	}
+}
+
+func icalOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+	offset := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+	if seconds%60 != 0 {
+		offset += fmt.Sprintf("%02d", seconds%60)
+	}
+	return offset
+}
+
+var icalTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeICalText(text string) string {
+	return icalTextEscaper.Replace(text)
+}
+
+var icalTextUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func unescapeICalText(text string) string {
+	return icalTextUnescaper.Replace(text)
+}
+
+// ParseICalendar reads the VEVENTs of an RFC 5545 calendar such as a
+// holiday calendar. Times with a TZID are read in that zone and floating
+// times in UTC, all-day events without DTEND last one day.
+func ParseICalendar(data string) ([]ICalEvent, error) {
+	// unfold continuation lines first, they start with a space or a tab
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\n ", "")
+	data = strings.ReplaceAll(data, "\n\t", "")
+
+	events := []ICalEvent{}
+	var event *ICalEvent
+	calendar := false
+	for number, line := range strings.Split(data, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("line %d is not a content line", number+1)
+		}
+		name, params, _ := strings.Cut(name, ";")
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VCALENDAR") {
+				calendar = true
+			} else if strings.EqualFold(value, "VEVENT") {
+				event = &ICalEvent{}
+			}
+			continue
+		case "END":
+			if strings.EqualFold(value, "VEVENT") && event != nil {
+				if event.Start.IsZero() {
+					return nil, fmt.Errorf("event %q has no DTSTART", event.UID)
+				}
+				if event.End.IsZero() {
+					event.End = event.Start
+					if event.AllDay {
+						event.End = event.Start.AddDate(0, 0, 1)
+					}
+				}
+				events = append(events, *event)
+				event = nil
+			}
+			continue
+		}
+		if event == nil {
+			continue
+		}
+
+		var err error
+		switch strings.ToUpper(name) {
+		case "UID":
+			event.UID = value
+		case "SUMMARY":
+			event.Summary = unescapeICalText(value)
+		case "DESCRIPTION":
+			event.Description = unescapeICalText(value)
+		case "STATUS":
+			event.Status = strings.ToUpper(value)
+		case "RRULE":
+			event.RRule = value
+		case "DTSTART":
+			event.Start, event.AllDay, err = parseICalTime(value, params)
+		case "DTEND":
+			event.End, _, err = parseICalTime(value, params)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number+1, err)
+		}
+	}
+	if !calendar {
+		return nil, fmt.Errorf("not an iCalendar file, BEGIN:VCALENDAR is missing")
+	}
+	return events, nil
+}
+
+// parseICalTime parses a DATE or DATE-TIME value, the bool reports a DATE
+func parseICalTime(value, params string) (time.Time, bool, error) {
+	location := time.UTC
+	for _, param := range strings.Split(params, ";") {
+		name, setting, _ := strings.Cut(param, "=")
+		if strings.EqualFold(name, "TZID") {
+			var err error
+			if location, err = time.LoadLocation(strings.Trim(setting, `"`)); err != nil {
+				return time.Time{}, false, fmt.Errorf("unknown TZID %s", setting)
+			}
+		}
+	}
+
+	if len(value) == len(icalDate) {
+		t, err := time.Parse(icalDate, value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %s", value)
+		}
+		return t, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		location = time.UTC
+		value = strings.TrimSuffix(value, "Z")
+	}
+	t, err := time.ParseInLocation(icalLocalTime, value, location)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date-time %s", value)
+	}
+	return t, false, nil
+}
+
+// foldICalLine ends the line with CRLF and folds it every 75 octets without
+// splitting a UTF-8 character, continuation lines start with a space
+func foldICalLine(line string) string {
+	var folded strings.Builder
+	limit := icalLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		folded.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// the leading space counts towards the length of the next line
+		limit = icalLineLength - 1
+	}
+	folded.WriteString(line + "\r\n")
+	return folded.String()
+}
@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type (
+	// SigningKey is a private key loaded from a key file, identified by kid
+	SigningKey struct {
+		Kid     string
+		Alg     string
+		File    string
+		Private crypto.Signer
+	}
+
+	// KeySet keeps the keys of the configured key files, every key is
+	// accepted for as long as its file is configured
+	KeySet struct {
+		files     []string
+		keys      map[string]*SigningKey
+		activeKid string
+		mu        sync.RWMutex
+	}
+
+	// JWK is the public part of a signing key as published in the JWKS
+	JWK struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Alg string `json:"alg"`
+		Use string `json:"use"`
+		N   string `json:"n,omitempty"`
+		E   string `json:"e,omitempty"`
+		Crv string `json:"crv,omitempty"`
+		X   string `json:"x,omitempty"`
+		Y   string `json:"y,omitempty"`
+	}
+
+	JWKS struct {
+		Keys []JWK `json:"keys"`
+	}
+)
+
+// LoadKeySet loads PEM private keys from files, an entry may be a glob
+// pattern whose matches are taken newest name first. The first file is the
+// active key and the others are only used to verify tokens. The kid of each
+// key is its file name without extension. Keys are never retired, Reload
+// picks up new files and drops the keys of files that were removed.
+func LoadKeySet(alg string, files []string) (*KeySet, error) {
+	if alg != jwt.SigningMethodRS256.Alg() && alg != jwt.SigningMethodES256.Alg() {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no key files configured, tokens could not be verified")
+	}
+
+	ks := &KeySet{files: files}
+	if err := ks.Reload(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Reload reads the key files again, the keys are only replaced when every
+// file could be loaded so a half written file never drops valid keys
+func (k *KeySet) Reload() error {
+	paths, err := expandKeyFiles(k.files)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*SigningKey, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read key %s: %w", path, err)
+		}
+		signer, err := parsePrivateKey(data)
+		if err != nil {
+			return fmt.Errorf("parse key %s: %w", path, err)
+		}
+		alg, err := algForKey(signer)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", path, err)
+		}
+		kid := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if _, exists := keys[kid]; exists {
+			return fmt.Errorf("duplicate key id %q", kid)
+		}
+		keys[kid] = &SigningKey{Kid: kid, Alg: alg, File: path, Private: signer}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = keys
+	k.activeKid = strings.TrimSuffix(filepath.Base(paths[0]), filepath.Ext(paths[0]))
+	return nil
+}
+
+// expandKeyFiles replaces the glob patterns by their matches, newest name
+// first so dated file names put the latest key in front
+func expandKeyFiles(files []string) ([]string, error) {
+	seen := map[string]bool{}
+	paths := []string{}
+	for _, file := range files {
+		matches := []string{file}
+		if strings.ContainsAny(file, "*?[") {
+			var err error
+			matches, err = filepath.Glob(file)
+			if err != nil {
+				return nil, fmt.Errorf("key pattern %s: %w", file, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no key file matches %s", file)
+			}
+			sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+		}
+		for _, path := range matches {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths, nil
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, errors.New("key type is not supported")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("key format is not supported")
+}
+
+func algForKey(signer crypto.Signer) (string, error) {
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256.Alg(), nil
+	case *ecdsa.PrivateKey:
+		if key.Curve != elliptic.P256() {
+			return "", errors.New("only P-256 keys are supported for ES256")
+		}
+		return jwt.SigningMethodES256.Alg(), nil
+	}
+	return "", errors.New("key type is not supported")
+}
+
+// StartReload reloads the key files on every interval, so keys the issuer
+// adds are accepted without a restart
+func (k *KeySet) StartReload(interval time.Duration, wg *sync.WaitGroup) {
+	ticker := time.NewTicker(interval)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range ticker.C {
+			if err := k.Reload(); err != nil {
+				log.Printf("Failed to reload signing keys, keeping the loaded ones: %v", err)
+			}
+		}
+	}()
+}
+
+// Active returns the key new tokens are signed with
+func (k *KeySet) Active() *SigningKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[k.activeKid]
+}
+
+// Sign signs the claims with the active key and sets the kid header, for
+// issuers that share the key files
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key := k.Active()
+	if key == nil {
+		return "", errors.New("no active signing key")
+	}
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Alg), claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.Private)
+}
+
+// Keyfunc selects the verification key by the kid header of the token
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("error: token has no key id")
+	}
+
+	k.mu.RLock()
+	key, ok := k.keys[kid]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, errors.New("error: unknown key id")
+	}
+	if token.Method.Alg() != key.Alg {
+		return nil, errors.New("error: unexpected signing method")
+	}
+	return key.Private.Public(), nil
+}
+
+// JWKS returns the public keys of every key that is still accepted
+func (k *KeySet) JWKS() JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range k.keys {
+		jwk := JWK{Kid: key.Kid, Alg: key.Alg, Use: "sig"}
+		switch pub := key.Private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			ecdhKey, err := pub.ECDH()
+			if err != nil {
+				continue
+			}
+			// uncompressed point: 0x04 || X || Y
+			point := ecdhKey.Bytes()[1:]
+			jwk.Kty = "EC"
+			jwk.Crv = "P-256"
+			jwk.X = base64.RawURLEncoding.EncodeToString(point[:32])
+			jwk.Y = base64.RawURLEncoding.EncodeToString(point[32:])
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+	return jwks
+}
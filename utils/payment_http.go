@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	models "github.com/Eursukkul/fiber-booking-system/model"
+)
+
+// HTTPPaymentProvider talks to a payment gateway over JSON:
+//
+//	POST {base}/authorizations                 PaymentRequest
+//	POST {base}/authorizations/{ref}/capture   {"amount": ...}
+//	POST {base}/authorizations/{ref}/void
+//	POST {base}/authorizations/{ref}/refunds   {"amount": ...}
+//
+// every call answers with a PaymentResult
+type HTTPPaymentProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewHTTPPaymentProvider(baseURL, apiKey string, timeout time.Duration) *HTTPPaymentProvider {
+	return &HTTPPaymentProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *HTTPPaymentProvider) Name() string {
+	return "http"
+}
+
+func (p *HTTPPaymentProvider) Authorize(req PaymentRequest) (*PaymentResult, error) {
+	return p.post("/authorizations", req)
+}
+
+func (p *HTTPPaymentProvider) Capture(reference string, amount models.Money) (*PaymentResult, error) {
+	return p.post("/authorizations/"+url.PathEscape(reference)+"/capture", map[string]models.Money{"amount": amount})
+}
+
+func (p *HTTPPaymentProvider) Void(reference string) (*PaymentResult, error) {
+	return p.post("/authorizations/"+url.PathEscape(reference)+"/void", nil)
+}
+
+func (p *HTTPPaymentProvider) Refund(reference string, amount models.Money) (*PaymentResult, error) {
+	return p.post("/authorizations/"+url.PathEscape(reference)+"/refunds", map[string]models.Money{"amount": amount})
+}
+
+// post sends body and decodes the result, declines are results and not
+// errors so only transport and server failures return an error
+func (p *HTTPPaymentProvider) post(path string, body interface{}) (*PaymentResult, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("payment provider: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return nil, fmt.Errorf("payment provider: %s", resp.Status)
+	}
+
+	var result PaymentResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("payment provider: invalid response: %w", err)
+	}
+	if result.Status != PaymentSucceeded && result.Status != PaymentPending && result.Status != PaymentDeclined {
+		return nil, fmt.Errorf("payment provider: unknown status %q", result.Status)
+	}
+	return &result, nil
+}
@@ -19,7 +19,8 @@ type Response struct {
 }
 
 type ErrorResponse struct {
-	Msg string `json:"message"`
+	Msg  string `json:"message"`
+	Code string `json:"code,omitempty"`
 }
 
 func NewResponse(c *fiber.Ctx) IResponse {
@@ -35,10 +36,10 @@ func (r *Response) Success(code int, data any) IResponse {
 }
 
 func (r *Response) Error(code int, traceId, msg string) IResponse {
-	_ = traceId
 	r.StatusCode = code
 	r.ErrorRes = &ErrorResponse{
-		Msg: msg,
+		Msg:  msg,
+		Code: traceId,
 	}
 	r.IsError = true
 	return r
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recurrence frequencies supported by ParseRRule
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// RRule is the part of an RFC 5545 recurrence rule used for booking series:
+// FREQ, INTERVAL, COUNT, UNTIL and BYDAY for weekly rules. A rule without
+// COUNT or UNTIL repeats forever.
+type RRule struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    time.Time
+	ByDay    []time.Weekday
+}
+
+// ParseRRule parses a rule such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10", the
+// "RRULE:" prefix is optional
+func ParseRRule(value string) (*RRule, error) {
+	rule := &RRule{Interval: 1}
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	for _, part := range strings.Split(value, ";") {
+		name, setting, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(setting)
+			if rule.Freq != FreqDaily && rule.Freq != FreqWeekly && rule.Freq != FreqMonthly {
+				return nil, fmt.Errorf("FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(setting)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("INTERVAL must be a positive number")
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(setting)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("COUNT must be a positive number")
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseRRuleTime(setting)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, day := range strings.Split(strings.ToUpper(setting), ",") {
+				weekday, exists := rruleWeekdays[day]
+				if !exists {
+					return nil, fmt.Errorf("invalid BYDAY %q", day)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		default:
+			return nil, fmt.Errorf("%s is not supported", name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot be used together")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != FreqWeekly {
+		return nil, fmt.Errorf("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	return rule, nil
+}
+
+// parseRRuleTime parses UNTIL, a UTC date-time such as 20240131T170000Z or
+// a date that includes the whole day
+func parseRRuleTime(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("UNTIL must be a UTC date-time such as 20240131T170000Z or a date")
+}
+
+// IsBounded reports whether the rule ends, by COUNT or UNTIL
+func (r *RRule) IsBounded() bool {
+	return r.Count > 0 || !r.Until.IsZero()
+}
+
+// Occurrences expands the rule from start, which is the first occurrence,
+// and returns at most limit occurrences. Months without the day of start are
+// skipped as RFC 5545 does.
+func (r *RRule) Occurrences(start time.Time, limit int) []time.Time {
+	occurrences := []time.Time{}
+	add := func(t time.Time) bool {
+		if !r.Until.IsZero() && t.After(r.Until) {
+			return false
+		}
+		if (r.Count > 0 && len(occurrences) >= r.Count) || len(occurrences) >= limit {
+			return false
+		}
+		occurrences = append(occurrences, t)
+		return true
+	}
+
+	switch r.Freq {
+	case FreqDaily:
+		for t := start; add(t); t = t.AddDate(0, 0, r.Interval) {
+		}
+	case FreqMonthly:
+		for i := 0; ; i += r.Interval {
+			t := start.AddDate(0, i, 0)
+			if t.Day() != start.Day() {
+				// the month is too short, AddDate rolled over into the next one
+				continue
+			}
+			if !add(t) {
+				break
+			}
+		}
+	case FreqWeekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+		// weeks start on Monday as in RFC 5545
+		weekStart := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		for week := weekStart; ; week = week.AddDate(0, 0, 7*r.Interval) {
+			for offset := 0; offset < 7; offset++ {
+				t := week.AddDate(0, 0, offset)
+				if t.Before(start) || !containsWeekday(days, t.Weekday()) {
+					continue
+				}
+				if !add(t) {
+					return occurrences
+				}
+			}
+		}
+	}
+	return occurrences
+}
+
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+package utils
+
+import "github.com/gofiber/fiber/v2"
+
+// LocalsTenant is where the tenant middleware stores the tenant of the request
+const LocalsTenant = "tenant"
+
+// TenantFromCtx returns the tenant the request was resolved to, or "" when
+// the route does not resolve tenants and the default tenant applies
+func TenantFromCtx(c *fiber.Ctx) string {
+	if tenant, ok := c.Locals(LocalsTenant).(string); ok {
+		return tenant
+	}
+	return ""
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// HeaderAcceptTimezone asks for response times in an IANA time zone
+	HeaderAcceptTimezone = "Accept-Timezone"
+	// LocalsTimezone is where the Timezone middleware stores the zone
+	LocalsTimezone = "timezone"
+)
+
+// LocationFromCtx returns the time zone the caller asked for, or nil when
+// the request did not ask for one
+func LocationFromCtx(c *fiber.Ctx) *time.Location {
+	if location, ok := c.Locals(LocalsTimezone).(*time.Location); ok {
+		return location
+	}
+	return nil
+}
@@ -2,14 +2,30 @@ package utils
 
 import (
 	"errors"
-	"fmt"
-	"os"
+
+	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// roles carried in the JWT claims
+const (
+	RoleCustomer = "customer"
+	RoleStaff    = "staff"
+	RoleAdmin    = "admin"
+)
+
+// SystemClaims are the claims of callers inside the system, such as the
+// background tasks and bookingctl, they act as an admin without a user id.
+// Requests never carry them, anonymous requests have nil claims.
+var SystemClaims = &Claims{Role: RoleAdmin}
+
 type (
 	Claims struct {
-		Id int `json:"id"`	
+		Id   int    `json:"id"`
+		Role string `json:"role"`
+		// Tenant is the business unit the caller belongs to, tokens without
+		// one belong to the default tenant
+		Tenant string `json:"tenant,omitempty"`
 	}
 
 	AuthMapClaims struct {
@@ -18,14 +34,56 @@ type (
 	}
 )
 
-func ParseToken(secertKey, tokenString string) (*AuthMapClaims, error) {
+// GetRole returns the role of the caller, tokens without a role are customers
+func (c *Claims) GetRole() string {
+	if c.Role == "" {
+		return RoleCustomer
+	}
+	return c.Role
+}
+
+// HasRole reports whether the caller has one of the given roles
+func (c *Claims) HasRole(roles ...string) bool {
+	role := c.GetRole()
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
 
-	token, err := jwt.ParseWithClaims(tokenString, &AuthMapClaims{}, func(token *jwt.Token) (interface{}, error) {
+// IsStaff reports whether the caller may act on bookings of other users
+func (c *Claims) IsStaff() bool {
+	return c.HasRole(RoleStaff, RoleAdmin)
+}
+
+// ClaimsFromCtx returns the claims stored by the JwtAuth middleware, or nil
+// when the route is not authenticated
+func ClaimsFromCtx(c *fiber.Ctx) *Claims {
+	if claims, ok := c.Locals("claims").(*AuthMapClaims); ok && claims.Claims != nil {
+		return claims.Claims
+	}
+	return nil
+}
+
+func ParseToken(secertKey, tokenString string) (*AuthMapClaims, error) {
+	return parseToken(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("error: unexpected signing method")
 		}
 		return []byte(secertKey), nil
 	})
+}
+
+// ParseTokenWithKeySet verifies an RS256/ES256 token against the key set
+func ParseTokenWithKeySet(keys *KeySet, tokenString string) (*AuthMapClaims, error) {
+	return parseToken(tokenString, keys.Keyfunc)
+}
+
+func parseToken(tokenString string, keyFunc jwt.Keyfunc) (*AuthMapClaims, error) {
+
+	token, err := jwt.ParseWithClaims(tokenString, &AuthMapClaims{}, keyFunc)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenMalformed) {
@@ -45,36 +103,3 @@ func ParseToken(secertKey, tokenString string) (*AuthMapClaims, error) {
 		return nil, errors.New("error: claims type is invalid")
 	}
 }
-
-func ParseApiKey(apisecret, tokenString string) (*AuthMapClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &AuthMapClaims{}, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("signing method is invalid")
-		}
-		return []byte(apisecret), nil
-	})
-	if err != nil {
-		if errors.Is(err, jwt.ErrTokenMalformed) {
-			return nil, fmt.Errorf("token format is invalid")
-		} else if errors.Is(err, jwt.ErrTokenExpired) {
-			return nil, fmt.Errorf("token had expired")
-		} else {
-			return nil, fmt.Errorf("parse token failed: %v", err)
-		}
-	}
-
-	if claims, ok := token.Claims.(*AuthMapClaims); ok {
-		return claims, nil
-	} else {
-		return nil, fmt.Errorf("claims type is invalid")
-	}
-}
-
-func ValidApikey(accessToken string) error {
-
-	if accessToken != os.Getenv("API_KEY") {
-		return errors.New("error: access token is invalid")
-	}
-
-	return nil
-}